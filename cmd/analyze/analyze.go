@@ -18,11 +18,15 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/ai/interactive"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/analysis"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/apimachinery/pkg/fields"
 )
 
 var (
@@ -34,6 +38,7 @@ var (
 	nocache         bool
 	namespace       string
 	labelSelector   string
+	fieldSelector   string
 	anonymize       bool
 	maxConcurrency  int
 	withDoc         bool
@@ -41,8 +46,31 @@ var (
 	customAnalysis  bool
 	customHeaders   []string
 	withStats       bool
+	minAge          time.Duration
+	stream          bool
+	validate        bool
+	includeNs       []string
+	excludeNs       []string
+	exclude         []string
+	minSeverity     string
+	analysisConfig  string
+	analyzerTimeout time.Duration
+	groupByParent   bool
+	suggestCommands bool
+	includeLogs     bool
+	reportFile      string
+	sinceLast       bool
+	exitCode        bool
+	failOnSeverity  string
+	fromDir         string
+	scoreExplain    bool
 )
 
+// findingsExitCode is the exit code of the analyze command when --exit-code
+// or --fail-on-severity are given and problems are found, telling them from
+// errors.
+const findingsExitCode = 2
+
 // AnalyzeCmd represents the problems command
 var AnalyzeCmd = &cobra.Command{
 	Use:     "analyze",
@@ -51,6 +79,14 @@ var AnalyzeCmd = &cobra.Command{
 	Long: `This command will find problems within your Kubernetes cluster and
 	provide you with a list of issues that need to be resolved`,
 	Run: func(cmd *cobra.Command, args []string) {
+		// The language of the configuration applies unless --language is given.
+		if !cmd.Flags().Changed("language") && viper.IsSet("language") {
+			language = viper.GetString("language")
+		}
+
+		// Manifests are analyzed instead of the cluster when a directory is given.
+		viper.Set("from_dir", fromDir)
+
 		// Create analysis configuration first.
 		config, err := analysis.NewAnalysis(
 			backend,
@@ -72,13 +108,120 @@ var AnalyzeCmd = &cobra.Command{
 			os.Exit(1)
 		}
 		defer config.Close()
+		// Flags given along the analysis configuration file win over it.
+		if analysisConfig != "" {
+			fileConfig, err := analysis.LoadConfig(analysisConfig)
+			if err == nil {
+				err = config.ApplyConfig(fileConfig)
+			}
+			if err != nil {
+				color.Red("Error: %v", err)
+				os.Exit(1)
+			}
+			if cmd.Flags().Changed("language") {
+				config.Language = language
+			}
+		}
+		config.MinAge = minAge
+		if cmd.Flags().Changed("analyzer-timeout") {
+			config.AnalyzerTimeout = analyzerTimeout
+		}
+		if _, err := fields.ParseSelector(fieldSelector); err != nil {
+			color.Red("Error: invalid field selector: %v", err)
+			os.Exit(1)
+		}
+		config.FieldSelector = fieldSelector
+		switch common.Severity(failOnSeverity) {
+		case "", common.SeverityLow, common.SeverityMedium, common.SeverityHigh:
+		default:
+			color.Red("Error: invalid --fail-on-severity %s, must be one of low, medium, high", failOnSeverity)
+			os.Exit(1)
+		}
+		if (exitCode || failOnSeverity != "") && interactiveMode {
+			color.Red("Error: --exit-code and --fail-on-severity can't be used with --interactive")
+			os.Exit(1)
+		}
+		switch severity := common.Severity(minSeverity); severity {
+		case "":
+		case common.SeverityLow, common.SeverityMedium, common.SeverityHigh:
+			config.MinSeverity = severity
+		default:
+			color.Red("Error: invalid --min-severity %s, must be one of low, medium, high", minSeverity)
+			os.Exit(1)
+		}
+		if len(includeNs) > 0 {
+			config.IncludeNamespaces = includeNs
+		}
+		if len(excludeNs) > 0 {
+			config.ExcludeNamespaces = excludeNs
+		}
+		if len(exclude) > 0 {
+			config.ExcludeFilters = exclude
+		}
+		if validate {
+			config.ExplanationValidators = append(config.ExplanationValidators, &analysis.NamespaceValidator{Client: config.Client.GetClient()})
+		}
+
+		if scoreExplain && !explain {
+			color.Red("Error: --score-explanations only works with --explain")
+			os.Exit(1)
+		}
+		config.ScoreExplanations = scoreExplain
+		config.GroupByParent = groupByParent
+		config.WithCommands = suggestCommands
+		config.IncludeLogs = includeLogs
+
+		// NDJSON results are streamed unless they have to wait for explanations or grouping.
+		streaming := stream || (output == "ndjson" && !config.Explain && !groupByParent && !sinceLast)
+		if stream && groupByParent {
+			color.Red("Error: --stream can't be used with --group-by-parent, as grouping needs the whole analysis")
+			os.Exit(1)
+		}
+		if stream && sinceLast {
+			color.Red("Error: --stream can't be used with --since-last, as the comparison needs the whole analysis")
+			os.Exit(1)
+		}
+		if stream && config.Explain {
+			color.Red("Error: --stream can't be used with --explain, as explanations need the whole analysis")
+			os.Exit(1)
+		}
+		if streaming {
+			if err := config.StreamOutput(output, os.Stdout); err != nil {
+				color.Red("Error: %v", err)
+				os.Exit(1)
+			}
+		}
 
 		if customAnalysis {
 			config.RunCustomAnalysis()
 		}
 		config.RunAnalysis()
 
-		if explain {
+		if streaming {
+			writeReport(config)
+			fmt.Print(string(config.StreamSummary(output)))
+			// Keep stdout to one result per line for NDJSON.
+			if output == "ndjson" {
+				for _, aerror := range config.Errors {
+					fmt.Fprintln(os.Stderr, color.YellowString("Warning: %s", aerror))
+				}
+			}
+			if withStats {
+				fmt.Fprintln(os.Stderr, string(config.PrintStats()))
+			}
+			exitOnFindings(config)
+			return
+		}
+
+		// Only the new findings are explained.
+		if sinceLast {
+			if err := config.SinceLast(analysis.NewHistoryStore(config)); err != nil {
+				color.Red("Error: %v", err)
+				os.Exit(1)
+			}
+		}
+
+		if config.Explain {
 			if err := config.GetAIResults(output, anonymize); err != nil {
 				color.Red("Error: %v", err)
 				os.Exit(1)
@@ -90,6 +233,7 @@ var AnalyzeCmd = &cobra.Command{
 			color.Red("Error: %v", err)
 			os.Exit(1)
 		}
+		writeReport(config)
 
 		if withStats {
 			statsData := config.PrintStats()
@@ -97,8 +241,9 @@ var AnalyzeCmd = &cobra.Command{
 		}
 
 		fmt.Println(string(output_data))
+		exitOnFindings(config)
 
-		if interactiveMode && explain {
+		if interactiveMode && config.Explain {
 			if output == "json" {
 				color.Yellow("Caution: interactive mode using --json enabled may use additional tokens.")
 			}
@@ -125,6 +270,34 @@ var AnalyzeCmd = &cobra.Command{
 	},
 }
 
+// exitOnFindings exits with findingsExitCode when --exit-code is given and
+// problems were found, or when one of them is at least as severe as
+// --fail-on-severity.
+func exitOnFindings(config *analysis.Analysis) {
+	count, maxSeverity := config.Findings()
+	if failOnSeverity != "" {
+		if maxSeverity.Rank() >= common.Severity(failOnSeverity).Rank() {
+			os.Exit(findingsExitCode)
+		}
+		return
+	}
+	if exitCode && count > 0 {
+		os.Exit(findingsExitCode)
+	}
+}
+
+// writeReport writes the Markdown report of the analysis when --report-file
+// is given.
+func writeReport(config *analysis.Analysis) {
+	if reportFile == "" {
+		return
+	}
+	if err := config.WriteReport(reportFile); err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+}
+
 func init() {
 	// namespace flag
 	AnalyzeCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Namespace to analyze")
@@ -133,17 +306,18 @@ func init() {
 	// anonymize flag
 	AnalyzeCmd.Flags().BoolVarP(&anonymize, "anonymize", "a", false, "Anonymize data before sending it to the AI backend. This flag masks sensitive data, such as Kubernetes object names and labels, by replacing it with a key. However, please note that this flag does not currently apply to events.")
 	// array of strings flag
-	AnalyzeCmd.Flags().StringSliceVarP(&filters, "filter", "f", []string{}, "Filter for these analyzers (e.g. Pod, PersistentVolumeClaim, Service, ReplicaSet)")
+	AnalyzeCmd.Flags().StringSliceVarP(&filters, "filter", "f", []string{}, "Filter for these analyzers (e.g. Pod, PersistentVolumeClaim, Service, ReplicaSet) or groups of analyzers (e.g. workloads, networking)")
+	AnalyzeCmd.Flags().StringSliceVarP(&exclude, "exclude", "", []string{}, "Don't run these analyzers, groups of analyzers or integrations' analyzers (e.g. PolicyReport, networking, kyverno), even when filtered for or active. Overrides exclude_filters of the configuration")
 	// explain flag
 	AnalyzeCmd.Flags().BoolVarP(&explain, "explain", "e", false, "Explain the problem to me")
 	// add flag for backend
 	AnalyzeCmd.Flags().StringVarP(&backend, "backend", "b", "", "Backend AI provider")
 	// output as json
-	AnalyzeCmd.Flags().StringVarP(&output, "output", "o", "text", "Output format (text, json)")
+	AnalyzeCmd.Flags().StringVarP(&output, "output", "o", "text", "Output format (text, json, junit, ndjson, sarif)")
 	// add language options for output
 	AnalyzeCmd.Flags().StringVarP(&language, "language", "l", "english", "Languages to use for AI (e.g. 'English', 'Spanish', 'French', 'German', 'Italian', 'Portuguese', 'Dutch', 'Russian', 'Chinese', 'Japanese', 'Korean')")
 	// add max concurrency
-	AnalyzeCmd.Flags().IntVarP(&maxConcurrency, "max-concurrency", "m", 10, "Maximum number of concurrent requests to the Kubernetes API server")
+	AnalyzeCmd.Flags().IntVarP(&maxConcurrency, "max-concurrency", "m", 0, "Maximum number of analyzers run concurrently against the Kubernetes API server (defaults to GOMAXPROCS)")
 	// kubernetes doc flag
 	AnalyzeCmd.Flags().BoolVarP(&withDoc, "with-doc", "d", false, "Give me the official documentation of the involved field")
 	// interactive mode flag
@@ -154,6 +328,35 @@ func init() {
 	AnalyzeCmd.Flags().StringSliceVarP(&customHeaders, "custom-headers", "r", []string{}, "Custom Headers, <key>:<value> (e.g CustomHeaderKey:CustomHeaderValue AnotherHeader:AnotherValue)")
 	// label selector flag
 	AnalyzeCmd.Flags().StringVarP(&labelSelector, "selector", "L", "", "Label selector (label query) to filter on, supports '=', '==', and '!='. (e.g. -L key1=value1,key2=value2). Matching objects must satisfy all of the specified label constraints.")
+	// field selector flag
+	AnalyzeCmd.Flags().StringVarP(&fieldSelector, "field-selector", "", "", "Field selector to filter on, supports '=', '==', and '!=' (e.g. --field-selector spec.nodeName=node-1,status.phase=Pending). It only applies to the kinds supporting the fields; others are analyzed without it.")
+	// namespace filters
+	AnalyzeCmd.Flags().StringSliceVarP(&includeNs, "include-namespaces", "", []string{}, "Only report problems in namespaces matching these glob patterns (e.g. team-*). Overrides include_namespaces of the configuration")
+	AnalyzeCmd.Flags().StringSliceVarP(&excludeNs, "exclude-namespaces", "", []string{}, "Don't report problems in namespaces matching these glob patterns (e.g. kube-system). Overrides exclude_namespaces of the configuration")
+	// minimum severity
+	AnalyzeCmd.Flags().StringVarP(&minSeverity, "min-severity", "", "", "Only report failures of at least this severity (low, medium, high). Failures of analyzers that don't rank them are always reported")
 	// print stats
 	AnalyzeCmd.Flags().BoolVarP(&withStats, "with-stat", "s", false, "Print analysis stats. This option disables errors display.")
+	// stream results flag
+	AnalyzeCmd.Flags().BoolVarP(&stream, "stream", "", false, "Print each result as soon as its analyzer finishes instead of at the end (text and ndjson output)")
+	// explanation validation flag
+	AnalyzeCmd.Flags().BoolVarP(&validate, "validate-explanations", "", false, "Warn about namespaces mentioned in explanations that don't exist in the cluster. Works only with --explain flag")
+	// analysis configuration file
+	AnalyzeCmd.Flags().StringVarP(&analysisConfig, "analysis-config", "", "", "YAML or JSON file describing the analyzers to run and their options (e.g. analysis.yaml). Flags given along it take precedence")
+	// per analyzer timeout
+	AnalyzeCmd.Flags().DurationVarP(&analyzerTimeout, "analyzer-timeout", "", 30*time.Second, "Give up on an analyzer after this duration and report it as timed out, so one slow analyzer can't hang the analysis. Overrides analyzer_timeout of the configuration")
+	// include container logs
+	AnalyzeCmd.Flags().BoolVarP(&includeLogs, "include-logs", "", false, "Send the last lines logged by the previous instance of a failing container along the failures of a Pod when explaining it (log_lines configuration key, 50 by default)")
+	AnalyzeCmd.Flags().StringVarP(&reportFile, "report-file", "", "", "Also write the results, with their explanations, to this file as a Markdown report")
+	AnalyzeCmd.Flags().BoolVarP(&sinceLast, "since-last", "", false, "Only print the problems found since the last analysis of the same cluster, namespace, filters and selectors, and those resolved since")
+	AnalyzeCmd.Flags().BoolVarP(&exitCode, "exit-code", "", false, fmt.Sprintf("Exit with code %d when problems are found, e.g. to fail CI pipelines", findingsExitCode))
+	AnalyzeCmd.Flags().StringVarP(&failOnSeverity, "fail-on-severity", "", "", fmt.Sprintf("Exit with code %d when a problem at least this severe is found (low, medium, high)", findingsExitCode))
+	AnalyzeCmd.Flags().BoolVarP(&suggestCommands, "suggest-commands", "", false, "Print kubectl commands to investigate each result further (text output)")
+	// group results by parent
+	AnalyzeCmd.Flags().BoolVarP(&groupByParent, "group-by-parent", "", false, "Report the failures of objects once under their top-level owner (e.g. the pods of a Deployment under the Deployment)")
+	// score explanations
+	AnalyzeCmd.Flags().BoolVarP(&scoreExplain, "score-explanations", "", false, "Estimate the confidence of each explanation and whether it gives a remediation, flagging the low ones. Works only with --explain")
+	AnalyzeCmd.Flags().StringVarP(&fromDir, "from-dir", "", "", "Analyze the YAML or JSON manifests of this directory instead of the cluster, e.g. before deploying them. Manifests have no status, so checks relying on it report less or report objects as unavailable")
+	// minimum object age
+	AnalyzeCmd.Flags().DurationVarP(&minAge, "min-age", "", 0, "Skip objects created within this duration, as they are often still starting up (e.g. 30s, 5m)")
 }
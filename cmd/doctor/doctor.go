@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/analyzer"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/doctor"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	backend   string
+	namespace string
+	filters   []string
+	timeout   time.Duration
+)
+
+// DoctorCmd represents the doctor command
+var DoctorCmd = &cobra.Command{
+	Use:     "doctor",
+	Aliases: []string{"health"},
+	Short:   "Check that k8sgpt can reach the cluster and the AI provider",
+	Long: `This command runs the pre-flight checks of an analysis: it connects to the
+	cluster, checks the RBAC permissions of the enabled analyzers and sends a
+	trivial prompt to the configured AI provider. Each check is reported as
+	PASS or FAIL, and the command exits with status 1 if any check failed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		var checks []doctor.Check
+
+		client, err := kubernetes.NewClient(viper.GetString("kubecontext"), viper.GetString("kubeconfig"))
+		if err != nil {
+			checks = append(checks, doctor.Check{Name: "cluster connectivity", Err: err})
+		} else {
+			cluster := doctor.CheckCluster(client.GetClient())
+			checks = append(checks, cluster)
+			if cluster.Err == nil {
+				checks = append(checks, doctor.CheckPermissions(ctx, client.GetClient(), namespace, enabledAnalyzers())...)
+			}
+		}
+
+		provider, err := configuredProvider()
+		if err != nil {
+			checks = append(checks, doctor.Check{Name: "AI provider", Err: err})
+		} else {
+			checks = append(checks, doctor.CheckAIProvider(ctx, provider))
+		}
+
+		failed := false
+		for _, check := range checks {
+			if check.Err != nil {
+				failed = true
+				fmt.Printf("%s %s: %s\n", color.RedString("FAIL"), check.Name, check.Err)
+				continue
+			}
+			fmt.Printf("%s %s\n", color.GreenString("PASS"), check.Name)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// enabledAnalyzers returns the analyzers an analysis would run with the same
+// flags: the given filters, else the active filters, else the core analyzers.
+func enabledAnalyzers() []string {
+	if len(filters) > 0 {
+		return filters
+	}
+	if activeFilters := viper.GetStringSlice("active_filters"); len(activeFilters) > 0 {
+		return activeFilters
+	}
+	coreFilters, _, _ := analyzer.ListFilters()
+	return coreFilters
+}
+
+// configuredProvider returns the provider an analysis would use: the backend
+// flag, else the default provider, else openai.
+func configuredProvider() (ai.AIProvider, error) {
+	var configAI ai.AIConfiguration
+	if err := viper.UnmarshalKey("ai", &configAI); err != nil {
+		return ai.AIProvider{}, err
+	}
+	name := backend
+	if name == "" {
+		name = configAI.DefaultProvider
+	}
+	if name == "" {
+		name = "openai"
+	}
+	for _, provider := range configAI.Providers {
+		if provider.Name == name {
+			return provider, nil
+		}
+	}
+	return ai.AIProvider{}, fmt.Errorf("AI provider %s not specified in configuration. Please run k8sgpt auth", name)
+}
+
+func init() {
+	DoctorCmd.Flags().StringVarP(&backend, "backend", "b", "", "Backend AI provider to check")
+	DoctorCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Namespace to check RBAC permissions in")
+	DoctorCmd.Flags().StringSliceVarP(&filters, "filter", "f", []string{}, "Analyzers to check RBAC permissions for (defaults to the analyzers analyze would run)")
+	DoctorCmd.Flags().DurationVar(&timeout, "timeout", time.Minute, "Timeout for the checks")
+}
@@ -34,6 +34,7 @@ var addCmd = &cobra.Command{
 		coreFilters, additionalFilters, integrationFilters := analyzer.ListFilters()
 
 		availableFilters := append(append(coreFilters, additionalFilters...), integrationFilters...)
+		groups := analyzer.FilterGroups()
 		// Verify filter exist
 		invalidFilters := []string{}
 		for _, f := range inputFilters {
@@ -41,7 +42,8 @@ var addCmd = &cobra.Command{
 				color.Red("Filter cannot be empty. Please use correct syntax.")
 				os.Exit(1)
 			}
-			foundFilter := false
+			// Groups are expanded when analyzing, so they follow changes to the configuration.
+			_, foundFilter := groups[strings.ToLower(f)]
 			for _, filter := range availableFilters {
 				if filter == f {
 					foundFilter = true
@@ -61,7 +63,7 @@ var addCmd = &cobra.Command{
 		}
 
 		if len(invalidFilters) != 0 {
-			color.Red("Filter %s does not exist. Please use k8sgpt filters list", strings.Join(invalidFilters, ", "))
+			color.Red("Filter %s does not exist. Please use k8sgpt filters list. Available filter groups: %s", strings.Join(invalidFilters, ", "), strings.Join(analyzer.FilterGroupNames(), ", "))
 			os.Exit(1)
 		}
 
@@ -16,6 +16,7 @@ package filters
 import (
 	"fmt"
 	"slices"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/analyzer"
@@ -67,5 +68,12 @@ var listCmd = &cobra.Command{
 				}
 			}
 		}
+
+		// display filter groups
+		groups := analyzer.FilterGroups()
+		fmt.Print(color.YellowString("Groups: \n"))
+		for _, name := range analyzer.FilterGroupNames() {
+			fmt.Printf("> %s: %s\n", color.CyanString(name), strings.Join(groups[name], ", "))
+		}
 	},
 }
@@ -14,13 +14,17 @@ limitations under the License.
 package serve
 
 import (
+	"context"
 	"os"
 	"strconv"
+	"strings"
 
 	k8sgptserver "github.com/k8sgpt-ai/k8sgpt/pkg/server"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/server/analyze"
 
 	"github.com/fatih/color"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -38,6 +42,10 @@ var (
 	metricsPort string
 	backend     string
 	enableHttp  bool
+	// filtersConfigMap is the namespace/name of the ConfigMap holding the analyzer filters
+	filtersConfigMap string
+	// maxConcurrentAnalyses bounds the analyze requests served at once
+	maxConcurrentAnalyses int
 )
 
 var ServeCmd = &cobra.Command{
@@ -190,7 +198,32 @@ var ServeCmd = &cobra.Command{
 			EnableHttp:  enableHttp,
 			Token:       aiProvider.Password,
 			Logger:      logger,
+
+			MaxConcurrentAnalyses: maxConcurrentAnalyses,
+		}
+
+		if filtersConfigMap != "" {
+			namespace, name, found := strings.Cut(filtersConfigMap, "/")
+			if !found || namespace == "" || name == "" {
+				color.Red("Error: --filters-configmap must be in the form namespace/name")
+				os.Exit(1)
+			}
+			client, err := kubernetes.NewClient(viper.GetString("kubecontext"), viper.GetString("kubeconfig"))
+			if err != nil {
+				color.Red("Error initialising kubernetes client: %v", err)
+				os.Exit(1)
+			}
+			server.FilterConfig = &analyze.FilterConfigMap{
+				Namespace: namespace,
+				Name:      name,
+				Logger:    logger,
+			}
+			if err := server.FilterConfig.Watch(context.Background(), client.GetClient()); err != nil {
+				color.Red("Error: %v", err)
+				os.Exit(1)
+			}
 		}
+
 		go func() {
 			if err := server.ServeMetrics(); err != nil {
 				color.Red("Error: %v", err)
@@ -216,4 +249,6 @@ func init() {
 	ServeCmd.Flags().StringVarP(&metricsPort, "metrics-port", "", "8081", "Port to run the metrics-server on")
 	ServeCmd.Flags().StringVarP(&backend, "backend", "b", "openai", "Backend AI provider")
 	ServeCmd.Flags().BoolVarP(&enableHttp, "http", "", false, "Enable REST/http using gppc-gateway")
+	ServeCmd.Flags().IntVarP(&maxConcurrentAnalyses, "max-concurrent-analyses", "", 1, "Maximum number of analyze requests served at once; others wait for their turn (0 for no limit)")
+	ServeCmd.Flags().StringVarP(&filtersConfigMap, "filters-configmap", "", "", "Read the analyzer filters from this ConfigMap (namespace/name) and reload them when it changes")
 }
@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command plugin-analyzer is a stub of an out-of-tree analyzer, run by
+// k8sgpt as a subprocess, see analyzer.PluginAnalyzer. It reads what to
+// analyze on its standard input and writes its results on its standard
+// output.
+//
+// Build it and add it to the k8sgpt configuration file:
+//
+//	plugin_analyzers:
+//	  - name: Widget
+//	    command: /usr/local/bin/plugin-analyzer
+//
+// then run it with k8sgpt analyze --filter Widget.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/analyzer"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+)
+
+func main() {
+	var request analyzer.PluginRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&request); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid request: %v\n", err)
+		os.Exit(1)
+	}
+
+	namespace := request.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	// A real plugin would list its custom resources in the namespace and
+	// with the selectors of the request, and report those in trouble.
+	results := []common.Result{
+		{
+			Kind: "Widget",
+			Name: namespace + "/example",
+			Error: []common.Failure{
+				{
+					Text:     "Widget example is not ready: its backend is unreachable",
+					Severity: common.SeverityMedium,
+				},
+			},
+		},
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(results); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing results: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -16,6 +16,7 @@ package ai
 import (
 	"context"
 	"net/http"
+	"reflect"
 )
 
 var (
@@ -90,7 +91,9 @@ type IAIConfig interface {
 func NewClient(provider string) IAI {
 	for _, c := range clients {
 		if provider == c.GetName() {
-			return c
+			// A new instance, so clients configured differently (e.g. one per
+			// model) don't overwrite each other's configuration.
+			return reflect.New(reflect.TypeOf(c).Elem()).Interface().(IAI)
 		}
 	}
 	// default client
@@ -120,6 +123,8 @@ type AIProvider struct {
 	MaxTokens      int           `mapstructure:"maxtokens" yaml:"maxtokens,omitempty"`
 	OrganizationId string        `mapstructure:"organizationid" yaml:"organizationid,omitempty"`
 	CustomHeaders  []http.Header `mapstructure:"customHeaders"`
+	// Models maps result kinds (e.g. Pod, Log) to the model explaining them, instead of Model.
+	Models map[string]string `mapstructure:"models" yaml:"models,omitempty"`
 }
 
 func (p *AIProvider) GetBaseURL() string {
@@ -88,7 +88,8 @@ func (c *OllamaClient) GetCompletion(ctx context.Context, prompt string) (string
 	}
 	completion := ""
 	respFunc := func(resp ollama.GenerateResponse) error {
-		completion = resp.Response
+		// Chunks are concatenated in case the response is streamed.
+		completion += resp.Response
 		return nil
 	}
 	err := c.client.Generate(ctx, req, respFunc)
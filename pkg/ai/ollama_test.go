@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOllamaGetCompletion(t *testing.T) {
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/generate", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		// A streamed response is concatenated.
+		fmt.Fprintln(w, `{"model":"llama3","response":"The image ","done":false}`)
+		fmt.Fprintln(w, `{"model":"llama3","response":"tag is wrong.","done":true}`)
+	}))
+	defer server.Close()
+
+	client := &OllamaClient{}
+	require.NoError(t, client.Configure(&AIProvider{BaseURL: server.URL}))
+
+	completion, err := client.GetCompletion(context.Background(), "Explain ErrImagePull")
+	require.NoError(t, err)
+	require.Equal(t, "The image tag is wrong.", completion)
+	require.Equal(t, "llama3", request["model"])
+	require.Equal(t, "Explain ErrImagePull", request["prompt"])
+	require.Equal(t, ollamaClientName, client.GetName())
+}
+
+func TestOllamaGetCompletionCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := &OllamaClient{}
+	require.NoError(t, client.Configure(&AIProvider{BaseURL: server.URL, Model: "mistral"}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.GetCompletion(ctx, "Explain ErrImagePull")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
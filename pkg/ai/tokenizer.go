@@ -0,0 +1,73 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Tokenizer counts the number of tokens a text consumes for a given model.
+// The implementations in this package are estimates: they do not ship the
+// vocabularies of the models, so counts can differ slightly from the provider's.
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// gptPretokenizePattern splits text the same way the cl100k/o200k encodings
+// used by OpenAI GPT models do before applying byte-pair merges.
+var gptPretokenizePattern = regexp.MustCompile(`(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+`)
+
+// gptEstimator estimates GPT token counts from the pre-tokenized pieces. Most
+// short pieces (common words with their leading space) are a single token in
+// the GPT vocabularies; longer ones are split into roughly 4-character merges.
+type gptEstimator struct{}
+
+const gptSingleTokenRunes = 12
+
+func (gptEstimator) CountTokens(text string) int {
+	var count int
+	for _, piece := range gptPretokenizePattern.FindAllString(text, -1) {
+		n := utf8.RuneCountInString(piece)
+		if n <= gptSingleTokenRunes {
+			count++
+			continue
+		}
+		count += (n + 3) / 4
+	}
+	return count
+}
+
+// charEstimator is the fallback used when no tokenizer is known for a model.
+type charEstimator struct {
+	charsPerToken int
+}
+
+func (t charEstimator) CountTokens(text string) int {
+	return (utf8.RuneCountInString(text) + t.charsPerToken - 1) / t.charsPerToken
+}
+
+var gptModelPrefixes = []string{"gpt-", "chatgpt-", "o1", "o3", "o4"}
+
+// NewTokenizer returns the tokenizer for the given model.
+func NewTokenizer(model string) Tokenizer {
+	model = strings.ToLower(model)
+	for _, prefix := range gptModelPrefixes {
+		if strings.HasPrefix(model, prefix) {
+			return gptEstimator{}
+		}
+	}
+	return charEstimator{charsPerToken: 4}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenizer(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    string
+		text     string
+		expected int
+	}{
+		{
+			name:     "empty text",
+			model:    "gpt-4o",
+			text:     "",
+			expected: 0,
+		},
+		{
+			// cl100k encodes this as "Back", "-off", " restarting", " failed", " container".
+			name:     "gpt model counts words",
+			model:    "gpt-4o-mini",
+			text:     "Back-off restarting failed container",
+			expected: 5,
+		},
+		{
+			name:     "gpt model splits long pieces",
+			model:    "GPT-3.5-turbo",
+			text:     "supercalifragilisticexpialidocious",
+			expected: 9,
+		},
+		{
+			name:     "unknown model falls back to characters",
+			model:    "llama3",
+			text:     "Back-off restarting failed container",
+			expected: 9,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NewTokenizer(tt.model).CountTokens(tt.text))
+		})
+	}
+}
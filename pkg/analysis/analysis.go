@@ -18,8 +18,13 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"path"
+	"regexp"
+	"runtime"
+	"sort"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	"github.com/fatih/color"
@@ -33,6 +38,9 @@ import (
 	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
 	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/viper"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 type Analysis struct {
@@ -52,6 +60,111 @@ type Analysis struct {
 	WithDoc            bool
 	WithStats          bool
 	Stats              []common.AnalysisStats
+	// AnalyzerErrors holds the errors of the analyzers that failed, also
+	// recorded as text in Errors.
+	AnalyzerErrors []*AnalyzerError
+	// Tokenizer estimates the tokens of the prompts sent to the AI provider.
+	Tokenizer ai.Tokenizer
+	// PromptTokens is the estimated number of tokens sent to the AI provider.
+	PromptTokens int
+	// MinAge skips objects created more recently than this.
+	MinAge time.Duration
+	// FieldSelector narrows the objects of the kinds supporting its fields.
+	FieldSelector string
+	// ExcludeFilters are analyzers, filter groups or integrations not to run,
+	// whether they come from Filters, active filters or the core analyzers,
+	// read from the exclude_filters configuration key.
+	ExcludeFilters []string
+	// ExcludeContainers are glob patterns of container names the PodAnalyzer
+	// ignores, read from the exclude_containers configuration key.
+	ExcludeContainers []string
+	// PodEnvSourceLookups is read from the pod_env_source_lookups
+	// configuration key, see common.Analyzer.
+	PodEnvSourceLookups bool
+	// PodErrorReasons and PodEventErrorReasons are read from the
+	// pod_error_reasons and pod_event_error_reasons configuration keys, see
+	// common.Analyzer.
+	PodErrorReasons      []string
+	PodEventErrorReasons []string
+	// CustomResources are read from the custom_resources configuration key,
+	// see common.Analyzer.
+	CustomResources []common.CustomResource
+	// ResourceQuotaThreshold is read from the resource_quota_threshold
+	// configuration key, see common.Analyzer.
+	ResourceQuotaThreshold float64
+	// EventLookback is read from the event_lookback configuration key, see
+	// common.Analyzer.
+	EventLookback time.Duration
+	// VolumeAttachmentTimeout is read from the volume_attachment_timeout
+	// configuration key, see common.Analyzer.
+	VolumeAttachmentTimeout time.Duration
+	// IncludeNamespaces and ExcludeNamespaces are glob patterns of the
+	// namespaces whose results are kept or dropped, read from the
+	// include_namespaces and exclude_namespaces configuration keys.
+	// Results of cluster-scoped objects are always kept.
+	IncludeNamespaces []string
+	ExcludeNamespaces []string
+	// MinSeverity drops the failures ranked below it, and the results left
+	// without failures. Failures without a severity are kept.
+	MinSeverity common.Severity
+	// ResultFilter, when set, keeps only the results for which it returns
+	// true. It runs before results are streamed, explained or printed, and
+	// may be called concurrently by the analyzers.
+	ResultFilter func(common.Result) bool
+	// OnResult, when set, is called with each result as soon as its analyzer
+	// finishes. Calls are serialized.
+	OnResult func(common.Result)
+	// kindAIClients holds the clients for the models set per result kind.
+	kindAIClients map[string]ai.IAI
+	// aiModel and kindAIModels are the models of AIClient and kindAIClients,
+	// which explanations are cached by.
+	aiModel      string
+	kindAIModels map[string]string
+	// analyzersWithoutFindings lists the analyzers that ran cleanly and found nothing.
+	analyzersWithoutFindings []string
+	// suppressions drops results for objects annotated to ignore them.
+	suppressions *suppressions
+	// masks keeps anonymized values consistent across the prompts of a run.
+	masks *maskTable
+	// explainMutex guards the Errors added while explaining, which
+	// GetExplanation may do concurrently.
+	explainMutex sync.Mutex
+	// ExplanationValidators check each AI explanation and add their
+	// warnings to the result.
+	ExplanationValidators []ExplanationValidator
+	// ScoreExplanations sets the confidence of each AI explanation, see
+	// ScoreExplanation, and flags the low ones in the text output.
+	ScoreExplanations bool
+	// AIRetries is how many times a transient AI provider error is retried,
+	// waiting AIRetryDelay before the first retry and twice as long before
+	// each next one.
+	AIRetries    int
+	AIRetryDelay time.Duration
+	// AnalyzerTimeout bounds each analyzer run, 30s when unset. It is read
+	// from the analyzer_timeout configuration key.
+	AnalyzerTimeout time.Duration
+	// GroupByParent collapses the results of objects into the result of
+	// their ParentObject once the analysis is done.
+	GroupByParent bool
+	// WithCommands prints the kubectl commands of SuggestCommands under each
+	// result of the text output.
+	WithCommands bool
+	// MaxPromptTokens is the budget of the prompts sent to the AI provider,
+	// unlimited when unset. It is read from the max_prompt_tokens
+	// configuration key.
+	MaxPromptTokens int
+	// PromptTemplate replaces the default prompt when set, from the
+	// prompt_template configuration key. See LoadPromptTemplate.
+	PromptTemplate *template.Template
+	// IncludeLogs adds the last LogLines lines logged by the previous
+	// instance of the failing container to the prompts explaining Pod
+	// results. LogLines is read from the log_lines configuration key.
+	IncludeLogs bool
+	LogLines    int
+	// Resolved holds the findings of PreviousRun gone since, once SinceLast
+	// left only the new findings in Results.
+	Resolved    []common.Result
+	PreviousRun time.Time
 }
 
 type (
@@ -70,6 +183,8 @@ type JsonOutput struct {
 	Status   AnalysisStatus  `json:"status"`
 	Problems int             `json:"problems"`
 	Results  []common.Result `json:"results"`
+	// Resolved is only set when comparing with the previous run.
+	Resolved []common.Result `json:"resolved,omitempty"`
 }
 
 func NewAnalysis(
@@ -86,14 +201,29 @@ func NewAnalysis(
 	httpHeaders []string,
 	withStats bool,
 ) (*Analysis, error) {
-	// Get kubernetes client from viper.
-	kubecontext := viper.GetString("kubecontext")
-	kubeconfig := viper.GetString("kubeconfig")
-	client, err := kubernetes.NewClient(kubecontext, kubeconfig)
-	if err != nil {
-		return nil, fmt.Errorf("initialising kubernetes client: %w", err)
+	// A malformed selector would otherwise fail every analyzer with an API error.
+	if _, err := labels.Parse(labelSelector); err != nil {
+		return nil, fmt.Errorf("%w %q: %w", ErrInvalidLabelSelector, labelSelector, err)
 	}
 
+	// Get kubernetes client from viper, or the manifests of a directory.
+	var client *kubernetes.Client
+	var err error
+	if dir := viper.GetString("from_dir"); dir != "" {
+		client, err = kubernetes.NewClientFromDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("loading manifests: %w", err)
+		}
+	} else {
+		kubecontext := viper.GetString("kubecontext")
+		kubeconfig := viper.GetString("kubeconfig")
+		client, err = kubernetes.NewClient(kubecontext, kubeconfig)
+		if err != nil {
+			return nil, fmt.Errorf("initialising kubernetes client: %w", err)
+		}
+	}
+	client.Limiter = kubernetes.NewLimiter(viper.GetFloat64("kubernetes_qps"), viper.GetInt("kubernetes_burst"))
+
 	// Load remote cache if it is configured.
 	cache, err := cache.GetCacheConfiguration()
 	if err != nil {
@@ -116,7 +246,47 @@ func NewAnalysis(
 		MaxConcurrency: maxConcurrency,
 		WithDoc:        withDoc,
 		WithStats:      withStats,
+
+		ExcludeFilters:          viper.GetStringSlice("exclude_filters"),
+		ExcludeContainers:       viper.GetStringSlice("exclude_containers"),
+		PodEnvSourceLookups:     viper.GetBool("pod_env_source_lookups"),
+		PodErrorReasons:         viper.GetStringSlice("pod_error_reasons"),
+		PodEventErrorReasons:    viper.GetStringSlice("pod_event_error_reasons"),
+		ResourceQuotaThreshold:  viper.GetFloat64("resource_quota_threshold"),
+		EventLookback:           viper.GetDuration("event_lookback"),
+		VolumeAttachmentTimeout: viper.GetDuration("volume_attachment_timeout"),
+		MaxPromptTokens:         viper.GetInt("max_prompt_tokens"),
+		IncludeNamespaces:       viper.GetStringSlice("include_namespaces"),
+		ExcludeNamespaces:       viper.GetStringSlice("exclude_namespaces"),
+		AIRetries:               defaultAIRetries,
+		AIRetryDelay:            defaultAIRetryDelay,
+		LogLines:                defaultLogLines,
+	}
+	if viper.IsSet("ai_retries") {
+		a.AIRetries = viper.GetInt("ai_retries")
+	}
+	if viper.IsSet("ai_retry_delay") {
+		a.AIRetryDelay = viper.GetDuration("ai_retry_delay")
 	}
+	if viper.IsSet("log_lines") {
+		a.LogLines = viper.GetInt("log_lines")
+	}
+	if a.CustomResources, err = analyzer.CustomResources(); err != nil {
+		return nil, err
+	}
+	if viper.IsSet("analyzer_timeout") {
+		a.AnalyzerTimeout = viper.GetDuration("analyzer_timeout")
+	}
+	if path := viper.GetString("prompt_template"); path != "" {
+		if a.PromptTemplate, err = LoadPromptTemplate(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := checkNamespaceExists(a.Context, client, namespace); err != nil {
+		return nil, err
+	}
+
 	if !explain {
 		// Return early if AI use was not requested.
 		return a, nil
@@ -128,7 +298,8 @@ func NewAnalysis(
 	}
 
 	if len(configAI.Providers) == 0 {
-		return nil, errors.New("AI provider not specified in configuration. Please run k8sgpt auth")
+		a.disableExplain("AI provider not specified in configuration. Please run k8sgpt auth")
+		return a, nil
 	}
 
 	// Backend string will have high priority than a default provider
@@ -150,7 +321,8 @@ func NewAnalysis(
 	}
 
 	if aiProvider.Name == "" {
-		return nil, fmt.Errorf("AI provider %s not specified in configuration. Please run k8sgpt auth", backend)
+		a.disableExplain(fmt.Sprintf("AI provider %s not specified in configuration. Please run k8sgpt auth", backend))
+		return a, nil
 	}
 
 	aiClient := ai.NewClient(aiProvider.Name)
@@ -160,10 +332,40 @@ func NewAnalysis(
 		return nil, err
 	}
 	a.AIClient = aiClient
+	a.kindAIClients, err = newKindAIClients(aiProvider)
+	if err != nil {
+		aiClient.Close()
+		return nil, err
+	}
+	a.aiModel = aiProvider.Model
+	a.kindAIModels = aiProvider.Models
 	a.AnalysisAIProvider = aiProvider.Name
+	a.Tokenizer = ai.NewTokenizer(aiProvider.Model)
 	return a, nil
 }
 
+// disableExplain turns off AI explanations when no provider is configured, so
+// the analysis still reports its findings instead of failing on the first
+// explanation. The reason is surfaced as a warning.
+func (a *Analysis) disableExplain(reason string) {
+	a.Explain = false
+	a.Errors = append(a.Errors, fmt.Sprintf("explanations disabled: %s", reason))
+}
+
+// checkNamespaceExists fails fast when the requested namespace does not exist,
+// rather than letting every analyzer report an empty result for it.
+// Other lookup errors (e.g. missing RBAC on namespaces) are not fatal.
+func checkNamespaceExists(ctx context.Context, client *kubernetes.Client, namespace string) error {
+	if namespace == "" {
+		return nil
+	}
+	_, err := client.GetClient().CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("namespace %s not found", namespace)
+	}
+	return nil
+}
+
 func (a *Analysis) CustomAnalyzersAreAvailable() bool {
 	var customAnalyzers []custom.CustomAnalyzer
 	if err := viper.UnmarshalKey("custom_analyzers", &customAnalyzers); err != nil {
@@ -179,7 +381,7 @@ func (a *Analysis) RunCustomAnalysis() {
 		return
 	}
 
-	semaphore := make(chan struct{}, a.MaxConcurrency)
+	semaphore := make(chan struct{}, a.concurrency())
 	var wg sync.WaitGroup
 	var mutex sync.Mutex
 	for _, cAnalyzer := range customAnalyzers {
@@ -204,11 +406,14 @@ func (a *Analysis) RunCustomAnalysis() {
 			}
 			if err != nil {
 				mutex.Lock()
-				a.Errors = append(a.Errors, fmt.Sprintf("[%s] %s", cAnalyzer.Name, err))
+				a.addAnalyzerError(cAnalyzer.Name, err)
 				mutex.Unlock()
-			} else {
+			} else if result, ok := a.applySeverity(result); ok && a.namespaceAllowed(result) && (a.ResultFilter == nil || a.ResultFilter(result)) {
 				mutex.Lock()
 				a.Results = append(a.Results, result)
+				if a.OnResult != nil {
+					a.OnResult(result)
+				}
 				mutex.Unlock()
 			}
 			<-semaphore
@@ -220,6 +425,28 @@ func (a *Analysis) RunCustomAnalysis() {
 func (a *Analysis) RunAnalysis() {
 	activeFilters := viper.GetStringSlice("active_filters")
 
+	// Analyzers finish in any order, so results are sorted for stable output.
+	defer func() {
+		if a.GroupByParent {
+			a.Results = groupByParent(a.Results)
+		}
+		sortResults(a.Results)
+	}()
+
+	// A webhook blocking writes is reported first, as it can explain the other findings.
+	a.Errors = append(a.Errors, blockingWebhookWarnings(a.Context, a.Client.GetClient())...)
+
+	a.suppressions = newSuppressions(a.Context, a.Client)
+
+	if a.FieldSelector != "" && util.FieldSelectorFor("", a.FieldSelector) == "" {
+		kinds := util.FieldSelectorKinds(a.FieldSelector)
+		if len(kinds) == 0 {
+			a.Errors = append(a.Errors, fmt.Sprintf("field selector %s is not supported by any kind and is ignored", a.FieldSelector))
+		} else {
+			a.Errors = append(a.Errors, fmt.Sprintf("field selector %s only applies to %s objects; the other kinds are analyzed without it", a.FieldSelector, strings.Join(kinds, ", ")))
+		}
+	}
+
 	coreAnalyzerMap, analyzerMap := analyzer.GetAnalyzerMap()
 
 	// we get the openapi schema from the server only if required by the flag "with-doc"
@@ -240,69 +467,87 @@ func (a *Analysis) RunAnalysis() {
 		LabelSelector: a.LabelSelector,
 		AIClient:      a.AIClient,
 		OpenapiSchema: openapiSchema,
+		MinAge:        a.MinAge,
+		FieldSelector: a.FieldSelector,
+
+		ExcludeContainers:       a.ExcludeContainers,
+		PodEnvSourceLookups:     a.PodEnvSourceLookups,
+		PodErrorReasons:         a.PodErrorReasons,
+		PodEventErrorReasons:    a.PodEventErrorReasons,
+		CustomResources:         a.CustomResources,
+		ResourceQuotaThreshold:  a.ResourceQuotaThreshold,
+		EventLookback:           a.EventLookback,
+		VolumeAttachmentTimeout: a.VolumeAttachmentTimeout,
 	}
 
-	semaphore := make(chan struct{}, a.MaxConcurrency)
+	semaphore := make(chan struct{}, a.concurrency())
 	var wg sync.WaitGroup
 	var mutex sync.Mutex
+	var filters []string
+	analyzers := analyzerMap
+	switch {
 	// if there are no filters selected and no active_filters then run coreAnalyzer
-	if len(a.Filters) == 0 && len(activeFilters) == 0 {
-		for name, analyzer := range coreAnalyzerMap {
-			wg.Add(1)
-			semaphore <- struct{}{}
-			go a.executeAnalyzer(analyzer, name, analyzerConfig, semaphore, &wg, &mutex)
-
+	case len(a.Filters) == 0 && len(activeFilters) == 0:
+		analyzers = coreAnalyzerMap
+		for name := range coreAnalyzerMap {
+			filters = append(filters, name)
 		}
-		wg.Wait()
-		return
-	}
 	// if the filters flag is specified
-	if len(a.Filters) != 0 {
-		for _, filter := range a.Filters {
-			if analyzer, ok := analyzerMap[filter]; ok {
-				semaphore <- struct{}{}
-				wg.Add(1)
-				go a.executeAnalyzer(analyzer, filter, analyzerConfig, semaphore, &wg, &mutex)
-			} else {
-				a.Errors = append(a.Errors, fmt.Sprintf("\"%s\" filter does not exist. Please run k8sgpt filters list.", filter))
-			}
+	case len(a.Filters) != 0:
+		var unknown []string
+		filters, unknown = analyzer.ExpandFilterGroups(a.Filters, analyzerMap)
+		for _, filter := range unknown {
+			a.Errors = append(a.Errors, fmt.Sprintf("\"%s\" filter does not exist. Please run k8sgpt filters list. Available filter groups: %s.", filter, strings.Join(analyzer.FilterGroupNames(), ", ")))
 		}
-		wg.Wait()
-		return
+	// use active_filters
+	default:
+		filters, _ = analyzer.ExpandFilterGroups(activeFilters, analyzerMap)
 	}
 
-	// use active_filters
-	for _, filter := range activeFilters {
-		if analyzer, ok := analyzerMap[filter]; ok {
-			semaphore <- struct{}{}
-			wg.Add(1)
-			go a.executeAnalyzer(analyzer, filter, analyzerConfig, semaphore, &wg, &mutex)
+	if len(a.ExcludeFilters) > 0 {
+		var unknown []string
+		filters, unknown = analyzer.ExcludeFilters(filters, a.ExcludeFilters, analyzerMap)
+		for _, filter := range unknown {
+			a.Errors = append(a.Errors, fmt.Sprintf("\"%s\" excluded filter does not exist. Please run k8sgpt filters list or k8sgpt integration list.", filter))
 		}
 	}
+
+	for _, filter := range filters {
+		semaphore <- struct{}{}
+		wg.Add(1)
+		go a.executeAnalyzer(analyzers[filter], filter, analyzerConfig, semaphore, &wg, &mutex)
+	}
 	wg.Wait()
 }
 
-func (a *Analysis) executeAnalyzer(analyzer common.IAnalyzer, filter string, analyzerConfig common.Analyzer, semaphore chan struct{}, wg *sync.WaitGroup, mutex *sync.Mutex) {
+func (a *Analysis) executeAnalyzer(iAnalyzer common.IAnalyzer, filter string, analyzerConfig common.Analyzer, semaphore chan struct{}, wg *sync.WaitGroup, mutex *sync.Mutex) {
 	defer wg.Done()
 
-	var startTime time.Time
-	var elapsedTime time.Duration
-
 	// Start the timer
-	if a.WithStats {
-		startTime = time.Now()
-	}
+	startTime := time.Now()
 
 	// Run the analyzer
-	results, err := analyzer.Analyze(analyzerConfig)
+	results, err := a.analyzeWithTimeout(iAnalyzer, analyzerConfig)
+	analyzer.AnalyzerDurationMetric.WithLabelValues(filter).Observe(time.Since(startTime).Seconds())
+	if err == nil && a.suppressions != nil {
+		results = filterResults(results, func(result common.Result) bool {
+			return !a.suppressions.suppressed(filter, result)
+		})
+	}
+	if err == nil {
+		results = a.applySeverities(results)
+	}
+	if err == nil && (len(a.IncludeNamespaces) > 0 || len(a.ExcludeNamespaces) > 0) {
+		results = filterResults(results, a.namespaceAllowed)
+	}
+	if err == nil && a.ResultFilter != nil {
+		results = filterResults(results, a.ResultFilter)
+	}
 
 	// Measure the time taken
-	if a.WithStats {
-		elapsedTime = time.Since(startTime)
-	}
 	stat := common.AnalysisStats{
 		Analyzer:     filter,
-		DurationTime: elapsedTime,
+		DurationTime: time.Since(startTime),
 	}
 
 	mutex.Lock()
@@ -312,45 +557,101 @@ func (a *Analysis) executeAnalyzer(analyzer common.IAnalyzer, filter string, ana
 		if a.WithStats {
 			a.Stats = append(a.Stats, stat)
 		}
-		a.Errors = append(a.Errors, fmt.Sprintf("[%s] %s", filter, err))
+		a.addAnalyzerError(filter, err)
 	} else {
 		if a.WithStats {
 			a.Stats = append(a.Stats, stat)
 		}
 		a.Results = append(a.Results, results...)
+		if a.OnResult != nil {
+			for _, result := range results {
+				a.OnResult(result)
+			}
+		}
+		if len(results) == 0 {
+			a.analyzersWithoutFindings = append(a.analyzersWithoutFindings, filter)
+		}
 	}
 	<-semaphore
 }
 
+// concurrency returns how many analyzers run at once: MaxConcurrency, or
+// GOMAXPROCS when it is not set.
+func (a *Analysis) concurrency() int {
+	if a.MaxConcurrency > 0 {
+		return a.MaxConcurrency
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// namespaceAllowed reports whether the namespace of the result, taken from
+// its namespace/name, matches IncludeNamespaces, when set, and none of
+// ExcludeNamespaces.
+func (a *Analysis) namespaceAllowed(result common.Result) bool {
+	namespace, _, found := strings.Cut(result.Name, "/")
+	if !found {
+		return true
+	}
+	if len(a.IncludeNamespaces) > 0 && !matchesAny(namespace, a.IncludeNamespaces) {
+		return false
+	}
+	return !matchesAny(namespace, a.ExcludeNamespaces)
+}
+
+func matchesAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// sortResults orders results by kind, then name.
+func sortResults(results []common.Result) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Kind != results[j].Kind {
+			return results[i].Kind < results[j].Kind
+		}
+		return results[i].Name < results[j].Name
+	})
+}
+
+func filterResults(results []common.Result, keep func(common.Result) bool) []common.Result {
+	var kept []common.Result
+	for _, result := range results {
+		if keep(result) {
+			kept = append(kept, result)
+		}
+	}
+	return kept
+}
+
 func (a *Analysis) GetAIResults(output string, anonymize bool) error {
 	if len(a.Results) == 0 {
 		return nil
 	}
 
+	if anonymize && a.masks == nil {
+		a.masks = newMaskTable()
+	}
+
 	var bar *progressbar.ProgressBar
 	if output != "json" {
 		bar = progressbar.Default(int64(len(a.Results)))
 	}
 
 	for index, analysis := range a.Results {
-		var texts []string
-
-		for _, failure := range analysis.Error {
-			if anonymize {
-				for _, s := range failure.Sensitive {
-					failure.Text = util.ReplaceIfMatch(failure.Text, s.Unmasked, s.Masked)
-				}
+		result, err := a.explain(a.Context, analysis, anonymize)
+		// A single unusable explanation doesn't fail the others, which keep
+		// no Details.
+		if errors.Is(err, ErrAIEmptyResponse) || errors.Is(err, ErrAIRefusal) {
+			a.Errors = append(a.Errors, fmt.Sprintf("[AI] %s %s: %v", analysis.Kind, analysis.Name, err))
+			if output != "json" {
+				_ = bar.Add(1)
 			}
-			texts = append(texts, failure.Text)
+			continue
 		}
-
-		promptTemplate := ai.PromptMap["default"]
-		// If the resource `Kind` comes from an "integration plugin",
-		// maybe a customized prompt template will be involved.
-		if prompt, ok := ai.PromptMap[analysis.Kind]; ok {
-			promptTemplate = prompt
-		}
-		result, err := a.getAIResultForSanitizedFailures(texts, promptTemplate)
 		if err != nil {
 			// FIXME: can we avoid checking if output is json multiple times?
 			//   maybe implement the progress bar better?
@@ -360,20 +661,16 @@ func (a *Analysis) GetAIResults(output string, anonymize bool) error {
 
 			// Check for exhaustion.
 			if strings.Contains(err.Error(), "status code: 429") {
-				return fmt.Errorf("exhausted API quota for AI provider %s: %v", a.AIClient.GetName(), err)
-			}
-			return fmt.Errorf("failed while calling AI provider %s: %v", a.AIClient.GetName(), err)
-		}
-
-		if anonymize {
-			for _, failure := range analysis.Error {
-				for _, s := range failure.Sensitive {
-					result = strings.ReplaceAll(result, s.Masked, s.Unmasked)
-				}
+				return fmt.Errorf("%w for AI provider %s: %w", ErrAIQuotaExhausted, a.AIClient.GetName(), err)
 			}
+			return fmt.Errorf("%w %s: %w", ErrAIRequestFailed, a.AIClient.GetName(), err)
 		}
 
 		analysis.Details = result
+		analysis = a.validateExplanation(analysis)
+		if a.ScoreExplanations {
+			analysis.Confidence, analysis.HasRemediation = ScoreExplanation(analysis)
+		}
 		if output != "json" {
 			_ = bar.Add(1)
 		}
@@ -382,11 +679,203 @@ func (a *Analysis) GetAIResults(output string, anonymize bool) error {
 	return nil
 }
 
-func (a *Analysis) getAIResultForSanitizedFailures(texts []string, promptTmpl string) (string, error) {
-	inputKey := strings.Join(texts, " ")
-	// Check for cached data.
-	// TODO(bwplotka): This might depend on model too (or even other client configuration pieces), fix it in later PRs.
-	cacheKey := util.GetCacheKey(a.AIClient.GetName(), a.Language, inputKey)
+// GetExplanation returns the AI explanation for the failures of a single
+// object, without running any analyzers. It shares the anonymization, prompt
+// selection and cache with GetAIResults, so library users can explain their
+// own failures. (Analysis.Explain is the field enabling AI, hence the name.)
+func (a *Analysis) GetExplanation(ctx context.Context, kind string, failures []common.Failure, anonymize bool) (string, error) {
+	return a.explain(ctx, common.Result{Kind: kind, Error: failures}, anonymize)
+}
+
+func (a *Analysis) explain(ctx context.Context, result common.Result, anonymize bool) (string, error) {
+	kind, failures := result.Kind, result.Error
+	if a.AIClient == nil {
+		return "", ErrAIProviderNotInitialized
+	}
+	if len(failures) == 0 {
+		return "", nil
+	}
+
+	var masks *maskTable
+	if anonymize {
+		// Outside of GetAIResults, values are only masked consistently
+		// within this call.
+		masks = a.masks
+		if masks == nil {
+			masks = newMaskTable()
+		}
+	}
+	texts := maskFailureTexts(failures, masks)
+	data := PromptData{
+		Kind:   kind,
+		Name:   maskSensitive(result.Name, failures, masks),
+		Errors: texts,
+		Logs:   maskSensitive(a.containerLogs(ctx, result), failures, masks),
+	}
+	promptTmpl := promptTemplateForKind(kind)
+	data, dropped := a.fitPromptBudget(data, failures, promptTmpl)
+	if dropped != "" {
+		subject := kind
+		if result.Name != "" {
+			subject += " " + result.Name
+		}
+		a.explainMutex.Lock()
+		a.Errors = append(a.Errors, fmt.Sprintf("[AI] %s: %s", subject, dropped))
+		a.explainMutex.Unlock()
+	}
+	client, model := a.aiClientForKind(kind)
+	response, err := a.getAIResult(ctx, client, model, data, promptTmpl)
+	if err != nil {
+		return "", err
+	}
+	return unmaskResponse(response, masks), nil
+}
+
+// aiClientForKind returns the AI client explaining results of the given
+// kind, and its model: the one for the model set for the kind, or the
+// default client.
+func (a *Analysis) aiClientForKind(kind string) (ai.IAI, string) {
+	if client, ok := a.kindAIClients[kind]; ok {
+		return client, a.kindAIModels[kind]
+	}
+	return a.AIClient, a.aiModel
+}
+
+// newKindAIClients configures a client per model set in the provider's
+// models setting, so cheaper or larger models can explain results depending
+// on the analyzer that produced them. Kinds sharing a model share the client.
+func newKindAIClients(provider ai.AIProvider) (map[string]ai.IAI, error) {
+	clients := map[string]ai.IAI{}
+	byModel := map[string]ai.IAI{}
+	for kind, model := range provider.Models {
+		if model == "" || model == provider.Model {
+			continue
+		}
+		client, ok := byModel[model]
+		if !ok {
+			p := provider
+			p.Model = model
+			client = ai.NewClient(p.Name)
+			if err := client.Configure(&p); err != nil {
+				for _, c := range byModel {
+					c.Close()
+				}
+				return nil, fmt.Errorf("configuring model %s for %s results: %w", model, kind, err)
+			}
+			byModel[model] = client
+		}
+		clients[kind] = client
+	}
+	return clients, nil
+}
+
+// maskTable maps sensitive values to their masked form for a whole run, so a
+// value is masked the same way in every prompt and explanations referring to
+// the same object across results stay coherent.
+type maskTable struct {
+	mu       sync.Mutex
+	masked   map[string]string
+	unmasked map[string]string
+}
+
+func newMaskTable() *maskTable {
+	return &maskTable{
+		masked:   map[string]string{},
+		unmasked: map[string]string{},
+	}
+}
+
+// mask returns the masked form of the sensitive value: the one used first in
+// the run, or the analyzer's own masked form if the value wasn't seen yet.
+func (m *maskTable) mask(s common.Sensitive) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if masked, ok := m.masked[s.Unmasked]; ok {
+		return masked
+	}
+	m.masked[s.Unmasked] = s.Masked
+	m.unmasked[s.Masked] = s.Unmasked
+	return s.Masked
+}
+
+// maskFailureTexts returns the failure texts to send to the AI provider, with
+// sensitive values replaced by their masked form when masks is set.
+func maskFailureTexts(failures []common.Failure, masks *maskTable) []string {
+	var texts []string
+	for _, failure := range failures {
+		text := failure.Text
+		if failure.FieldPath != "" {
+			text = fmt.Sprintf("%s (field %s)", text, failure.FieldPath)
+		}
+		if masks != nil {
+			for _, s := range failure.Sensitive {
+				text = util.ReplaceIfMatch(text, s.Unmasked, masks.mask(s))
+			}
+		}
+		texts = append(texts, text)
+	}
+	return texts
+}
+
+// maskSensitive returns text to send to the AI provider along the failures,
+// such as the result name, masked with the sensitive values of the failures
+// when masks is set.
+func maskSensitive(text string, failures []common.Failure, masks *maskTable) string {
+	if masks == nil {
+		return text
+	}
+	for _, failure := range failures {
+		for _, s := range failure.Sensitive {
+			text = util.ReplaceIfMatch(text, s.Unmasked, masks.mask(s))
+		}
+	}
+	return text
+}
+
+// unmaskResponse restores the sensitive values masked by maskFailureTexts.
+func unmaskResponse(response string, masks *maskTable) string {
+	if masks == nil {
+		return response
+	}
+	masks.mu.Lock()
+	defer masks.mu.Unlock()
+	for masked, unmasked := range masks.unmasked {
+		response = strings.ReplaceAll(response, masked, unmasked)
+	}
+	return response
+}
+
+// promptTemplateForKind returns the prompt template used for results of the given kind.
+func promptTemplateForKind(kind string) string {
+	// If the resource `Kind` comes from an "integration plugin",
+	// maybe a customized prompt template will be involved.
+	if prompt, ok := ai.PromptMap[kind]; ok {
+		return prompt
+	}
+	return ai.PromptMap["default"]
+}
+
+func (a *Analysis) getAIResult(ctx context.Context, client ai.IAI, model string, data PromptData, promptTmpl string) (string, error) {
+	data.Language = a.Language
+	data.Error = strings.Join(data.Errors, " ")
+
+	// Process template.
+	prompt, err := a.renderPrompt(data, promptTmpl)
+	if err != nil {
+		return "", err
+	}
+
+	// Check for cached data. Explanations depend on the model, as models
+	// can be set per kind.
+	provider := client.GetName()
+	if model != "" {
+		provider += "/" + model
+	}
+	cacheKey := util.GetCacheKey(provider, a.Language, data.Error)
+	if a.PromptTemplate != nil || data.Logs != "" {
+		// Explanations depend on the custom prompt or the logs as a whole.
+		cacheKey = util.GetCacheKey(provider, a.Language, prompt)
+	}
 
 	if !a.Cache.IsCacheDisabled() && a.Cache.Exists(cacheKey) {
 		response, err := a.Cache.Load(cacheKey)
@@ -397,18 +886,27 @@ func (a *Analysis) getAIResultForSanitizedFailures(texts []string, promptTmpl st
 		if response != "" {
 			output, err := base64.StdEncoding.DecodeString(response)
 			if err == nil {
+				analyzer.AICacheHitsMetric.WithLabelValues(client.GetName()).Inc()
 				return string(output), nil
 			}
 			color.Red("error decoding cached data; ignoring cache item: %v", err)
 		}
 	}
+	if !a.Cache.IsCacheDisabled() {
+		analyzer.AICacheMissesMetric.WithLabelValues(client.GetName()).Inc()
+	}
 
-	// Process template.
-	prompt := fmt.Sprintf(strings.TrimSpace(promptTmpl), a.Language, inputKey)
-	response, err := a.AIClient.GetCompletion(a.Context, prompt)
+	if a.Tokenizer != nil {
+		a.PromptTokens += a.Tokenizer.CountTokens(prompt)
+	}
+	response, err := a.getCompletionWithRetry(ctx, client, prompt)
 	if err != nil {
 		return "", err
 	}
+	// Unusable responses aren't cached, so the next run asks again.
+	if err := checkAIResponse(client.GetName(), response); err != nil {
+		return "", err
+	}
 
 	if err = a.Cache.Store(cacheKey, base64.StdEncoding.EncodeToString([]byte(response))); err != nil {
 		color.Red("error storing value to cache; value won't be cached: %v", err)
@@ -416,7 +914,31 @@ func (a *Analysis) getAIResultForSanitizedFailures(texts []string, promptTmpl st
 	return response, nil
 }
 
+// refusalPattern matches the openings of responses declining to answer.
+var refusalPattern = regexp.MustCompile(`(?i)^\W*(i['’]m sorry|i am sorry|sorry, (but )?i|i cannot|i can['’]?t|i can not|i['’]m (not able|unable)|i am (not able|unable)|i won['’]t|as an ai)\b`)
+
+// checkAIResponse returns ErrAIEmptyResponse or ErrAIRefusal for responses
+// that explain nothing.
+func checkAIResponse(provider string, response string) error {
+	response = strings.TrimSpace(response)
+	if response == "" {
+		return fmt.Errorf("%w %s", ErrAIEmptyResponse, provider)
+	}
+	if refusalPattern.MatchString(response) {
+		line, _, _ := strings.Cut(response, "\n")
+		return fmt.Errorf("%w %s: %q", ErrAIRefusal, provider, line)
+	}
+	return nil
+}
+
 func (a *Analysis) Close() {
+	closed := map[ai.IAI]bool{}
+	for _, client := range a.kindAIClients {
+		if !closed[client] {
+			closed[client] = true
+			client.Close()
+		}
+	}
 	if a.AIClient == nil {
 		return
 	}
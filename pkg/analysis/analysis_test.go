@@ -18,13 +18,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/analyzer"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/cache"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
 	"github.com/magiconair/properties/assert"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
@@ -34,7 +41,7 @@ import (
 )
 
 // sub-function
-func analysis_RunAnalysisFilterTester(t *testing.T, filterFlag string) []common.Result {
+func analysis_RunAnalysisFilterTester(t *testing.T, filterFlag string, exclude ...string) []common.Result {
 	clientset := fake.NewSimpleClientset(
 		&v1.Pod{
 			ObjectMeta: metav1.ObjectMeta{
@@ -94,6 +101,7 @@ func analysis_RunAnalysisFilterTester(t *testing.T, filterFlag string) []common.
 		// `--filter` is explicitly given
 		analysis.Filters = strings.Split(filterFlag, ",")
 	}
+	analysis.ExcludeFilters = exclude
 	analysis.RunAnalysis()
 	return analysis.Results
 
@@ -107,6 +115,8 @@ func TestAnalysis_RunAnalysisWithFilter(t *testing.T) {
 	//1. Neither --filter flag Nor active filter is specified, only the "core analyzers"
 	results = analysis_RunAnalysisFilterTester(t, "")
 	assert.Equal(t, len(results), 3) // all built-in resource will be analyzed
+	// results are sorted by kind whatever order the analyzers finished in
+	assert.Equal(t, []string{"Ingress", "Pod", "Service"}, []string{results[0].Kind, results[1].Kind, results[2].Kind})
 
 	//2. When the --filter flag is specified
 
@@ -120,6 +130,35 @@ func TestAnalysis_RunAnalysisWithFilter(t *testing.T) {
 	assert.Equal(t, len(results), 2)
 }
 
+// Test: Excluded analyzers don't run, whichever filters apply
+func TestAnalysis_RunAnalysisExcludeFilters(t *testing.T) {
+	results := analysis_RunAnalysisFilterTester(t, "", "Pod")
+	assert.Equal(t, []string{"Ingress", "Service"}, []string{results[0].Kind, results[1].Kind})
+
+	results = analysis_RunAnalysisFilterTester(t, "Ingress,Pod", "networking")
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, "Pod", results[0].Kind)
+
+	results = analysis_RunAnalysisFilterTester(t, "Pod", "Unknown")
+	assert.Equal(t, 1, len(results))
+}
+
+func analyzerDurationCount(t *testing.T, name string) uint64 {
+	var metric dto.Metric
+	require.NoError(t, analyzer.AnalyzerDurationMetric.WithLabelValues(name).(prometheus.Metric).Write(&metric))
+	return metric.GetHistogram().GetSampleCount()
+}
+
+// Test: The duration of each analyzer run is observed once
+func TestAnalysis_AnalyzerDurationMetric(t *testing.T) {
+	ingress, pod, service := analyzerDurationCount(t, "Ingress"), analyzerDurationCount(t, "Pod"), analyzerDurationCount(t, "Service")
+
+	analysis_RunAnalysisFilterTester(t, "Ingress,Pod")
+	assert.Equal(t, ingress+1, analyzerDurationCount(t, "Ingress"))
+	assert.Equal(t, pod+1, analyzerDurationCount(t, "Pod"))
+	assert.Equal(t, service, analyzerDurationCount(t, "Service"))
+}
+
 // Test:  Filter logic with Active Filter
 func TestAnalysis_RunAnalysisActiveFilter(t *testing.T) {
 
@@ -312,18 +351,18 @@ func TestNewAnalysis(t *testing.T) {
 
 	tests := []struct {
 		name        string
-		a           Analysis
+		a           *Analysis
 		output      string
 		anonymize   bool
 		expectedErr string
 	}{
 		{
 			name: "Empty results",
-			a:    Analysis{},
+			a:    &Analysis{},
 		},
 		{
 			name: "cache disabled",
-			a: Analysis{
+			a: &Analysis{
 				AIClient: aiClient,
 				Cache:    disabledCache,
 				Results:  results,
@@ -331,7 +370,7 @@ func TestNewAnalysis(t *testing.T) {
 		},
 		{
 			name: "output and anonymize both set",
-			a: Analysis{
+			a: &Analysis{
 				AIClient: aiClient,
 				Cache:    cache.New("test-cache"),
 				Results:  results,
@@ -354,53 +393,446 @@ func TestNewAnalysis(t *testing.T) {
 	}
 }
 
-func TestGetAIResultForSanitizedFailures(t *testing.T) {
-	enabledCache := cache.New("enabled-cache")
+func TestGetExplanationPrompt(t *testing.T) {
 	disabledCache := cache.New("disabled-cache")
 	disabledCache.DisableCache()
 	aiClient := &ai.NoOpAIClient{}
 
 	tests := []struct {
-		name           string
-		a              Analysis
-		texts          []string
-		promptTmpl     string
-		expectedOutput string
-		expectedErr    string
+		name        string
+		a           *Analysis
+		text        string
+		countTokens bool
 	}{
 		{
 			name: "Cache enabled",
-			a: Analysis{
+			a: &Analysis{
 				AIClient: aiClient,
-				Cache:    enabledCache,
+				Cache:    &mapCache{items: map[string]string{}},
 			},
-			texts:          []string{"some-data"},
-			expectedOutput: "I am a noop response to the prompt %!(EXTRA string=, string=some-data)",
+			text: "some-data",
 		},
 		{
 			name: "cache disabled",
-			a: Analysis{
+			a: &Analysis{
 				AIClient: aiClient,
 				Cache:    disabledCache,
 				Language: "English",
 			},
-			texts:          []string{"test input"},
-			promptTmpl:     "Response in %s: %s",
-			expectedOutput: "I am a noop response to the prompt Response in English: test input",
+			text: "test input",
+		},
+		{
+			name: "prompt tokens counted",
+			a: &Analysis{
+				AIClient:  aiClient,
+				Cache:     disabledCache,
+				Language:  "English",
+				Tokenizer: ai.NewTokenizer("gpt-4o"),
+			},
+			text:        "test input",
+			countTokens: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := tt.a.GetExplanation(context.Background(), "Pod", []common.Failure{{Text: tt.text}}, false)
+			require.NoError(t, err)
+			prompt := fmt.Sprintf(strings.TrimSpace(ai.PromptMap["default"]), tt.a.Language, tt.text)
+			require.Equal(t, "I am a noop response to the prompt "+prompt, output)
+			if tt.countTokens {
+				require.Equal(t, tt.a.Tokenizer.CountTokens(prompt), tt.a.PromptTokens)
+			} else {
+				require.Zero(t, tt.a.PromptTokens)
+			}
+		})
+	}
+}
+
+// mapCache is an in-memory cache.ICache.
+type mapCache struct {
+	cache.ICache
+	items map[string]string
+}
+
+func (c *mapCache) Store(key string, data string) error { c.items[key] = data; return nil }
+func (c *mapCache) Load(key string) (string, error)     { return c.items[key], nil }
+func (c *mapCache) Exists(key string) bool              { _, ok := c.items[key]; return ok }
+func (c *mapCache) IsCacheDisabled() bool               { return false }
+
+func TestAICacheMetrics(t *testing.T) {
+	a := Analysis{
+		AIClient: &ai.NoOpAIClient{},
+		Cache:    &mapCache{items: map[string]string{}},
+		Language: "English",
+	}
+	hits := testutil.ToFloat64(analyzer.AICacheHitsMetric.WithLabelValues("noopai"))
+	misses := testutil.ToFloat64(analyzer.AICacheMissesMetric.WithLabelValues("noopai"))
+
+	for i := 0; i < 3; i++ {
+		_, err := a.GetExplanation(context.Background(), "Pod", []common.Failure{{Text: "same error"}}, false)
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, hits+2, testutil.ToFloat64(analyzer.AICacheHitsMetric.WithLabelValues("noopai")))
+	require.Equal(t, misses+1, testutil.ToFloat64(analyzer.AICacheMissesMetric.WithLabelValues("noopai")))
+}
+
+func TestCheckNamespaceExists(t *testing.T) {
+	client := &kubernetes.Client{
+		Client: fake.NewSimpleClientset(
+			&v1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "default",
+				},
+			},
+		),
+	}
+
+	tests := []struct {
+		name        string
+		namespace   string
+		expectedErr string
+	}{
+		{
+			name:      "all namespaces",
+			namespace: "",
+		},
+		{
+			name:      "existing namespace",
+			namespace: "default",
+		},
+		{
+			name:        "missing namespace",
+			namespace:   "defualt",
+			expectedErr: "namespace defualt not found",
 		},
 	}
 
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			output, err := tt.a.getAIResultForSanitizedFailures(tt.texts, tt.promptTmpl)
+			err := checkNamespaceExists(context.Background(), client, tt.namespace)
 			if tt.expectedErr == "" {
 				require.NoError(t, err)
-				require.Equal(t, tt.expectedOutput, output)
 			} else {
-				require.ErrorContains(t, err, tt.expectedErr)
-				require.Empty(t, output)
+				require.EqualError(t, err, tt.expectedErr)
 			}
 		})
 	}
 }
+
+func TestGetExplanation(t *testing.T) {
+	disabledCache := cache.New("disabled-cache")
+	disabledCache.DisableCache()
+	failures := []common.Failure{
+		{
+			Text: "Service default/frontend has no endpoints",
+			Sensitive: []common.Sensitive{
+				{
+					Unmasked: "frontend",
+					Masked:   "bWFza2Vk",
+				},
+			},
+		},
+	}
+
+	a := Analysis{
+		Cache:    disabledCache,
+		Language: "English",
+	}
+	_, err := a.GetExplanation(context.Background(), "Service", failures, false)
+	require.ErrorContains(t, err, "AI provider not initialized")
+
+	a.AIClient = &ai.NoOpAIClient{}
+	output, err := a.GetExplanation(context.Background(), "Service", nil, false)
+	require.NoError(t, err)
+	require.Empty(t, output)
+
+	output, err = a.GetExplanation(context.Background(), "Service", failures, false)
+	require.NoError(t, err)
+	require.Contains(t, output, "Service default/frontend has no endpoints")
+
+	require.Equal(t, []string{"Service default/bWFza2Vk has no endpoints"}, maskFailureTexts(failures, newMaskTable()))
+	// The noop provider echoes the prompt, so the masked name must be restored.
+	output, err = a.GetExplanation(context.Background(), "Service", failures, true)
+	require.NoError(t, err)
+	require.Contains(t, output, "Service default/frontend has no endpoints")
+	require.NotContains(t, output, "bWFza2Vk")
+}
+
+func TestGetExplanationLanguage(t *testing.T) {
+	disabledCache := cache.New("disabled-cache")
+	disabledCache.DisableCache()
+	a := Analysis{
+		AIClient: &ai.NoOpAIClient{},
+		Cache:    disabledCache,
+		Language: "French",
+	}
+
+	// The noop provider echoes the prompt it was sent.
+	output, err := a.GetExplanation(context.Background(), "Service", []common.Failure{{Text: "Service default/frontend has no endpoints"}}, false)
+	require.NoError(t, err)
+	require.Contains(t, output, "written in --- French --- language")
+}
+
+func TestMaskFailureTextsFieldPath(t *testing.T) {
+	failures := []common.Failure{
+		{Text: "Back-off pulling image \"nginx:lates\"", FieldPath: "spec.containers[0].image"},
+		{Text: "Deployment default/web has 3 replicas but 1 are available"},
+	}
+	require.Equal(t, []string{
+		"Back-off pulling image \"nginx:lates\" (field spec.containers[0].image)",
+		"Deployment default/web has 3 replicas but 1 are available",
+	}, maskFailureTexts(failures, nil))
+}
+
+func TestDisableExplain(t *testing.T) {
+	a := &Analysis{Explain: true}
+	a.disableExplain("AI provider openai not specified in configuration. Please run k8sgpt auth")
+
+	require.False(t, a.Explain)
+	require.Equal(t, []string{"explanations disabled: AI provider openai not specified in configuration. Please run k8sgpt auth"}, a.Errors)
+}
+
+func TestResultFilter(t *testing.T) {
+	a := &Analysis{
+		ResultFilter: func(result common.Result) bool {
+			for _, failure := range result.Error {
+				if failure.Severity == common.SeverityHigh {
+					return true
+				}
+			}
+			return false
+		},
+	}
+	var streamed []string
+	a.OnResult = func(result common.Result) {
+		streamed = append(streamed, result.Name)
+	}
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	semaphore := make(chan struct{}, 1)
+	semaphore <- struct{}{}
+	wg.Add(1)
+	a.executeAnalyzer(stubAnalyzer{results: []common.Result{
+		{Kind: "Pod", Name: "default/critical", Error: []common.Failure{{Text: "OOMKilled", Severity: common.SeverityHigh}}},
+		{Kind: "Pod", Name: "default/noisy", Error: []common.Failure{{Text: "Readiness probe failed"}}},
+	}}, "Pod", common.Analyzer{}, semaphore, &wg, &mutex)
+	wg.Wait()
+
+	require.Len(t, a.Results, 1)
+	require.Equal(t, "default/critical", a.Results[0].Name)
+	require.Equal(t, []string{"default/critical"}, streamed)
+}
+
+func TestNamespaceFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		include  []string
+		exclude  []string
+		expected []string
+	}{
+		{
+			name:     "no filters",
+			expected: []string{"kube-system/coredns", "team-a/web", "team-b/api", "node-1"},
+		},
+		{
+			name:     "exclude",
+			exclude:  []string{"kube-system"},
+			expected: []string{"team-a/web", "team-b/api", "node-1"},
+		},
+		{
+			name:     "include glob",
+			include:  []string{"team-*"},
+			expected: []string{"team-a/web", "team-b/api", "node-1"},
+		},
+		{
+			name:     "include and exclude",
+			include:  []string{"team-*"},
+			exclude:  []string{"team-b"},
+			expected: []string{"team-a/web", "node-1"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Analysis{IncludeNamespaces: tt.include, ExcludeNamespaces: tt.exclude}
+
+			var wg sync.WaitGroup
+			var mutex sync.Mutex
+			semaphore := make(chan struct{}, 1)
+			semaphore <- struct{}{}
+			wg.Add(1)
+			a.executeAnalyzer(stubAnalyzer{results: []common.Result{
+				{Kind: "Pod", Name: "kube-system/coredns"},
+				{Kind: "Pod", Name: "team-a/web"},
+				{Kind: "Pod", Name: "team-b/api"},
+				// Cluster-scoped results are always kept.
+				{Kind: "Node", Name: "node-1"},
+			}}, "Pod", common.Analyzer{}, semaphore, &wg, &mutex)
+			wg.Wait()
+
+			var names []string
+			for _, result := range a.Results {
+				names = append(names, result.Name)
+			}
+			require.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestKindAIClients(t *testing.T) {
+	clients, err := newKindAIClients(ai.AIProvider{
+		Name:  "openai",
+		Model: "base",
+		Models: map[string]string{
+			"Pod":     "small",
+			"Service": "small",
+			"Log":     "large",
+			"Node":    "base",
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, clients, 3)
+	require.Same(t, clients["Pod"], clients["Service"])
+	require.NotSame(t, clients["Pod"], clients["Log"])
+
+	defaultClient := &ai.NoOpAIClient{}
+	a := &Analysis{
+		AIClient:      defaultClient,
+		kindAIClients: clients,
+		aiModel:       "base",
+		kindAIModels:  map[string]string{"Pod": "small", "Service": "small", "Log": "large", "Node": "base"},
+	}
+	for kind, expected := range map[string]ai.IAI{"Pod": clients["Pod"], "Log": clients["Log"], "Node": defaultClient, "Ingress": defaultClient} {
+		client, _ := a.aiClientForKind(kind)
+		require.Same(t, expected, client, kind)
+	}
+	for kind, expected := range map[string]string{"Pod": "small", "Log": "large", "Node": "base", "Ingress": "base"} {
+		_, model := a.aiClientForKind(kind)
+		require.Equal(t, expected, model, kind)
+	}
+}
+
+func TestAICacheKeyModel(t *testing.T) {
+	aiCache := &mapCache{items: map[string]string{}}
+	a := &Analysis{
+		AIClient:      &ai.NoOpAIClient{},
+		Cache:         aiCache,
+		kindAIClients: map[string]ai.IAI{"Pod": &ai.NoOpAIClient{}},
+		aiModel:       "base",
+		kindAIModels:  map[string]string{"Pod": "small"},
+	}
+
+	// The same failure explained by two models of the same provider is
+	// cached twice.
+	for _, kind := range []string{"Pod", "Service", "Pod"} {
+		_, err := a.GetExplanation(context.Background(), kind, []common.Failure{{Text: "same error"}}, false)
+		require.NoError(t, err)
+	}
+	require.Len(t, aiCache.items, 2)
+}
+
+// recordingAIClient echoes prompts like the noop provider and records them.
+type recordingAIClient struct {
+	ai.NoOpAIClient
+	prompts []string
+}
+
+func (c *recordingAIClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
+	c.prompts = append(c.prompts, prompt)
+	return c.NoOpAIClient.GetCompletion(ctx, prompt)
+}
+
+func TestAnonymizationIsConsistentAcrossPrompts(t *testing.T) {
+	disabledCache := cache.New("disabled-cache")
+	disabledCache.DisableCache()
+
+	// Analyzers mask every occurrence of a value independently.
+	result := func(kind string, text string) common.Result {
+		return common.Result{
+			Kind: kind,
+			Name: "default/frontend",
+			Error: []common.Failure{
+				{
+					Text:      text,
+					Sensitive: []common.Sensitive{{Unmasked: "frontend", Masked: util.MaskString("frontend")}},
+				},
+			},
+		}
+	}
+	aiClient := &recordingAIClient{}
+	a := Analysis{
+		Context:  context.Background(),
+		AIClient: aiClient,
+		Cache:    disabledCache,
+		Language: "English",
+		Results: []common.Result{
+			result("Service", "Service frontend has no endpoints"),
+			result("Deployment", "Deployment frontend has 2 replicas but 0 are available"),
+			result("Ingress", "Ingress uses the service frontend which does not exist"),
+		},
+	}
+
+	require.NoError(t, a.GetAIResults("json", true))
+	require.Len(t, aiClient.prompts, 3)
+
+	masked := a.Results[0].Error[0].Sensitive[0].Masked
+	for _, prompt := range aiClient.prompts {
+		require.Contains(t, prompt, masked)
+		require.NotContains(t, prompt, "frontend")
+	}
+	for _, result := range a.Results {
+		require.Contains(t, result.Details, "frontend")
+		require.NotContains(t, result.Details, masked)
+	}
+}
+
+func TestSortResults(t *testing.T) {
+	results := []common.Result{
+		{Kind: "Service", Name: "default/b"},
+		{Kind: "Pod", Name: "default/b"},
+		{Kind: "Service", Name: "default/a"},
+		{Kind: "Pod", Name: "default/a"},
+	}
+	sortResults(results)
+	require.Equal(t, []common.Result{
+		{Kind: "Pod", Name: "default/a"},
+		{Kind: "Pod", Name: "default/b"},
+		{Kind: "Service", Name: "default/a"},
+		{Kind: "Service", Name: "default/b"},
+	}, results)
+}
+
+// slowAnalyzer stands in for an analyzer waiting on the API server.
+type slowAnalyzer struct {
+	name string
+}
+
+func (s slowAnalyzer) Analyze(common.Analyzer) ([]common.Result, error) {
+	time.Sleep(time.Millisecond)
+	return []common.Result{{Kind: "Pod", Name: s.name}}, nil
+}
+
+func benchmarkAnalyzers(b *testing.B, maxConcurrency int) {
+	for i := 0; i < b.N; i++ {
+		a := &Analysis{MaxConcurrency: maxConcurrency}
+		semaphore := make(chan struct{}, a.concurrency())
+		var wg sync.WaitGroup
+		var mutex sync.Mutex
+		for j := 0; j < 15; j++ {
+			name := fmt.Sprintf("analyzer-%d", j)
+			wg.Add(1)
+			semaphore <- struct{}{}
+			go a.executeAnalyzer(slowAnalyzer{name: name}, name, common.Analyzer{}, semaphore, &wg, &mutex)
+		}
+		wg.Wait()
+	}
+}
+
+func BenchmarkAnalyzersSequential(b *testing.B) { benchmarkAnalyzers(b, 1) }
+
+func BenchmarkAnalyzersConcurrent(b *testing.B) { benchmarkAnalyzers(b, 15) }
@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+)
+
+// estimateTokens counts the tokens of text with the tokenizer of the model,
+// or estimates about four characters per token without one.
+func (a *Analysis) estimateTokens(text string) int {
+	if a.Tokenizer != nil {
+		return a.Tokenizer.CountTokens(text)
+	}
+	return (len(text) + 3) / 4
+}
+
+// fitPromptBudget keeps the prompt within MaxPromptTokens by leaving out the
+// container logs, then dropping the least severe failures, then truncating
+// the text of the last one. The
+// second return value describes what was dropped, and is empty when the
+// prompt already fits.
+func (a *Analysis) fitPromptBudget(data PromptData, failures []common.Failure, promptTmpl string) (PromptData, string) {
+	if a.MaxPromptTokens <= 0 {
+		return data, ""
+	}
+	tokens := func(d PromptData) int {
+		d.Language = a.Language
+		d.Error = strings.Join(d.Errors, " ")
+		prompt, err := a.renderPrompt(d, promptTmpl)
+		if err != nil {
+			return 0
+		}
+		return a.estimateTokens(prompt)
+	}
+	if tokens(data) <= a.MaxPromptTokens {
+		return data, ""
+	}
+
+	var notes []string
+	if data.Logs != "" {
+		data.Logs = ""
+		notes = append(notes, "left the container logs out")
+		if tokens(data) <= a.MaxPromptTokens {
+			return data, fmt.Sprintf("the prompt exceeded max_prompt_tokens of %d: %s", a.MaxPromptTokens, notes[0])
+		}
+	}
+
+	// Failures are kept most severe first, in their order otherwise.
+	order := make([]int, len(data.Errors))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return failures[order[i]].Severity.Rank() > failures[order[j]].Severity.Rank()
+	})
+	texts := make([]string, len(order))
+	for i, index := range order {
+		texts[i] = data.Errors[index]
+	}
+
+	total := len(texts)
+	data.Errors = texts
+	for len(data.Errors) > 1 && tokens(data) > a.MaxPromptTokens {
+		data.Errors = data.Errors[:len(data.Errors)-1]
+	}
+
+	truncated := false
+	for over := tokens(data) - a.MaxPromptTokens; over > 0 && len(data.Errors) == 1 && data.Errors[0] != ""; over = tokens(data) - a.MaxPromptTokens {
+		text := []rune(data.Errors[0])
+		// Cut about as many characters as the tokens over budget.
+		cut := min(len(text), max(4*over, 1))
+		data.Errors = []string{string(text[:len(text)-cut])}
+		truncated = true
+	}
+
+	note := fmt.Sprintf("sent %d of %d failures", len(data.Errors), total)
+	if truncated {
+		note += ", the last one truncated"
+	}
+	notes = append(notes, note)
+	return data, fmt.Sprintf("the prompt exceeded max_prompt_tokens of %d: %s", a.MaxPromptTokens, strings.Join(notes, ", "))
+}
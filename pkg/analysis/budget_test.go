@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/cache"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFitPromptBudget(t *testing.T) {
+	failures := []common.Failure{
+		{Text: "low " + strings.Repeat("a", 40), Severity: common.SeverityLow},
+		{Text: "high " + strings.Repeat("b", 40), Severity: common.SeverityHigh},
+		{Text: "medium " + strings.Repeat("c", 40), Severity: common.SeverityMedium},
+	}
+	texts := maskFailureTexts(failures, nil)
+	// The template makes the prompt the joined failure texts.
+	const promptTmpl = "%.0s%s"
+
+	tests := []struct {
+		name           string
+		budget         int
+		expectedErrors []string
+		expectedNote   string
+	}{
+		{
+			name:           "no budget",
+			expectedErrors: texts,
+		},
+		{
+			name:           "within budget",
+			budget:         100,
+			expectedErrors: texts,
+		},
+		{
+			name:           "least severe dropped first",
+			budget:         25,
+			expectedErrors: []string{texts[1], texts[2]},
+			expectedNote:   "the prompt exceeded max_prompt_tokens of 25: sent 2 of 3 failures",
+		},
+		{
+			name:           "most severe truncated",
+			budget:         5,
+			expectedErrors: []string{texts[1][:17]},
+			expectedNote:   "the prompt exceeded max_prompt_tokens of 5: sent 1 of 3 failures, the last one truncated",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Analysis{MaxPromptTokens: tt.budget}
+			data, note := a.fitPromptBudget(PromptData{Kind: "Pod", Errors: texts}, failures, promptTmpl)
+			require.Equal(t, tt.expectedErrors, data.Errors)
+			require.Equal(t, tt.expectedNote, note)
+		})
+	}
+}
+
+func TestFitPromptBudgetLogs(t *testing.T) {
+	failures := []common.Failure{
+		{Text: "low " + strings.Repeat("a", 40), Severity: common.SeverityLow},
+		{Text: "high " + strings.Repeat("b", 40), Severity: common.SeverityHigh},
+	}
+	texts := maskFailureTexts(failures, nil)
+	data := PromptData{Kind: "Pod", Errors: texts, Logs: strings.Repeat("log line\n", 50)}
+
+	// The logs are left out before any failure.
+	a := &Analysis{MaxPromptTokens: 30}
+	fitted, note := a.fitPromptBudget(data, failures, "%.0s%s")
+	require.Empty(t, fitted.Logs)
+	require.Equal(t, texts, fitted.Errors)
+	require.Equal(t, "the prompt exceeded max_prompt_tokens of 30: left the container logs out", note)
+
+	a.MaxPromptTokens = 15
+	fitted, note = a.fitPromptBudget(data, failures, "%.0s%s")
+	require.Empty(t, fitted.Logs)
+	require.Equal(t, []string{texts[1]}, fitted.Errors)
+	require.Equal(t, "the prompt exceeded max_prompt_tokens of 15: left the container logs out, sent 1 of 2 failures", note)
+}
+
+func TestGetExplanationPromptBudget(t *testing.T) {
+	disabledCache := cache.New("disabled-cache")
+	disabledCache.DisableCache()
+	a := &Analysis{
+		AIClient:        &ai.NoOpAIClient{},
+		Cache:           disabledCache,
+		Language:        "English",
+		MaxPromptTokens: 100,
+	}
+	failures := []common.Failure{
+		{Text: "crash " + strings.Repeat("x", 400), Severity: common.SeverityHigh},
+		{Text: "probe " + strings.Repeat("y", 400), Severity: common.SeverityMedium},
+	}
+
+	output, err := a.explain(context.Background(), common.Result{Kind: "Pod", Name: "default/web", Error: failures}, false)
+	require.NoError(t, err)
+	require.Contains(t, output, "crash")
+	require.NotContains(t, output, "probe")
+	require.Equal(t, []string{"[AI] Pod default/web: the prompt exceeded max_prompt_tokens of 100: sent 1 of 2 failures, the last one truncated"}, a.Errors)
+}
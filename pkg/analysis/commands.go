@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+)
+
+// failingContainerPattern finds the container named in pod failure texts,
+// e.g. "back-off 5m0s restarting failed container=app pod=web".
+var failingContainerPattern = regexp.MustCompile(`container=([^\s,]+)`)
+
+// SuggestCommands returns the kubectl commands a user can run to inspect the
+// object of a result further: a describe for every kind, the logs of the
+// failing container for pods, the endpoints for services and the rollout
+// status for workloads. The results of the EventAnalyzer get the Warning
+// events of their object instead.
+func SuggestCommands(result common.Result) []string {
+	namespace, name := "", result.Name
+	if i := strings.Index(result.Name, "/"); i >= 0 {
+		namespace, name = result.Name[:i], result.Name[i+1:]
+	}
+	if name == "" {
+		return nil
+	}
+	scope := ""
+	if namespace != "" {
+		scope = fmt.Sprintf(" -n %s", namespace)
+	}
+	if result.Kind == "Event" {
+		return []string{fmt.Sprintf("kubectl get events%s --field-selector involvedObject.name=%s,type=Warning", scope, name)}
+	}
+	resource := strings.ToLower(result.Kind)
+
+	commands := []string{fmt.Sprintf("kubectl describe %s%s %s", resource, scope, name)}
+	switch result.Kind {
+	case "Pod":
+		if container := failingContainer(result); container != "" {
+			commands = append(commands, fmt.Sprintf("kubectl logs%s %s -c %s --previous", scope, name, container))
+		} else {
+			commands = append(commands, fmt.Sprintf("kubectl logs%s %s --all-containers", scope, name))
+		}
+		commands = append(commands, fmt.Sprintf("kubectl get events%s --field-selector involvedObject.name=%s", scope, name))
+	case "Service":
+		commands = append(commands, fmt.Sprintf("kubectl get endpoints%s %s", scope, name))
+	case "Deployment", "StatefulSet", "DaemonSet":
+		commands = append(commands, fmt.Sprintf("kubectl rollout status %s%s %s", resource, scope, name))
+	}
+	return commands
+}
+
+// failingContainer returns the first container named in the failures of a
+// pod result, or nothing when none is.
+func failingContainer(result common.Result) string {
+	for _, failure := range result.Error {
+		if match := failingContainerPattern.FindStringSubmatch(failure.Text); match != nil {
+			return match[1]
+		}
+	}
+	return ""
+}
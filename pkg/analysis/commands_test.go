@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggestCommands(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   common.Result
+		expected []string
+	}{
+		{
+			name: "pod with a failing container",
+			result: common.Result{
+				Kind: "Pod",
+				Name: "default/web",
+				Error: []common.Failure{
+					{Text: "the last termination reason is Error container=app pod=web"},
+				},
+			},
+			expected: []string{
+				"kubectl describe pod -n default web",
+				"kubectl logs -n default web -c app --previous",
+				"kubectl get events -n default --field-selector involvedObject.name=web",
+			},
+		},
+		{
+			name: "pod without a named container",
+			result: common.Result{
+				Kind:  "Pod",
+				Name:  "default/web",
+				Error: []common.Failure{{Text: "0/3 nodes are available"}},
+			},
+			expected: []string{
+				"kubectl describe pod -n default web",
+				"kubectl logs -n default web --all-containers",
+				"kubectl get events -n default --field-selector involvedObject.name=web",
+			},
+		},
+		{
+			name:   "service",
+			result: common.Result{Kind: "Service", Name: "shop/cart"},
+			expected: []string{
+				"kubectl describe service -n shop cart",
+				"kubectl get endpoints -n shop cart",
+			},
+		},
+		{
+			name:   "deployment",
+			result: common.Result{Kind: "Deployment", Name: "shop/cart"},
+			expected: []string{
+				"kubectl describe deployment -n shop cart",
+				"kubectl rollout status deployment -n shop cart",
+			},
+		},
+		{
+			name:     "warning events",
+			result:   common.Result{Kind: "Event", Name: "shop/cart"},
+			expected: []string{"kubectl get events -n shop --field-selector involvedObject.name=cart,type=Warning"},
+		},
+		{
+			name:     "cluster-scoped object",
+			result:   common.Result{Kind: "Node", Name: "worker-1"},
+			expected: []string{"kubectl describe node worker-1"},
+		},
+		{
+			name:   "result without a name",
+			result: common.Result{Kind: "Node", Name: "default/"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, SuggestCommands(tt.result))
+		})
+	}
+}
+
+func TestTextOutputWithCommands(t *testing.T) {
+	color.NoColor = true
+	result := common.Result{
+		Kind:  "Service",
+		Name:  "shop/cart",
+		Error: []common.Failure{{Text: "Service has no endpoints"}},
+	}
+
+	a := &Analysis{Results: []common.Result{result}}
+	output, err := a.PrintOutput("text")
+	require.NoError(t, err)
+	require.NotContains(t, string(output), "Suggested commands")
+
+	a.WithCommands = true
+	output, err = a.PrintOutput("text")
+	require.NoError(t, err)
+	require.Contains(t, string(output), "- Error: Service has no endpoints\n  Suggested commands:\n    kubectl describe service -n shop cart\n    kubectl get endpoints -n shop cart\n")
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+)
+
+// lowConfidence is the confidence below which an explanation is flagged in
+// the text output.
+const lowConfidence = 0.5
+
+var (
+	// solutionPattern matches the solution of the default prompt's format,
+	// "Solution: {Step by step solution here}", when it is filled in.
+	solutionPattern = regexp.MustCompile(`(?im)^\s*\**solution\**\s*:\**[ \t]*[^\s{]`)
+	// remediationStepPattern matches numbered steps and kubectl commands.
+	remediationStepPattern = regexp.MustCompile(`(?m)^\s*(\d+[.)]|[-*])\s+\S|\bkubectl\s+[a-z]+`)
+	// hedgingPattern matches the phrases of explanations unsure of the cause.
+	hedgingPattern = regexp.MustCompile(`(?i)\b(not sure|unclear|unknown cause|cannot determine|can't determine|hard to say|without more (information|context)|more information is needed)\b`)
+	// termPattern matches the words of failures an explanation addressing
+	// them likely repeats, such as reasons and object names.
+	termPattern = regexp.MustCompile(`[A-Za-z][A-Za-z0-9-]{4,}`)
+)
+
+// ScoreExplanation estimates how confident an AI explanation of a result is,
+// from 0 to 1, and whether it provides a concrete remediation, without
+// asking the AI provider again. The explanation is result.Details.
+//
+// The score grows with the share of the distinctive words of the failures
+// the explanation repeats, showing it addresses them, and with a
+// remediation. Hedging and the warnings of the explanation validators, which
+// flag likely hallucinations, lower it.
+func ScoreExplanation(result common.Result) (float64, bool) {
+	explanation := strings.TrimSpace(result.Details)
+	if explanation == "" {
+		return 0, false
+	}
+	hasRemediation := solutionPattern.MatchString(explanation) || remediationStepPattern.MatchString(explanation)
+
+	terms := map[string]bool{}
+	for _, failure := range result.Error {
+		for _, term := range termPattern.FindAllString(failure.Text, -1) {
+			terms[strings.ToLower(term)] = true
+		}
+	}
+	coverage := 1.0
+	if len(terms) > 0 {
+		lower := strings.ToLower(explanation)
+		matched := 0
+		for term := range terms {
+			if strings.Contains(lower, term) {
+				matched++
+			}
+		}
+		// Explanations simplify the failures, so a third of their words
+		// already shows they are addressed.
+		coverage = math.Min(1, float64(matched)/float64(len(terms))*3)
+	}
+
+	confidence := 0.2 + 0.5*coverage
+	if hasRemediation {
+		confidence += 0.3
+	}
+	if hedgingPattern.MatchString(explanation) {
+		confidence -= 0.2
+	}
+	confidence -= 0.2 * float64(len(result.Warnings))
+	confidence = math.Max(0, math.Min(1, confidence))
+	return math.Round(confidence*100) / 100, hasRemediation
+}
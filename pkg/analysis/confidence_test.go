@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/cache"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScoreExplanation(t *testing.T) {
+	failures := []common.Failure{{Text: "Back-off pulling image nginx:lates: manifest unknown"}}
+
+	tests := []struct {
+		name           string
+		explanation    string
+		warnings       []string
+		confidence     float64
+		hasRemediation bool
+	}{
+		{
+			name: "addressed with a solution",
+			explanation: "Error: The image nginx:lates can't be pulled as its manifest is unknown, the tag is likely a typo.\n" +
+				"Solution: 1. Fix the tag to nginx:latest. 2. Redeploy the pod.",
+			confidence:     1,
+			hasRemediation: true,
+		},
+		{
+			name:           "kubectl command",
+			explanation:    "The tag does not exist. Run kubectl set image deployment/web web=nginx:1.27",
+			confidence:     0.93,
+			hasRemediation: true,
+		},
+		{
+			name:        "unrelated and hedging",
+			explanation: "Error: It is unclear what happened to the container.",
+			confidence:  0,
+		},
+		{
+			name:        "addressed without a solution",
+			explanation: "The image nginx:lates can't be pulled: the manifest is unknown.",
+			confidence:  0.7,
+		},
+		{
+			name: "validation warnings",
+			explanation: "Error: The image nginx:lates can't be pulled as its manifest is unknown.\n" +
+				"Solution: Fix the tag in namespace prod.",
+			warnings:       []string{"the explanation mentions namespace prod, which does not exist in the cluster"},
+			confidence:     0.8,
+			hasRemediation: true,
+		},
+		{
+			name: "no explanation",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confidence, hasRemediation := ScoreExplanation(common.Result{
+				Kind:     "Pod",
+				Name:     "default/web",
+				Error:    failures,
+				Details:  tt.explanation,
+				Warnings: tt.warnings,
+			})
+			require.Equal(t, tt.confidence, confidence)
+			require.Equal(t, tt.hasRemediation, hasRemediation)
+		})
+	}
+}
+
+func TestGetAIResultsScoresExplanations(t *testing.T) {
+	disabledCache := cache.New("disabled-cache")
+	disabledCache.DisableCache()
+	a := Analysis{
+		Context:  context.Background(),
+		AIClient: &ai.NoOpAIClient{},
+		Cache:    disabledCache,
+		Language: "English",
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/web", Error: []common.Failure{{Text: "Back-off pulling image nginx:lates"}}},
+		},
+	}
+
+	require.NoError(t, a.GetAIResults("json", false))
+	require.Zero(t, a.Results[0].Confidence)
+
+	a.ScoreExplanations = true
+	require.NoError(t, a.GetAIResults("json", false))
+	// The noop provider echoes the prompt, which holds the failures and no solution.
+	require.Equal(t, 0.7, a.Results[0].Confidence)
+	require.False(t, a.Results[0].HasRemediation)
+}
+
+func TestTextOutputLowConfidence(t *testing.T) {
+	color.NoColor = true
+	result := common.Result{
+		Kind:       "Pod",
+		Name:       "default/web",
+		Error:      []common.Failure{{Text: "Back-off pulling image nginx:lates"}},
+		Details:    "Error: It is unclear what happened.",
+		Confidence: 0.1,
+	}
+
+	a := Analysis{Results: []common.Result{result}}
+	output, err := a.PrintOutput("text")
+	require.NoError(t, err)
+	require.NotContains(t, string(output), "Low confidence")
+
+	a.ScoreExplanations = true
+	output, err = a.PrintOutput("text")
+	require.NoError(t, err)
+	require.Contains(t, string(output), "Low confidence: 0.10, the explanation may not address the failures and gives no concrete remediation\n")
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"gopkg.in/yaml.v2"
+)
+
+// Config describes an analysis in a YAML or JSON file, so it can be kept in
+// version control and reproduced, see LoadConfig. Unset fields keep the
+// values of the k8sgpt configuration.
+type Config struct {
+	// Analyzers are the analyzers to run, like --filter.
+	Analyzers         []string        `yaml:"analyzers"`
+	IncludeNamespaces []string        `yaml:"includeNamespaces"`
+	ExcludeNamespaces []string        `yaml:"excludeNamespaces"`
+	ExcludeContainers []string        `yaml:"excludeContainers"`
+	MinSeverity       common.Severity `yaml:"minSeverity"`
+	ResourceQuota     struct {
+		Threshold float64 `yaml:"threshold"`
+	} `yaml:"resourceQuota"`
+	AI struct {
+		Language       string        `yaml:"language"`
+		Retries        *int          `yaml:"retries"`
+		RetryDelay     time.Duration `yaml:"retryDelay"`
+		PromptTemplate string        `yaml:"promptTemplate"`
+	} `yaml:"ai"`
+}
+
+// LoadConfig reads and validates the analysis configuration file at path.
+// Unknown keys are rejected, so typos don't silently change the analysis.
+func LoadConfig(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading analysis configuration: %w", err)
+	}
+	var config Config
+	if err := yaml.UnmarshalStrict(content, &config); err != nil {
+		return nil, fmt.Errorf("parsing analysis configuration %s: %w", path, err)
+	}
+
+	switch config.MinSeverity {
+	case "", common.SeverityLow, common.SeverityMedium, common.SeverityHigh:
+	default:
+		return nil, fmt.Errorf("invalid minSeverity %s in %s, must be one of low, medium, high", config.MinSeverity, path)
+	}
+	if config.ResourceQuota.Threshold < 0 || config.ResourceQuota.Threshold > 1 {
+		return nil, fmt.Errorf("invalid resourceQuota.threshold %g in %s, must be between 0 and 1", config.ResourceQuota.Threshold, path)
+	}
+	if config.AI.Retries != nil && *config.AI.Retries < 0 {
+		return nil, fmt.Errorf("invalid ai.retries %d in %s, must not be negative", *config.AI.Retries, path)
+	}
+	return &config, nil
+}
+
+// ApplyConfig sets the fields of the analysis from the configuration. The
+// analyzers only apply when no filter was given, as flags win over files.
+func (a *Analysis) ApplyConfig(config *Config) error {
+	if len(a.Filters) == 0 {
+		a.Filters = config.Analyzers
+	}
+	if len(config.IncludeNamespaces) > 0 {
+		a.IncludeNamespaces = config.IncludeNamespaces
+	}
+	if len(config.ExcludeNamespaces) > 0 {
+		a.ExcludeNamespaces = config.ExcludeNamespaces
+	}
+	if len(config.ExcludeContainers) > 0 {
+		a.ExcludeContainers = config.ExcludeContainers
+	}
+	if config.MinSeverity != "" {
+		a.MinSeverity = config.MinSeverity
+	}
+	if config.ResourceQuota.Threshold > 0 {
+		a.ResourceQuotaThreshold = config.ResourceQuota.Threshold
+	}
+	if config.AI.Language != "" {
+		a.Language = config.AI.Language
+	}
+	if config.AI.Retries != nil {
+		a.AIRetries = *config.AI.Retries
+	}
+	if config.AI.RetryDelay > 0 {
+		a.AIRetryDelay = config.AI.RetryDelay
+	}
+	if config.AI.PromptTemplate != "" {
+		tmpl, err := LoadPromptTemplate(config.AI.PromptTemplate)
+		if err != nil {
+			return err
+		}
+		a.PromptTemplate = tmpl
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string
+		content     string
+		expectedErr string
+	}{
+		{
+			name: "yaml",
+			file: "analysis.yaml",
+			content: `analyzers: [Pod, ResourceQuota]
+excludeNamespaces: [kube-system]
+minSeverity: medium
+resourceQuota:
+  threshold: 0.8
+ai:
+  language: french
+  retries: 0
+  retryDelay: 2s
+`,
+		},
+		{
+			name:    "json",
+			file:    "analysis.json",
+			content: `{"analyzers": ["Pod", "ResourceQuota"], "excludeNamespaces": ["kube-system"], "minSeverity": "medium", "resourceQuota": {"threshold": 0.8}, "ai": {"language": "french", "retries": 0, "retryDelay": "2s"}}`,
+		},
+		{
+			name:        "unknown key",
+			file:        "analysis.yaml",
+			content:     "analyser: [Pod]\n",
+			expectedErr: "field analyser not found",
+		},
+		{
+			name:        "invalid severity",
+			file:        "analysis.yaml",
+			content:     "minSeverity: critical\n",
+			expectedErr: "invalid minSeverity critical",
+		},
+		{
+			name:        "invalid threshold",
+			file:        "analysis.yaml",
+			content:     "resourceQuota:\n  threshold: 90\n",
+			expectedErr: "invalid resourceQuota.threshold 90",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := LoadConfig(writeConfig(t, tt.file, tt.content))
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			a := &Analysis{Language: "english", AIRetries: defaultAIRetries, AIRetryDelay: defaultAIRetryDelay}
+			require.NoError(t, a.ApplyConfig(config))
+			require.Equal(t, []string{"Pod", "ResourceQuota"}, a.Filters)
+			require.Equal(t, []string{"kube-system"}, a.ExcludeNamespaces)
+			require.Equal(t, common.SeverityMedium, a.MinSeverity)
+			require.Equal(t, 0.8, a.ResourceQuotaThreshold)
+			require.Equal(t, "french", a.Language)
+			require.Equal(t, 0, a.AIRetries)
+			require.Equal(t, 2*time.Second, a.AIRetryDelay)
+		})
+	}
+}
+
+func TestApplyConfigKeepsFilters(t *testing.T) {
+	a := &Analysis{Filters: []string{"Service"}, Language: "english"}
+	require.NoError(t, a.ApplyConfig(&Config{Analyzers: []string{"Pod"}}))
+	require.Equal(t, []string{"Service"}, a.Filters)
+	require.Equal(t, "english", a.Language)
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors callers can match with errors.Is, e.g. to map them to status codes.
+// The errors returned wrap them and keep their own messages.
+var (
+	// ErrAIProviderNotInitialized is returned when explaining without an AI
+	// provider configured.
+	ErrAIProviderNotInitialized = errors.New("AI provider not initialized")
+	// ErrAIEmptyResponse is returned when the AI provider answers with
+	// nothing but whitespace.
+	ErrAIEmptyResponse = errors.New("empty response from AI provider")
+	// ErrAIQuotaExhausted is returned by GetAIResults when the AI provider
+	// rejects requests for exceeding the quota.
+	ErrAIQuotaExhausted = errors.New("exhausted API quota")
+	// ErrAIRefusal is returned when the AI provider declines to explain, e.g.
+	// "I'm sorry, but I can't help with that".
+	ErrAIRefusal = errors.New("refusal from AI provider")
+	// ErrAIRequestFailed is returned by GetAIResults when the AI provider
+	// fails to explain a result.
+	ErrAIRequestFailed = errors.New("failed while calling AI provider")
+	// ErrAnalyzerFailed is matched by the AnalyzerError of every analyzer
+	// that failed.
+	ErrAnalyzerFailed = errors.New("analyzer failed")
+	// ErrAnalyzerTimedOut is wrapped by the errors of analyzers abandoned
+	// after AnalyzerTimeout.
+	ErrAnalyzerTimedOut = errors.New("timed out")
+	// ErrInvalidLabelSelector is returned by NewAnalysis for label selectors
+	// that don't parse.
+	ErrInvalidLabelSelector = errors.New("invalid label selector")
+	// ErrUnsupportedOutputFormat is returned for output formats that don't
+	// exist or can't be streamed.
+	ErrUnsupportedOutputFormat = errors.New("unsupported output format")
+)
+
+// AnalyzerError is the error of an analyzer, built-in or custom, that
+// failed. Its message is the one recorded in Errors.
+type AnalyzerError struct {
+	// Analyzer is the name of the analyzer.
+	Analyzer string
+	Err      error
+}
+
+func (e *AnalyzerError) Error() string {
+	return fmt.Sprintf("[%s] %s", e.Analyzer, e.Err)
+}
+
+func (e *AnalyzerError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes every AnalyzerError match ErrAnalyzerFailed.
+func (e *AnalyzerError) Is(target error) bool {
+	return target == ErrAnalyzerFailed
+}
+
+// sentinelError matches sentinel with errors.Is while keeping its own
+// message, for messages the sentinel can't be worked into.
+type sentinelError struct {
+	message  string
+	sentinel error
+}
+
+func (e *sentinelError) Error() string {
+	return e.message
+}
+
+func (e *sentinelError) Is(target error) bool {
+	return target == e.sentinel
+}
+
+// addAnalyzerError records the error of an analyzer in Errors and
+// AnalyzerErrors. The caller holds the lock guarding them.
+func (a *Analysis) addAnalyzerError(analyzer string, err error) {
+	analyzerErr := &AnalyzerError{Analyzer: analyzer, Err: err}
+	a.Errors = append(a.Errors, analyzerErr.Error())
+	a.AnalyzerErrors = append(a.AnalyzerErrors, analyzerErr)
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/cache"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+type failingAnalyzer struct {
+	err error
+}
+
+func (f failingAnalyzer) Analyze(common.Analyzer) ([]common.Result, error) {
+	return nil, f.err
+}
+
+func TestAnalyzerErrors(t *testing.T) {
+	forbidden := errors.New("pods is forbidden")
+	a := &Analysis{
+		Context:         context.Background(),
+		AnalyzerTimeout: 50 * time.Millisecond,
+	}
+	semaphore := make(chan struct{}, 2)
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	for name, analyzer := range map[string]common.IAnalyzer{
+		"Pod":     failingAnalyzer{err: forbidden},
+		"Hanging": hangingAnalyzer{},
+	} {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go a.executeAnalyzer(analyzer, name, common.Analyzer{Context: a.Context}, semaphore, &wg, &mutex)
+	}
+	wg.Wait()
+
+	require.ElementsMatch(t, []string{
+		"[Pod] pods is forbidden",
+		"[Hanging] timed out after 50ms, its results are missing",
+	}, a.Errors)
+	require.Len(t, a.AnalyzerErrors, 2)
+	for _, err := range a.AnalyzerErrors {
+		require.ErrorIs(t, err, ErrAnalyzerFailed)
+		require.Contains(t, a.Errors, err.Error())
+
+		var analyzerErr *AnalyzerError
+		require.ErrorAs(t, fmt.Errorf("analysis: %w", err), &analyzerErr)
+		switch analyzerErr.Analyzer {
+		case "Pod":
+			require.ErrorIs(t, err, forbidden)
+			require.NotErrorIs(t, err, ErrAnalyzerTimedOut)
+		case "Hanging":
+			require.ErrorIs(t, err, ErrAnalyzerTimedOut)
+		default:
+			t.Fatalf("unexpected analyzer %s", analyzerErr.Analyzer)
+		}
+	}
+}
+
+func TestExplanationErrors(t *testing.T) {
+	disabledCache := cache.New("disabled-cache")
+	disabledCache.DisableCache()
+	failures := []common.Failure{{Text: "Service default/frontend has no endpoints"}}
+
+	a := &Analysis{Cache: disabledCache}
+	_, err := a.GetExplanation(context.Background(), "Service", failures, false)
+	require.ErrorIs(t, err, ErrAIProviderNotInitialized)
+	require.EqualError(t, err, "AI provider not initialized")
+
+	quota := errors.New("error, status code: 429, message: quota exceeded")
+	a = &Analysis{
+		Context:  context.Background(),
+		AIClient: &flakyAIClient{errs: []error{quota}},
+		Cache:    disabledCache,
+		Results:  []common.Result{{Kind: "Service", Name: "default/frontend", Error: failures}},
+	}
+	err = a.GetAIResults("json", false)
+	require.ErrorIs(t, err, ErrAIQuotaExhausted)
+	require.ErrorIs(t, err, quota)
+	require.EqualError(t, err, "exhausted API quota for AI provider noopai: error, status code: 429, message: quota exceeded")
+
+	invalid := errors.New("invalid API key")
+	a.AIClient = &flakyAIClient{errs: []error{invalid}}
+	err = a.GetAIResults("json", false)
+	require.ErrorIs(t, err, ErrAIRequestFailed)
+	require.NotErrorIs(t, err, ErrAIQuotaExhausted)
+	require.ErrorIs(t, err, invalid)
+	require.EqualError(t, err, "failed while calling AI provider noopai: invalid API key")
+}
+
+// cannedAIClient answers every prompt with response.
+type cannedAIClient struct {
+	ai.NoOpAIClient
+	response string
+}
+
+func (c *cannedAIClient) GetCompletion(context.Context, string) (string, error) {
+	return c.response, nil
+}
+
+func TestUnusableExplanations(t *testing.T) {
+	failures := []common.Failure{{Text: "Service default/frontend has no endpoints"}}
+
+	tests := []struct {
+		name        string
+		response    string
+		expectedErr error
+		message     string
+	}{
+		{
+			name:        "empty",
+			response:    "",
+			expectedErr: ErrAIEmptyResponse,
+			message:     "empty response from AI provider noopai",
+		},
+		{
+			name:        "whitespace",
+			response:    " \n\t\n",
+			expectedErr: ErrAIEmptyResponse,
+			message:     "empty response from AI provider noopai",
+		},
+		{
+			name:        "refusal",
+			response:    "I'm sorry, but I can't help with that.\nPlease ask something else.",
+			expectedErr: ErrAIRefusal,
+			message:     `refusal from AI provider noopai: "I'm sorry, but I can't help with that."`,
+		},
+		{
+			name:        "refusal as an AI",
+			response:    "As an AI language model, I cannot access your cluster.",
+			expectedErr: ErrAIRefusal,
+			message:     `refusal from AI provider noopai: "As an AI language model, I cannot access your cluster."`,
+		},
+		{
+			name:     "explanation mentioning what can't be done",
+			response: "Error: The Service can't route traffic as no pod matches its selector.\nSolution: Fix the selector.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &mapCache{items: map[string]string{}}
+			a := &Analysis{
+				Context:  context.Background(),
+				AIClient: &cannedAIClient{response: tt.response},
+				Cache:    c,
+			}
+
+			explanation, err := a.GetExplanation(context.Background(), "Service", failures, false)
+			if tt.expectedErr == nil {
+				require.NoError(t, err)
+				require.Equal(t, tt.response, explanation)
+				return
+			}
+			require.ErrorIs(t, err, tt.expectedErr)
+			require.EqualError(t, err, tt.message)
+			require.Empty(t, explanation)
+			require.Empty(t, c.items)
+
+			// Unusable explanations aren't cached, and don't fail the others.
+			a.AIClient = &cannedAIClient{response: "Error: The Service has no endpoints."}
+			a.Results = []common.Result{
+				{Kind: "Service", Name: "default/frontend", Error: failures},
+			}
+			require.NoError(t, a.GetAIResults("json", false))
+			require.Equal(t, "Error: The Service has no endpoints.", a.Results[0].Details)
+
+			a.AIClient = &cannedAIClient{response: tt.response}
+			a.Results = []common.Result{
+				{Kind: "Service", Name: "default/backend", Error: []common.Failure{{Text: "Service default/backend has no endpoints"}}},
+				{Kind: "Service", Name: "default/frontend", Error: failures},
+			}
+			require.NoError(t, a.GetAIResults("json", false))
+			require.Empty(t, a.Results[0].Details)
+			require.Equal(t, "Error: The Service has no endpoints.", a.Results[1].Details)
+			require.Equal(t, []string{"[AI] Service default/backend: " + tt.message}, a.Errors)
+		})
+	}
+}
+
+func TestOutputFormatErrors(t *testing.T) {
+	a := &Analysis{}
+	_, err := a.PrintOutput("yaml")
+	require.ErrorIs(t, err, ErrUnsupportedOutputFormat)
+
+	err = a.StreamOutput("junit", nil)
+	require.ErrorIs(t, err, ErrUnsupportedOutputFormat)
+	require.ErrorContains(t, err, "output format junit can't be streamed. Available format")
+}
+
+func TestNewAnalysisInvalidLabelSelector(t *testing.T) {
+	_, err := NewAnalysis("", "english", nil, "", "app in (web", false, false, 1, false, false, nil, false)
+	require.ErrorIs(t, err, ErrInvalidLabelSelector)
+	require.ErrorContains(t, err, `invalid label selector "app in (web"`)
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+)
+
+// groupByParent collapses the results of objects with a ParentObject, such as
+// the pods of a Deployment, into one result for the parent, merged with the
+// parent's own result if there is one. Failures with the same text, like the
+// same image pull error of every pod, are only kept once.
+func groupByParent(results []common.Result) []common.Result {
+	var grouped []common.Result
+	index := map[string]int{}
+	for _, result := range results {
+		index[result.Kind+"/"+result.Name] = len(grouped)
+		grouped = append(grouped, result)
+	}
+
+	collapsed := map[int]bool{}
+	for i, result := range results {
+		parentKind, parentName, found := strings.Cut(result.ParentObject, "/")
+		if !found {
+			continue
+		}
+		if namespace, _, namespaced := strings.Cut(result.Name, "/"); namespaced {
+			parentName = namespace + "/" + parentName
+		}
+		key := parentKind + "/" + parentName
+		if key == result.Kind+"/"+result.Name {
+			continue
+		}
+		collapsed[i] = true
+
+		parent, ok := index[key]
+		if !ok {
+			parent = len(grouped)
+			index[key] = parent
+			grouped = append(grouped, common.Result{Kind: parentKind, Name: parentName})
+		}
+		grouped[parent].Error = mergeFailures(grouped[parent].Error, result.Error)
+	}
+
+	var kept []common.Result
+	for i, result := range grouped {
+		if !collapsed[i] {
+			kept = append(kept, result)
+		}
+	}
+	return kept
+}
+
+// mergeFailures appends the failures whose text isn't in failures yet,
+// without writing to the array of failures.
+func mergeFailures(failures []common.Failure, others []common.Failure) []common.Failure {
+	failures = failures[:len(failures):len(failures)]
+	texts := map[string]bool{}
+	for _, failure := range failures {
+		texts[failure.Text] = true
+	}
+	for _, failure := range others {
+		if !texts[failure.Text] {
+			texts[failure.Text] = true
+			failures = append(failures, failure)
+		}
+	}
+	return failures
+}
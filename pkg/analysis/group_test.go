@@ -0,0 +1,46 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupByParent(t *testing.T) {
+	pull := common.Failure{Text: `Back-off pulling image "nginx:lates"`}
+	results := []common.Result{
+		{Kind: "Pod", Name: "default/web-1", ParentObject: "Deployment/web", Error: []common.Failure{pull}},
+		{Kind: "Pod", Name: "default/web-2", ParentObject: "Deployment/web", Error: []common.Failure{pull, {Text: "readiness probe failed"}}},
+		{Kind: "Deployment", Name: "default/web", Error: []common.Failure{{Text: "Deployment default/web has 1 replicas but 0 are available"}}},
+		{Kind: "Pod", Name: "jobs/backup-1", ParentObject: "CronJob/backup", Error: []common.Failure{{Text: "the last termination reason is Error"}}},
+		{Kind: "Pod", Name: "default/standalone", Error: []common.Failure{{Text: "Pod standalone is pending"}}},
+	}
+
+	grouped := groupByParent(results)
+
+	require.Equal(t, []common.Result{
+		{Kind: "Deployment", Name: "default/web", Error: []common.Failure{
+			{Text: "Deployment default/web has 1 replicas but 0 are available"},
+			pull,
+			{Text: "readiness probe failed"},
+		}},
+		{Kind: "Pod", Name: "default/standalone", Error: []common.Failure{{Text: "Pod standalone is pending"}}},
+		{Kind: "CronJob", Name: "jobs/backup", Error: []common.Failure{{Text: "the last termination reason is Error"}}},
+	}, grouped)
+	// The input results are left as they were.
+	require.Len(t, results[2].Error, 1)
+}
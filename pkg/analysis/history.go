@@ -0,0 +1,209 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+)
+
+// historyRuns is the number of runs kept per analysis scope.
+const historyRuns = 20
+
+// Run is an analysis stored in the history.
+type Run struct {
+	Time    time.Time       `json:"time"`
+	Results []common.Result `json:"results"`
+}
+
+// RunComparison holds the findings of a run compared with a previous one.
+// Each result only holds the failures in its category.
+type RunComparison struct {
+	Added     []common.Result
+	Resolved  []common.Result
+	Unchanged []common.Result
+}
+
+// CompareRuns compares the findings of two runs. A finding is a failure of an
+// object, identified by the kind and name of the result and the hash of the
+// failure text.
+func CompareRuns(previous []common.Result, current []common.Result) RunComparison {
+	previousKeys := findingKeys(previous)
+	currentKeys := findingKeys(current)
+
+	var comparison RunComparison
+	comparison.Added, comparison.Unchanged = splitFindings(current, previousKeys)
+	comparison.Resolved, _ = splitFindings(previous, currentKeys)
+	return comparison
+}
+
+// findingKey identifies a failure of a result across runs.
+func findingKey(result common.Result, failure common.Failure) string {
+	hash := sha256.Sum256([]byte(failure.Text))
+	return fmt.Sprintf("%s/%s/%s", result.Kind, result.Name, hex.EncodeToString(hash[:]))
+}
+
+func findingKeys(results []common.Result) map[string]bool {
+	keys := map[string]bool{}
+	for _, result := range results {
+		for _, failure := range result.Error {
+			keys[findingKey(result, failure)] = true
+		}
+	}
+	return keys
+}
+
+// splitFindings splits the failures of the results into those whose key is
+// not in keys and those whose key is. Results left without failures are
+// dropped.
+func splitFindings(results []common.Result, keys map[string]bool) ([]common.Result, []common.Result) {
+	var missing, found []common.Result
+	for _, result := range results {
+		var missingFailures, foundFailures []common.Failure
+		for _, failure := range result.Error {
+			if keys[findingKey(result, failure)] {
+				foundFailures = append(foundFailures, failure)
+			} else {
+				missingFailures = append(missingFailures, failure)
+			}
+		}
+		if len(missingFailures) > 0 {
+			r := result
+			r.Error = missingFailures
+			missing = append(missing, r)
+		}
+		if len(foundFailures) > 0 {
+			r := result
+			r.Error = foundFailures
+			found = append(found, r)
+		}
+	}
+	return missing, found
+}
+
+// HistoryStore keeps the latest runs of an analysis as JSON files in a
+// directory.
+type HistoryStore struct {
+	dir string
+}
+
+// NewHistoryStore returns the store of the runs of the analyses of the same
+// cluster, namespace, filters and selectors, kept in the k8sgpt state
+// directory.
+func NewHistoryStore(a *Analysis) *HistoryStore {
+	filters := append([]string{}, a.Filters...)
+	sort.Strings(filters)
+	var host string
+	if a.Client != nil && a.Client.Config != nil {
+		host = a.Client.Config.Host
+	}
+	scope := strings.Join([]string{host, a.Namespace, a.LabelSelector, a.FieldSelector, strings.Join(filters, ",")}, "\n")
+	hash := sha256.Sum256([]byte(scope))
+	return &HistoryStore{dir: filepath.Join(xdg.StateHome, "k8sgpt", "history", hex.EncodeToString(hash[:8]))}
+}
+
+// Last returns the latest run stored, or nil when there is none.
+func (s *HistoryStore) Last() (*Run, error) {
+	names, err := s.runFiles()
+	if err != nil || len(names) == 0 {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(s.dir, names[len(names)-1]))
+	if err != nil {
+		return nil, fmt.Errorf("error reading analysis history: %w", err)
+	}
+	var run Run
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("error reading analysis history: %w", err)
+	}
+	return &run, nil
+}
+
+// Save stores a run, dropping the oldest runs beyond the ones kept.
+func (s *HistoryStore) Save(run Run) error {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return fmt.Errorf("error creating analysis history: %w", err)
+	}
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("error marshalling json: %v", err)
+	}
+	// Names sort in the order of the runs.
+	name := fmt.Sprintf("%020d.json", run.Time.UnixNano())
+	if err := writeFileAtomic(filepath.Join(s.dir, name), data); err != nil {
+		return err
+	}
+
+	names, err := s.runFiles()
+	if err != nil {
+		return err
+	}
+	for len(names) > historyRuns {
+		if err := os.Remove(filepath.Join(s.dir, names[0])); err != nil {
+			return fmt.Errorf("error pruning analysis history: %w", err)
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+// runFiles returns the sorted names of the stored runs.
+func (s *HistoryStore) runFiles() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading analysis history: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".json" && !strings.HasPrefix(entry.Name(), ".") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// SinceLast stores the results of the analysis in the history and keeps only
+// the findings added since the previous run, recording the resolved ones in
+// Resolved. Without a previous run, every finding is new.
+func (a *Analysis) SinceLast(store *HistoryStore) error {
+	previous, err := store.Last()
+	if err != nil {
+		return err
+	}
+	if err := store.Save(Run{Time: time.Now(), Results: a.Results}); err != nil {
+		return err
+	}
+	if previous == nil {
+		return nil
+	}
+	comparison := CompareRuns(previous.Results, a.Results)
+	a.Results = comparison.Added
+	a.Resolved = comparison.Resolved
+	a.PreviousRun = previous.Time
+	return nil
+}
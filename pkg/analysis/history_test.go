@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func historyResult(kind string, name string, texts ...string) common.Result {
+	result := common.Result{Kind: kind, Name: name}
+	for _, text := range texts {
+		result.Error = append(result.Error, common.Failure{Text: text})
+	}
+	return result
+}
+
+func TestCompareRuns(t *testing.T) {
+	previous := []common.Result{
+		historyResult("Pod", "default/web", "back-off restarting failed container", "readiness probe failed"),
+		historyResult("Service", "default/web", "no endpoints"),
+	}
+	current := []common.Result{
+		historyResult("Pod", "default/web", "back-off restarting failed container"),
+		historyResult("Pod", "default/api", "readiness probe failed"),
+	}
+
+	comparison := CompareRuns(previous, current)
+	require.Equal(t, []common.Result{
+		historyResult("Pod", "default/api", "readiness probe failed"),
+	}, comparison.Added)
+	require.Equal(t, []common.Result{
+		historyResult("Pod", "default/web", "readiness probe failed"),
+		historyResult("Service", "default/web", "no endpoints"),
+	}, comparison.Resolved)
+	require.Equal(t, []common.Result{
+		historyResult("Pod", "default/web", "back-off restarting failed container"),
+	}, comparison.Unchanged)
+}
+
+func TestHistoryStore(t *testing.T) {
+	store := &HistoryStore{dir: t.TempDir() + "/runs"}
+
+	last, err := store.Last()
+	require.NoError(t, err)
+	require.Nil(t, last)
+
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < historyRuns+2; i++ {
+		require.NoError(t, store.Save(Run{
+			Time:    start.Add(time.Duration(i) * time.Minute),
+			Results: []common.Result{historyResult("Pod", "default/web", "run "+string(rune('a'+i)))},
+		}))
+	}
+
+	last, err = store.Last()
+	require.NoError(t, err)
+	require.True(t, start.Add(time.Duration(historyRuns+1)*time.Minute).Equal(last.Time))
+	require.Equal(t, "run "+string(rune('a'+historyRuns+1)), last.Results[0].Error[0].Text)
+
+	entries, err := os.ReadDir(store.dir)
+	require.NoError(t, err)
+	require.Len(t, entries, historyRuns)
+}
+
+func TestSinceLast(t *testing.T) {
+	color.NoColor = true
+	store := &HistoryStore{dir: t.TempDir()}
+
+	// Without a previous run, every finding is new.
+	first := &Analysis{Results: []common.Result{
+		historyResult("Pod", "default/web", "back-off restarting failed container"),
+		historyResult("Service", "default/web", "no endpoints"),
+	}}
+	require.NoError(t, first.SinceLast(store))
+	require.Len(t, first.Results, 2)
+	require.Empty(t, first.Resolved)
+
+	second := &Analysis{Results: []common.Result{
+		historyResult("Pod", "default/web", "back-off restarting failed container"),
+		historyResult("Pod", "default/api", "readiness probe failed"),
+	}}
+	require.NoError(t, second.SinceLast(store))
+	require.Equal(t, []common.Result{historyResult("Pod", "default/api", "readiness probe failed")}, second.Results)
+	require.Equal(t, []common.Result{historyResult("Service", "default/web", "no endpoints")}, second.Resolved)
+
+	output, err := second.textOutput()
+	require.NoError(t, err)
+	previousRun := second.PreviousRun.Format(time.RFC3339)
+	require.Contains(t, string(output), "Resolved since the last run ("+previousRun+"): \n- Service default/web: no endpoints\n")
+	require.Contains(t, string(output), "New since the last run ("+previousRun+"): \n0: Pod default/api()\n- Error: readiness probe failed\n")
+
+	// The comparison is made with the whole previous run.
+	third := &Analysis{Results: second.Results}
+	require.NoError(t, third.SinceLast(store))
+	require.Empty(t, third.Results)
+	require.Equal(t, []common.Result{historyResult("Pod", "default/web", "back-off restarting failed container")}, third.Resolved)
+
+	output, err = third.textOutput()
+	require.NoError(t, err)
+	require.Contains(t, string(output), "No new problems detected since the last run")
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+	SystemOut string          `xml:"system-out,omitempty"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitOutput renders the analysis as a JUnit XML report so CI systems can
+// display the findings. Each analyzed kind is a test suite and each finding a
+// failed test case. Namespaces an analyzer found nothing in get a passing
+// case, and analyzers that could not run are reported as errors in the k8sgpt
+// suite, whose output lists the other errors of the analysis.
+func (a *Analysis) junitOutput() ([]byte, error) {
+	namespaces := a.junitNamespaces()
+
+	suites := map[string]*junitTestSuite{}
+	suite := func(name string) *junitTestSuite {
+		if s, ok := suites[name]; ok {
+			return s
+		}
+		s := &junitTestSuite{Name: name}
+		suites[name] = s
+		return s
+	}
+
+	for _, result := range a.Results {
+		s := suite(result.Kind)
+		var texts []string
+		for _, failure := range result.Error {
+			if failure.Severity != "" {
+				texts = append(texts, fmt.Sprintf("[%s] %s", failure.Severity, failure.Text))
+			} else {
+				texts = append(texts, failure.Text)
+			}
+		}
+		message := ""
+		if len(texts) > 0 {
+			message = texts[0]
+		}
+		s.TestCases = append(s.TestCases, junitTestCase{
+			Name:      result.Name,
+			ClassName: result.Kind,
+			Failure: &junitMessage{
+				Message: message,
+				Type:    result.Kind,
+				Text:    strings.Join(texts, "\n"),
+			},
+			SystemOut: result.Details,
+		})
+		s.Failures++
+	}
+
+	// Kinds whose results are all namespaced get a passing case for each
+	// namespace without findings; cluster-scoped kinds don't.
+	for kind, s := range suites {
+		failing := map[string]bool{}
+		for _, testCase := range s.TestCases {
+			namespace, _, found := strings.Cut(testCase.Name, "/")
+			if !found {
+				failing = nil
+				break
+			}
+			failing[namespace] = true
+		}
+		if failing == nil {
+			continue
+		}
+		for _, namespace := range namespaces {
+			if !failing[namespace] {
+				s.TestCases = append(s.TestCases, junitTestCase{Name: namespace, ClassName: kind})
+			}
+		}
+	}
+
+	for _, analyzer := range a.analyzersWithoutFindings {
+		s := suite(analyzer)
+		if len(namespaces) == 0 {
+			s.TestCases = append(s.TestCases, junitTestCase{Name: "all namespaces", ClassName: analyzer})
+		}
+		for _, namespace := range namespaces {
+			s.TestCases = append(s.TestCases, junitTestCase{Name: namespace, ClassName: analyzer})
+		}
+	}
+
+	analyzerErrors := map[string]bool{}
+	for _, analyzerErr := range a.AnalyzerErrors {
+		analyzerErrors[analyzerErr.Error()] = true
+		s := suite("k8sgpt")
+		s.TestCases = append(s.TestCases, junitTestCase{
+			Name:      analyzerErr.Error(),
+			ClassName: "k8sgpt",
+			Error: &junitMessage{
+				Message: analyzerErr.Error(),
+				Type:    "AnalysisError",
+			},
+		})
+		s.Errors++
+	}
+	var otherErrors []string
+	for _, analysisError := range a.Errors {
+		if !analyzerErrors[analysisError] {
+			otherErrors = append(otherErrors, analysisError)
+		}
+	}
+	if len(otherErrors) > 0 {
+		suite("k8sgpt").SystemOut = strings.Join(otherErrors, "\n")
+	}
+
+	names := make([]string, 0, len(suites))
+	for name := range suites {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	report := junitTestSuites{Name: "k8sgpt"}
+	for _, name := range names {
+		s := suites[name]
+		s.Tests = len(s.TestCases)
+		report.Tests += s.Tests
+		report.Failures += s.Failures
+		report.Errors += s.Errors
+		report.Suites = append(report.Suites, *s)
+	}
+
+	output, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling junit: %v", err)
+	}
+	return append([]byte(xml.Header), output...), nil
+}
+
+// junitNamespaces returns the analyzed namespaces, which are listed when the
+// analysis covers all of them. It returns nothing when they can't be listed.
+func (a *Analysis) junitNamespaces() []string {
+	if a.Namespace != "" {
+		return []string{a.Namespace}
+	}
+	if a.Client == nil {
+		return nil
+	}
+	list, err := a.Client.GetClient().CoreV1().Namespaces().List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil
+	}
+	var namespaces []string
+	for _, namespace := range list.Items {
+		if a.namespaceAllowed(common.Result{Name: namespace.Name + "/"}) {
+			namespaces = append(namespaces, namespace.Name)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const defaultLogLines = 50
+
+// containerLogs returns the last LogLines lines logged by the previous
+// instance of the failing container of a Pod result, when IncludeLogs is
+// set. The container is the one named in the failures, or else the first
+// that restarted. Nothing is returned when the logs can't be read, e.g. as
+// the container never restarted or they were rotated away.
+func (a *Analysis) containerLogs(ctx context.Context, result common.Result) string {
+	if !a.IncludeLogs || a.LogLines <= 0 || result.Kind != "Pod" || a.Client == nil {
+		return ""
+	}
+	namespace, name, ok := strings.Cut(result.Name, "/")
+	if !ok {
+		return ""
+	}
+
+	client := a.Client.GetClient().CoreV1().Pods(namespace)
+	container := failingContainer(result)
+	if container == "" {
+		pod, err := client.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return ""
+		}
+		container = restartedContainer(pod)
+		if container == "" {
+			return ""
+		}
+	}
+
+	lines := int64(a.LogLines)
+	if err := a.Client.Wait(ctx); err != nil {
+		return ""
+	}
+	raw, err := client.GetLogs(name, &v1.PodLogOptions{
+		Container: container,
+		Previous:  true,
+		TailLines: &lines,
+	}).DoRaw(ctx)
+	if err != nil {
+		return ""
+	}
+	logs := strings.Split(strings.TrimSpace(string(raw)), "\n")
+	if len(logs) > a.LogLines {
+		logs = logs[len(logs)-a.LogLines:]
+	}
+	return strings.TrimSpace(strings.Join(logs, "\n"))
+}
+
+// restartedContainer returns the first container of the pod that was
+// terminated before, or nothing when none was.
+func restartedContainer(pod *v1.Pod) string {
+	statuses := append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if status.LastTerminationState.Terminated != nil {
+			return status.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/cache"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestExplainIncludeLogs(t *testing.T) {
+	restarted := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "restarted", Namespace: "default"},
+		Status: v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				{Name: "sidecar"},
+				{
+					Name: "app",
+					LastTerminationState: v1.ContainerState{
+						Terminated: &v1.ContainerStateTerminated{Reason: "Error"},
+					},
+				},
+			},
+		},
+	}
+	pending := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "default"},
+		Status: v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{{Name: "app"}},
+		},
+	}
+	// The fake clientset answers every logs request with "fake logs".
+	const logsPrompt = "--- fake logs ---"
+
+	tests := []struct {
+		name        string
+		includeLogs bool
+		result      common.Result
+		expectLogs  bool
+	}{
+		{
+			name:        "container named in the failures",
+			includeLogs: true,
+			result: common.Result{
+				Kind:  "Pod",
+				Name:  "default/crashing",
+				Error: []common.Failure{{Text: "the last termination reason is Error container=app pod=crashing"}},
+			},
+			expectLogs: true,
+		},
+		{
+			name:        "restarted container",
+			includeLogs: true,
+			result: common.Result{
+				Kind:  "Pod",
+				Name:  "default/restarted",
+				Error: []common.Failure{{Text: "Readiness probe failed"}},
+			},
+			expectLogs: true,
+		},
+		{
+			name:        "no container restarted",
+			includeLogs: true,
+			result: common.Result{
+				Kind:  "Pod",
+				Name:  "default/pending",
+				Error: []common.Failure{{Text: "0/3 nodes are available"}},
+			},
+		},
+		{
+			name:        "pod gone",
+			includeLogs: true,
+			result: common.Result{
+				Kind:  "Pod",
+				Name:  "default/gone",
+				Error: []common.Failure{{Text: "Readiness probe failed"}},
+			},
+		},
+		{
+			name:        "not a pod",
+			includeLogs: true,
+			result: common.Result{
+				Kind:  "Deployment",
+				Name:  "default/web",
+				Error: []common.Failure{{Text: "container=app is not ready"}},
+			},
+		},
+		{
+			name: "logs not requested",
+			result: common.Result{
+				Kind:  "Pod",
+				Name:  "default/crashing",
+				Error: []common.Failure{{Text: "the last termination reason is Error container=app pod=crashing"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			disabledCache := cache.New("disabled-cache")
+			disabledCache.DisableCache()
+			a := &Analysis{
+				Client: &kubernetes.Client{
+					Client: fake.NewSimpleClientset(restarted, pending),
+				},
+				AIClient:    &ai.NoOpAIClient{},
+				Cache:       disabledCache,
+				Language:    "English",
+				IncludeLogs: tt.includeLogs,
+				LogLines:    defaultLogLines,
+			}
+
+			// The noop provider echoes the prompt it was sent.
+			output, err := a.explain(context.Background(), tt.result, false)
+			require.NoError(t, err)
+			require.Contains(t, output, tt.result.Error[0].Text)
+			if tt.expectLogs {
+				require.Contains(t, output, logsPrompt)
+			} else {
+				require.NotContains(t, output, logsPrompt)
+			}
+		})
+	}
+}
+
+func TestPromptTemplateLogs(t *testing.T) {
+	tmpl, err := LoadPromptTemplate(writePromptTemplate(t, "{{.Error}} logs: {{.Logs}}"))
+	require.NoError(t, err)
+	a := &Analysis{PromptTemplate: tmpl}
+	prompt, err := a.renderPrompt(PromptData{Kind: "Pod", Error: "crashed", Logs: "panic: boom"}, promptTemplateForKind("Pod"))
+	require.NoError(t, err)
+	require.Equal(t, "crashed logs: panic: boom", prompt)
+}
+
+func TestMaskSensitiveLogs(t *testing.T) {
+	failures := []common.Failure{
+		{
+			Text:      "the last termination reason is Error container=app pod=payments",
+			Sensitive: []common.Sensitive{{Unmasked: "payments", Masked: "cGF5bWVu"}},
+		},
+	}
+	logs := "connecting to payments-db\npayments failed to start"
+	require.Equal(t, logs, maskSensitive(logs, failures, nil))
+	require.Equal(t, "connecting to cGF5bWVu-db\ncGF5bWVu failed to start", maskSensitive(logs, failures, newMaskTable()))
+}
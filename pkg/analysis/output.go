@@ -1,16 +1,31 @@
 package analysis
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
 )
 
 var outputFormats = map[string]func(*Analysis) ([]byte, error){
-	"json": (*Analysis).jsonOutput,
-	"text": (*Analysis).textOutput,
+	"json":   (*Analysis).jsonOutput,
+	"text":   (*Analysis).textOutput,
+	"junit":  (*Analysis).junitOutput,
+	"ndjson": (*Analysis).ndjsonOutput,
+	"sarif":  (*Analysis).sarifOutput,
+}
+
+// streamFormats render a single result, numbered n, for StreamOutput.
+var streamFormats = map[string]func(a *Analysis, n int, result common.Result) []byte{
+	"text":   (*Analysis).textResult,
+	"ndjson": (*Analysis).ndjsonResult,
 }
 
 func getOutputFormats() []string {
@@ -24,11 +39,47 @@ func getOutputFormats() []string {
 func (a *Analysis) PrintOutput(format string) ([]byte, error) {
 	outputFunc, ok := outputFormats[format]
 	if !ok {
-		return nil, fmt.Errorf("unsupported output format: %s. Available format %s", format, strings.Join(getOutputFormats(), ","))
+		return nil, fmt.Errorf("%w: %s. Available format %s", ErrUnsupportedOutputFormat, format, strings.Join(getOutputFormats(), ","))
 	}
 	return outputFunc(a)
 }
 
+// StreamOutput writes each result to w as soon as its analyzer finishes,
+// rather than once the whole analysis is done.
+func (a *Analysis) StreamOutput(format string, w io.Writer) error {
+	resultFunc, ok := streamFormats[format]
+	if !ok {
+		formats := make([]string, 0, len(streamFormats))
+		for format := range streamFormats {
+			formats = append(formats, format)
+		}
+		return &sentinelError{
+			message:  fmt.Sprintf("output format %s can't be streamed. Available format %s", format, strings.Join(formats, ",")),
+			sentinel: ErrUnsupportedOutputFormat,
+		}
+	}
+	var n int
+	a.OnResult = func(result common.Result) {
+		_, _ = w.Write(resultFunc(a, n, result))
+		n++
+	}
+	return nil
+}
+
+// StreamSummary returns what follows the streamed results: the warnings and,
+// when nothing was found, a note saying so. NDJSON output only holds results.
+func (a *Analysis) StreamSummary(format string) []byte {
+	if format == "ndjson" {
+		return nil
+	}
+	var output strings.Builder
+	a.writeWarnings(&output)
+	if len(a.Results) == 0 {
+		output.WriteString(color.GreenString("No problems detected\n"))
+	}
+	return []byte(output.String())
+}
+
 func (a *Analysis) jsonOutput() ([]byte, error) {
 	var problems int
 	var status AnalysisStatus
@@ -45,6 +96,7 @@ func (a *Analysis) jsonOutput() ([]byte, error) {
 		Provider: a.AnalysisAIProvider,
 		Problems: problems,
 		Results:  a.Results,
+		Resolved: a.Resolved,
 		Errors:   a.Errors,
 		Status:   status,
 	}
@@ -55,15 +107,46 @@ func (a *Analysis) jsonOutput() ([]byte, error) {
 	return output, nil
 }
 
+// ndjsonOutput writes one JSON object per result and line, so log pipelines
+// can process the results without parsing a whole document.
+func (a *Analysis) ndjsonOutput() ([]byte, error) {
+	var output []byte
+	for n, result := range a.Results {
+		line := a.ndjsonResult(n, result)
+		if line == nil {
+			return nil, fmt.Errorf("error marshalling json for %s %s", result.Kind, result.Name)
+		}
+		output = append(output, line...)
+	}
+	return bytes.TrimSuffix(output, []byte("\n")), nil
+}
+
+func (a *Analysis) ndjsonResult(_ int, result common.Result) []byte {
+	line, err := json.Marshal(result)
+	if err != nil {
+		return nil
+	}
+	return append(line, '\n')
+}
+
 func (a *Analysis) PrintStats() []byte {
 	var output strings.Builder
 
 	output.WriteString(color.YellowString("The stats mode allows for debugging and understanding the time taken by an analysis by displaying the statistics of each analyzer.\n"))
 
-	for _, stat := range a.Stats {
+	// The slowest analyzers are listed first.
+	stats := slices.Clone(a.Stats)
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].DurationTime > stats[j].DurationTime
+	})
+	for _, stat := range stats {
 		output.WriteString(fmt.Sprintf("- Analyzer %s took %s \n", color.YellowString(stat.Analyzer), stat.DurationTime))
 	}
 
+	if a.PromptTokens > 0 {
+		output.WriteString(fmt.Sprintf("- AI prompts used an estimated %s tokens \n", color.YellowString("%d", a.PromptTokens)))
+	}
+
 	return []byte(output.String())
 }
 
@@ -77,30 +160,86 @@ func (a *Analysis) textOutput() ([]byte, error) {
 		output.WriteString(fmt.Sprintf("AI Provider: %s\n", color.YellowString("AI not used; --explain not set")))
 	}
 
-	if len(a.Errors) != 0 {
-		output.WriteString("\n")
-		output.WriteString(color.YellowString("Warnings : \n"))
-		for _, aerror := range a.Errors {
-			output.WriteString(fmt.Sprintf("- %s\n", color.YellowString(aerror)))
+	a.writeWarnings(&output)
+	output.WriteString("\n")
+	if !a.PreviousRun.IsZero() {
+		a.writeResolved(&output)
+		if len(a.Results) == 0 {
+			output.WriteString(color.GreenString("No new problems detected since the last run (%s)\n", a.PreviousRun.Format(time.RFC3339)))
+			return []byte(output.String()), nil
 		}
+		output.WriteString(color.YellowString("New since the last run (%s): \n", a.PreviousRun.Format(time.RFC3339)))
 	}
-	output.WriteString("\n")
 	if len(a.Results) == 0 {
 		output.WriteString(color.GreenString("No problems detected\n"))
 		return []byte(output.String()), nil
 	}
 	for n, result := range a.Results {
-		output.WriteString(fmt.Sprintf("%s: %s %s(%s)\n", color.CyanString("%d", n),
-			color.HiYellowString(result.Kind),
-			color.YellowString(result.Name),
-			color.CyanString(result.ParentObject)))
-		for _, err := range result.Error {
+		output.Write(a.textResult(n, result))
+	}
+	return []byte(output.String()), nil
+}
+
+// writeResolved lists the findings resolved since the previous run.
+func (a *Analysis) writeResolved(output *strings.Builder) {
+	if len(a.Resolved) == 0 {
+		return
+	}
+	output.WriteString(color.GreenString("Resolved since the last run (%s): \n", a.PreviousRun.Format(time.RFC3339)))
+	for _, result := range a.Resolved {
+		for _, failure := range result.Error {
+			output.WriteString(fmt.Sprintf("- %s %s: %s\n", color.HiYellowString(result.Kind), color.YellowString(result.Name), color.GreenString(failure.Text)))
+		}
+	}
+	output.WriteString("\n")
+}
+
+func (a *Analysis) writeWarnings(output *strings.Builder) {
+	if len(a.Errors) != 0 {
+		output.WriteString("\n")
+		output.WriteString(color.YellowString("Warnings : \n"))
+		for _, aerror := range a.Errors {
+			output.WriteString(fmt.Sprintf("- %s\n", color.YellowString(aerror)))
+		}
+	}
+}
+
+func (a *Analysis) textResult(n int, result common.Result) []byte {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("%s: %s %s(%s)\n", color.CyanString("%d", n),
+		color.HiYellowString(result.Kind),
+		color.YellowString(result.Name),
+		color.CyanString(result.ParentObject)))
+	for _, err := range result.Error {
+		if err.Severity != "" {
+			output.WriteString(fmt.Sprintf("- %s %s %s\n", color.RedString("Error:"), color.RedString("[%s]", err.Severity), color.RedString(err.Text)))
+		} else {
 			output.WriteString(fmt.Sprintf("- %s %s\n", color.RedString("Error:"), color.RedString(err.Text)))
-			if err.KubernetesDoc != "" {
-				output.WriteString(fmt.Sprintf("  %s %s\n", color.RedString("Kubernetes Doc:"), color.RedString(err.KubernetesDoc)))
-			}
 		}
-		output.WriteString(color.GreenString(result.Details + "\n"))
+		if err.FieldPath != "" {
+			output.WriteString(fmt.Sprintf("  %s %s\n", color.RedString("Field:"), color.RedString(err.FieldPath)))
+		}
+		if err.KubernetesDoc != "" {
+			output.WriteString(fmt.Sprintf("  %s %s\n", color.RedString("Kubernetes Doc:"), color.RedString(err.KubernetesDoc)))
+		}
 	}
-	return []byte(output.String()), nil
+	if a.WithCommands {
+		output.WriteString(fmt.Sprintf("  %s\n", color.CyanString("Suggested commands:")))
+		for _, command := range SuggestCommands(result) {
+			output.WriteString(fmt.Sprintf("    %s\n", color.CyanString(command)))
+		}
+	}
+	output.WriteString(color.GreenString(result.Details + "\n"))
+	for _, warning := range result.Warnings {
+		output.WriteString(fmt.Sprintf("%s %s\n", color.YellowString("Warning:"), color.YellowString(warning)))
+	}
+	if a.ScoreExplanations && result.Details != "" && result.Confidence < lowConfidence {
+		remediation := ""
+		if !result.HasRemediation {
+			remediation = " and gives no concrete remediation"
+		}
+		output.WriteString(fmt.Sprintf("%s %s\n", color.YellowString("Low confidence:"),
+			color.YellowString("%.2f, the explanation may not address the failures%s", result.Confidence, remediation)))
+	}
+	return []byte(output.String())
 }
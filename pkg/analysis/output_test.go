@@ -14,9 +14,22 @@ limitations under the License.
 package analysis
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"sync"
 	"testing"
 
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
 	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestPrintOutput(t *testing.T) {
@@ -41,6 +54,12 @@ func TestPrintOutput(t *testing.T) {
 			format:         "text",
 			expectedOutput: "AI Provider: AI not used; --explain not set\n\nNo problems detected\n",
 		},
+		{
+			name:           "junit format",
+			a:              &Analysis{},
+			format:         "junit",
+			expectedOutput: "<testsuites name=\"k8sgpt\" tests=\"0\" failures=\"0\" errors=\"0\"></testsuites>",
+		},
 		{
 			name:        "unsupported format",
 			a:           &Analysis{},
@@ -62,3 +81,317 @@ func TestPrintOutput(t *testing.T) {
 		})
 	}
 }
+
+func TestJUnitOutput(t *testing.T) {
+	a := &Analysis{
+		Namespace: "default",
+		Results: []common.Result{
+			{
+				Kind: "Pod",
+				Name: "default/crashing",
+				Error: []common.Failure{
+					{Text: "Back-off restarting failed container", Severity: common.SeverityHigh},
+					{Text: "the last termination reason is Error"},
+				},
+			},
+		},
+		Errors: []string{"[Ingress] forbidden", "AI budget exceeded, explained 1 of 2 results"},
+		AnalyzerErrors: []*AnalyzerError{
+			{Analyzer: "Ingress", Err: errors.New("forbidden")},
+		},
+		analyzersWithoutFindings: []string{"Service"},
+	}
+
+	output, err := a.PrintOutput("junit")
+	require.NoError(t, err)
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="k8sgpt" tests="3" failures="1" errors="1">
+  <testsuite name="Pod" tests="1" failures="1" errors="0">
+    <testcase name="default/crashing" classname="Pod">
+      <failure message="[high] Back-off restarting failed container" type="Pod">[high] Back-off restarting failed container&#xA;the last termination reason is Error</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="Service" tests="1" failures="0" errors="0">
+    <testcase name="default" classname="Service"></testcase>
+  </testsuite>
+  <testsuite name="k8sgpt" tests="1" failures="0" errors="1">
+    <testcase name="[Ingress] forbidden" classname="k8sgpt">
+      <error message="[Ingress] forbidden" type="AnalysisError"></error>
+    </testcase>
+    <system-out>AI budget exceeded, explained 1 of 2 results</system-out>
+  </testsuite>
+</testsuites>`, string(output))
+}
+
+func TestJUnitOutputParses(t *testing.T) {
+	a := Analysis{
+		Results: []common.Result{
+			{
+				Kind:    "Service",
+				Name:    "shop/web",
+				Error:   []common.Failure{{Text: `Service has no endpoints, expected label app="web" & tier<2>`}},
+				Details: "Error: ]]> <none> & \"quoted\"\nSolution: 1. Fix the selector.",
+			},
+			{Kind: "Pod", Name: "shop/web-1", Error: []common.Failure{{Text: "Back-off restarting failed container"}}},
+		},
+		Errors: []string{"[Ingress] forbidden"},
+		AnalyzerErrors: []*AnalyzerError{
+			{Analyzer: "Ingress", Err: errors.New("forbidden")},
+		},
+		analyzersWithoutFindings: []string{"Node"},
+	}
+
+	output, err := a.PrintOutput("junit")
+	require.NoError(t, err)
+
+	var report junitTestSuites
+	require.NoError(t, xml.Unmarshal(output, &report))
+	require.Equal(t, 4, report.Tests)
+	require.Equal(t, 2, report.Failures)
+	require.Equal(t, 1, report.Errors)
+
+	cases := map[string]junitTestCase{}
+	tests := 0
+	for _, suite := range report.Suites {
+		require.Len(t, suite.TestCases, suite.Tests)
+		tests += suite.Tests
+		for _, testCase := range suite.TestCases {
+			cases[suite.Name+" "+testCase.Name] = testCase
+		}
+	}
+	require.Equal(t, report.Tests, tests)
+
+	web := cases["Service shop/web"]
+	require.NotNil(t, web.Failure)
+	require.Equal(t, a.Results[0].Error[0].Text, web.Failure.Message)
+	require.Equal(t, a.Results[0].Details, web.SystemOut)
+	require.Nil(t, cases["Node all namespaces"].Failure)
+	require.NotNil(t, cases["k8sgpt [Ingress] forbidden"].Error)
+}
+
+func TestJUnitOutputNamespaces(t *testing.T) {
+	namespace := func(name string) *v1.Namespace {
+		return &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+	a := Analysis{
+		Context: context.Background(),
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(namespace("default"), namespace("shop"), namespace("kube-system")),
+		},
+		ExcludeNamespaces: []string{"kube-*"},
+		Results: []common.Result{
+			{Kind: "Pod", Name: "shop/web-1", Error: []common.Failure{{Text: "Back-off restarting failed container"}}},
+			{Kind: "Node", Name: "worker-1", Error: []common.Failure{{Text: "NotReady"}}},
+		},
+		analyzersWithoutFindings: []string{"Service"},
+	}
+
+	output, err := a.PrintOutput("junit")
+	require.NoError(t, err)
+
+	var report junitTestSuites
+	require.NoError(t, xml.Unmarshal(output, &report))
+	cases := map[string][]string{}
+	for _, suite := range report.Suites {
+		for _, testCase := range suite.TestCases {
+			cases[suite.Name] = append(cases[suite.Name], testCase.Name)
+		}
+	}
+	require.Equal(t, map[string][]string{
+		"Node":    {"worker-1"},
+		"Pod":     {"shop/web-1", "default"},
+		"Service": {"default", "shop"},
+	}, cases)
+	require.Equal(t, 2, report.Failures)
+}
+
+type stubAnalyzer struct {
+	results []common.Result
+}
+
+func (s stubAnalyzer) Analyze(common.Analyzer) ([]common.Result, error) {
+	return s.results, nil
+}
+
+func TestStreamOutput(t *testing.T) {
+	color.NoColor = true
+
+	a := &Analysis{}
+	require.ErrorContains(t, a.StreamOutput("json", io.Discard), "output format json can't be streamed")
+
+	var buf bytes.Buffer
+	require.NoError(t, a.StreamOutput("text", &buf))
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	semaphore := make(chan struct{}, 1)
+
+	for _, name := range []string{"first", "second"} {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		a.executeAnalyzer(stubAnalyzer{results: []common.Result{
+			{Kind: "Pod", Name: "default/" + name, Error: []common.Failure{{Text: name + " failed"}}},
+		}}, "Pod", common.Analyzer{}, semaphore, &wg, &mutex)
+		// The result is written as soon as its analyzer finishes.
+		require.Contains(t, buf.String(), "default/"+name)
+	}
+	wg.Wait()
+
+	require.Equal(t, "0: Pod default/first()\n- Error: first failed\n\n1: Pod default/second()\n- Error: second failed\n\n", buf.String())
+	require.Empty(t, string(a.StreamSummary("text")))
+}
+
+func TestNDJSONOutput(t *testing.T) {
+	results := []common.Result{
+		{Kind: "Pod", Name: "default/crashing", Error: []common.Failure{{Text: "Back-off restarting failed container"}}},
+		{Kind: "Service", Name: "default/web", Error: []common.Failure{{Text: "Service has no endpoints, expected label app=web", Severity: common.SeverityHigh}}},
+	}
+	expected := `{"kind":"Pod","name":"default/crashing","error":[{"Text":"Back-off restarting failed container","KubernetesDoc":"","Sensitive":null}],"details":"","parentObject":""}
+{"kind":"Service","name":"default/web","error":[{"Text":"Service has no endpoints, expected label app=web","KubernetesDoc":"","Sensitive":null,"Severity":"high"}],"details":"","parentObject":""}`
+
+	output, err := (&Analysis{Results: results}).PrintOutput("ndjson")
+	require.NoError(t, err)
+	require.Equal(t, expected, string(output))
+
+	var buf bytes.Buffer
+	a := &Analysis{}
+	require.NoError(t, a.StreamOutput("ndjson", &buf))
+	for _, result := range results {
+		a.OnResult(result)
+	}
+	require.Equal(t, expected+"\n", buf.String())
+	require.Nil(t, a.StreamSummary("ndjson"))
+}
+
+func TestJSONOutputRoundTrip(t *testing.T) {
+	results := []common.Result{
+		{
+			Kind: "Pod",
+			Name: "default/web",
+			Error: []common.Failure{
+				{
+					Text:          "Back-off pulling image \"nginx:latst\"",
+					KubernetesDoc: "Container image name.",
+					Sensitive:     []common.Sensitive{{Unmasked: "web", Masked: "d2Vi"}},
+					Severity:      common.SeverityHigh,
+					FieldPath:     "spec.containers[0].image",
+				},
+			},
+			Details:      "The image tag is misspelled.",
+			ParentObject: "Deployment/web",
+			Warnings:     []string{"the explanation mentions namespace prod, which does not exist in the cluster"},
+		},
+	}
+	a := &Analysis{Results: results, Errors: []string{"[Service] forbidden"}}
+
+	output, err := a.PrintOutput("json")
+	require.NoError(t, err)
+
+	var got JsonOutput
+	require.NoError(t, json.Unmarshal(output, &got))
+	require.Equal(t, results, got.Results)
+	require.Equal(t, AnalysisErrors(a.Errors), got.Errors)
+	require.Equal(t, 1, got.Problems)
+}
+
+func TestSARIFOutput(t *testing.T) {
+	a := &Analysis{
+		Results: []common.Result{
+			{
+				Kind: "Pod",
+				Name: "default/crashing",
+				Error: []common.Failure{
+					{Text: "Back-off restarting failed container", Severity: common.SeverityHigh},
+					{Text: "the last termination reason is Error"},
+				},
+			},
+			{
+				Kind: "Node",
+				Name: "worker-1",
+				Error: []common.Failure{
+					{Text: "worker-1 has condition of type MemoryPressure", Severity: common.SeverityLow, FieldPath: "status.conditions"},
+				},
+			},
+		},
+		Errors: []string{"[Ingress] forbidden"},
+	}
+
+	output, err := a.PrintOutput("sarif")
+	require.NoError(t, err)
+
+	// Check the parts of the SARIF 2.1.0 schema consumers rely on: the
+	// version, a tool driver with the rules, and results that reference a
+	// rule and carry a level, a message and a location.
+	var log map[string]interface{}
+	require.NoError(t, json.Unmarshal(output, &log))
+	require.Equal(t, "2.1.0", log["version"])
+	require.Equal(t, "https://json.schemastore.org/sarif-2.1.0.json", log["$schema"])
+	runs := log["runs"].([]interface{})
+	require.Len(t, runs, 1)
+	run := runs[0].(map[string]interface{})
+
+	driver := run["tool"].(map[string]interface{})["driver"].(map[string]interface{})
+	require.Equal(t, "k8sgpt", driver["name"])
+	var ruleIDs []string
+	for _, rule := range driver["rules"].([]interface{}) {
+		ruleIDs = append(ruleIDs, rule.(map[string]interface{})["id"].(string))
+	}
+	require.Equal(t, []string{"Node", "Pod"}, ruleIDs)
+
+	invocation := run["invocations"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, false, invocation["executionSuccessful"])
+	notification := invocation["toolExecutionNotifications"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "[Ingress] forbidden", notification["message"].(map[string]interface{})["text"])
+
+	type location struct {
+		name, fullyQualifiedName string
+	}
+	type finding struct {
+		ruleID, level, text string
+		location            location
+	}
+	var findings []finding
+	for _, r := range run["results"].([]interface{}) {
+		result := r.(map[string]interface{})
+		ruleIndex := int(result["ruleIndex"].(float64))
+		require.Equal(t, ruleIDs[ruleIndex], result["ruleId"])
+		logical := result["locations"].([]interface{})[0].(map[string]interface{})["logicalLocations"].([]interface{})[0].(map[string]interface{})
+		require.Equal(t, "resource", logical["kind"])
+		findings = append(findings, finding{
+			ruleID: result["ruleId"].(string),
+			level:  result["level"].(string),
+			text:   result["message"].(map[string]interface{})["text"].(string),
+			location: location{
+				name:               logical["name"].(string),
+				fullyQualifiedName: logical["fullyQualifiedName"].(string),
+			},
+		})
+	}
+	require.Equal(t, []finding{
+		{"Pod", "error", "Back-off restarting failed container", location{"crashing", "Pod/default/crashing"}},
+		{"Pod", "warning", "the last termination reason is Error", location{"crashing", "Pod/default/crashing"}},
+		{"Node", "note", "worker-1 has condition of type MemoryPressure", location{"worker-1", "Node/worker-1"}},
+	}, findings)
+
+	properties := run["results"].([]interface{})[0].(map[string]interface{})["properties"].(map[string]interface{})
+	require.Equal(t, map[string]interface{}{"kind": "Pod", "namespace": "default"}, properties)
+}
+
+func TestSARIFOutputEmpty(t *testing.T) {
+	output, err := (&Analysis{}).PrintOutput("sarif")
+	require.NoError(t, err)
+
+	var log struct {
+		Runs []struct {
+			Results     []interface{} `json:"results"`
+			Invocations []struct {
+				ExecutionSuccessful bool `json:"executionSuccessful"`
+			} `json:"invocations"`
+		} `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal(output, &log))
+	require.Len(t, log.Runs, 1)
+	require.NotNil(t, log.Runs[0].Results)
+	require.Empty(t, log.Runs[0].Results)
+	require.True(t, log.Runs[0].Invocations[0].ExecutionSuccessful)
+}
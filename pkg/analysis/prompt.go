@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+)
+
+// PromptData is what a prompt template is executed with.
+type PromptData struct {
+	// Kind and Name are those of the result being explained. Name is masked
+	// like the failures when anonymizing, and empty when the explanation
+	// isn't for a single result.
+	Kind     string
+	Name     string
+	Language string
+	// Error is the failure texts joined by spaces, and Errors the texts.
+	Error  string
+	Errors []string
+	// Logs are the last lines logged by the previous instance of the
+	// failing container, when included. See Analysis.IncludeLogs.
+	Logs string
+}
+
+// LoadPromptTemplate parses the text/template file at path. The template is
+// executed once with sample data, so references to fields PromptData
+// doesn't have fail here rather than on the first explanation.
+func LoadPromptTemplate(path string) (*template.Template, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading prompt template: %w", err)
+	}
+	tmpl, err := template.New(filepath.Base(path)).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("parsing prompt template %s: %w", path, err)
+	}
+	sample := PromptData{
+		Kind:     "Pod",
+		Name:     "default/example",
+		Language: "english",
+		Error:    "example error",
+		Errors:   []string{"example error"},
+		Logs:     "example log line",
+	}
+	if err := tmpl.Execute(io.Discard, sample); err != nil {
+		return nil, fmt.Errorf("invalid prompt template %s: %w", path, err)
+	}
+	return tmpl, nil
+}
+
+// renderPrompt returns the prompt for the data. The custom prompt template
+// replaces the default prompt, while kinds of integrations keep their own.
+func (a *Analysis) renderPrompt(data PromptData, promptTmpl string) (string, error) {
+	if _, ok := ai.PromptMap[data.Kind]; a.PromptTemplate == nil || ok {
+		prompt := fmt.Sprintf(strings.TrimSpace(promptTmpl), data.Language, data.Error)
+		if data.Logs != "" {
+			prompt += fmt.Sprintf("\nThe last lines logged by the previous instance of the failing container, delimited by triple dashes, may tell why it failed: --- %s ---", data.Logs)
+		}
+		return prompt, nil
+	}
+	var prompt bytes.Buffer
+	if err := a.PromptTemplate.Execute(&prompt, data); err != nil {
+		return "", fmt.Errorf("executing prompt template: %w", err)
+	}
+	return strings.TrimSpace(prompt.String()), nil
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/cache"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func writePromptTemplate(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "prompt.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadPromptTemplate(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expectedErr string
+	}{
+		{
+			name:    "valid",
+			content: "Explain in {{.Language}} why {{.Kind}} {{.Name}} fails:{{range .Errors}} {{.}}{{end}}",
+		},
+		{
+			name:        "unknown field",
+			content:     "Explain {{.ErrorComponents}}",
+			expectedErr: "can't evaluate field ErrorComponents",
+		},
+		{
+			name:        "syntax error",
+			content:     "Explain {{.Kind",
+			expectedErr: "parsing prompt template",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := LoadPromptTemplate(writePromptTemplate(t, tt.content))
+			if tt.expectedErr == "" {
+				require.NoError(t, err)
+				require.NotNil(t, tmpl)
+			} else {
+				require.ErrorContains(t, err, tt.expectedErr)
+			}
+		})
+	}
+
+	_, err := LoadPromptTemplate(filepath.Join(t.TempDir(), "missing.tmpl"))
+	require.ErrorContains(t, err, "reading prompt template")
+}
+
+func TestPromptTemplateExplanation(t *testing.T) {
+	tmpl, err := LoadPromptTemplate(writePromptTemplate(t, "In {{.Language}}, {{.Kind}} {{.Name}}: {{.Error}}"))
+	require.NoError(t, err)
+
+	disabledCache := cache.New("disabled-cache")
+	disabledCache.DisableCache()
+	a := Analysis{
+		AIClient:       &ai.NoOpAIClient{},
+		Cache:          disabledCache,
+		Language:       "English",
+		PromptTemplate: tmpl,
+	}
+	result := common.Result{
+		Kind: "Service",
+		Name: "default/frontend",
+		Error: []common.Failure{
+			{
+				Text:      "Service default/frontend has no endpoints",
+				Sensitive: []common.Sensitive{{Unmasked: "frontend", Masked: "bWFza2Vk"}},
+			},
+		},
+	}
+
+	output, err := a.explain(context.Background(), result, false)
+	require.NoError(t, err)
+	require.Equal(t, "I am a noop response to the prompt In English, Service default/frontend: Service default/frontend has no endpoints", output)
+
+	// Integrations keep their own prompts.
+	prompt, err := a.renderPrompt(PromptData{Kind: "PolicyReport", Language: "English", Error: "denied"}, ai.PromptMap["PolicyReport"])
+	require.NoError(t, err)
+	require.Contains(t, prompt, "Kyverno")
+}
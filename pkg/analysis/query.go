@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+)
+
+// ResultFilterOptions selects the results and failures kept by FilterResults.
+// Unset options keep everything.
+type ResultFilterOptions struct {
+	// Kinds keeps the results of these kinds.
+	Kinds []string
+	// Namespaces keeps the results of objects in namespaces matching these
+	// glob patterns. Results of cluster-scoped objects are dropped.
+	Namespaces []string
+	// MinSeverity keeps the failures ranked at least as severe. Failures
+	// without a severity are kept, as with Analysis.MinSeverity.
+	MinSeverity common.Severity
+	// Text keeps the failures whose text contains it.
+	Text string
+	// TextPattern keeps the failures whose text it matches.
+	TextPattern *regexp.Regexp
+}
+
+// FilterResults returns the results selected by opts, holding only their
+// selected failures. Results left without failures are dropped. The results
+// given are not modified.
+func FilterResults(results []common.Result, opts ResultFilterOptions) []common.Result {
+	kept := []common.Result{}
+	for _, result := range results {
+		if len(opts.Kinds) > 0 && !slices.Contains(opts.Kinds, result.Kind) {
+			continue
+		}
+		if len(opts.Namespaces) > 0 {
+			namespace, _, found := strings.Cut(result.Name, "/")
+			if !found || !matchesAny(namespace, opts.Namespaces) {
+				continue
+			}
+		}
+
+		failures := make([]common.Failure, 0, len(result.Error))
+		for _, failure := range result.Error {
+			if opts.matchesFailure(failure) {
+				failures = append(failures, failure)
+			}
+		}
+		if len(failures) == 0 {
+			continue
+		}
+		result.Error = failures
+		kept = append(kept, result)
+	}
+	return kept
+}
+
+func (opts ResultFilterOptions) matchesFailure(failure common.Failure) bool {
+	if rank := failure.Severity.Rank(); rank != 0 && rank < opts.MinSeverity.Rank() {
+		return false
+	}
+	if opts.Text != "" && !strings.Contains(failure.Text, opts.Text) {
+		return false
+	}
+	if opts.TextPattern != nil && !opts.TextPattern.MatchString(failure.Text) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterResults(t *testing.T) {
+	results := []common.Result{
+		{
+			Kind: "Pod",
+			Name: "default/web",
+			Error: []common.Failure{
+				{Text: "back-off restarting failed container", Severity: common.SeverityHigh},
+				{Text: "readiness probe failed: HTTP probe failed with statuscode: 503", Severity: common.SeverityMedium},
+			},
+		},
+		{
+			Kind: "Service",
+			Name: "kube-system/dns",
+			Error: []common.Failure{
+				{Text: "Service has no endpoints, expected label k8s-app=kube-dns", Severity: common.SeverityLow},
+				{Text: "Service has not ready endpoints"},
+			},
+		},
+		{
+			Kind:  "Node",
+			Name:  "worker-1",
+			Error: []common.Failure{{Text: "worker-1 is under MemoryPressure", Severity: common.SeverityMedium}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		opts     ResultFilterOptions
+		expected map[string][]string
+	}{
+		{
+			name: "no options",
+			opts: ResultFilterOptions{},
+			expected: map[string][]string{
+				"Pod default/web":         {"back-off restarting failed container", "readiness probe failed: HTTP probe failed with statuscode: 503"},
+				"Service kube-system/dns": {"Service has no endpoints, expected label k8s-app=kube-dns", "Service has not ready endpoints"},
+				"Node worker-1":           {"worker-1 is under MemoryPressure"},
+			},
+		},
+		{
+			name: "kinds",
+			opts: ResultFilterOptions{Kinds: []string{"Service", "Node"}},
+			expected: map[string][]string{
+				"Service kube-system/dns": {"Service has no endpoints, expected label k8s-app=kube-dns", "Service has not ready endpoints"},
+				"Node worker-1":           {"worker-1 is under MemoryPressure"},
+			},
+		},
+		{
+			name: "namespaces drop cluster-scoped results",
+			opts: ResultFilterOptions{Namespaces: []string{"kube-*"}},
+			expected: map[string][]string{
+				"Service kube-system/dns": {"Service has no endpoints, expected label k8s-app=kube-dns", "Service has not ready endpoints"},
+			},
+		},
+		{
+			name: "severity keeps unranked failures",
+			opts: ResultFilterOptions{MinSeverity: common.SeverityMedium},
+			expected: map[string][]string{
+				"Pod default/web":         {"back-off restarting failed container", "readiness probe failed: HTTP probe failed with statuscode: 503"},
+				"Service kube-system/dns": {"Service has not ready endpoints"},
+				"Node worker-1":           {"worker-1 is under MemoryPressure"},
+			},
+		},
+		{
+			name: "text",
+			opts: ResultFilterOptions{Text: "endpoints"},
+			expected: map[string][]string{
+				"Service kube-system/dns": {"Service has no endpoints, expected label k8s-app=kube-dns", "Service has not ready endpoints"},
+			},
+		},
+		{
+			name: "text pattern",
+			opts: ResultFilterOptions{TextPattern: regexp.MustCompile(`(?i)probe failed|pressure`)},
+			expected: map[string][]string{
+				"Pod default/web": {"readiness probe failed: HTTP probe failed with statuscode: 503"},
+				"Node worker-1":   {"worker-1 is under MemoryPressure"},
+			},
+		},
+		{
+			name: "options combined",
+			opts: ResultFilterOptions{Kinds: []string{"Pod", "Service"}, MinSeverity: common.SeverityHigh, Text: "failed"},
+			expected: map[string][]string{
+				"Pod default/web": {"back-off restarting failed container"},
+			},
+		},
+		{
+			name:     "nothing selected",
+			opts:     ResultFilterOptions{Kinds: []string{"Ingress"}},
+			expected: map[string][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := FilterResults(results, tt.opts)
+			require.NotNil(t, filtered)
+
+			got := map[string][]string{}
+			for _, result := range filtered {
+				for _, failure := range result.Error {
+					got[result.Kind+" "+result.Name] = append(got[result.Kind+" "+result.Name], failure.Text)
+				}
+			}
+			require.Equal(t, tt.expected, got)
+		})
+	}
+
+	// The results given are left as they were.
+	require.Len(t, results, 3)
+	require.Len(t, results[0].Error, 2)
+	require.Equal(t, "back-off restarting failed container", results[0].Error[0].Text)
+	require.Len(t, results[1].Error, 2)
+}
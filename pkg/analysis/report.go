@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WriteReport writes the whole analysis, with the explanations, to path as a
+// Markdown report holding a section per resource, to be attached to incident
+// tickets. The report is written to a temporary file renamed over path, so
+// readers never see a partial report.
+func (a *Analysis) WriteReport(path string) error {
+	report, err := a.markdownReport()
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, report)
+}
+
+// writeFileAtomic writes data to a temporary file renamed over path.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("error creating %s: %w", path, err)
+	}
+	// Removing the temporary file fails once it was renamed, which is fine.
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// markdownReport renders the JSON output of the analysis as Markdown.
+func (a *Analysis) markdownReport() ([]byte, error) {
+	data, err := a.jsonOutput()
+	if err != nil {
+		return nil, err
+	}
+	var analysis JsonOutput
+	if err := json.Unmarshal(data, &analysis); err != nil {
+		return nil, fmt.Errorf("error unmarshalling json: %v", err)
+	}
+
+	var output strings.Builder
+	output.WriteString("# K8sGPT analysis report\n\n")
+	output.WriteString(fmt.Sprintf("- Status: %s\n", analysis.Status))
+	output.WriteString(fmt.Sprintf("- Problems: %d\n", analysis.Problems))
+	if analysis.Provider != "" {
+		output.WriteString(fmt.Sprintf("- AI provider: %s\n", analysis.Provider))
+	}
+
+	if len(analysis.Errors) > 0 {
+		output.WriteString("\n## Analysis errors\n\n")
+		for _, analysisError := range analysis.Errors {
+			output.WriteString(fmt.Sprintf("- %s\n", analysisError))
+		}
+	}
+
+	for _, result := range analysis.Results {
+		output.WriteString(fmt.Sprintf("\n## %s %s\n\n", result.Kind, result.Name))
+		if result.ParentObject != "" {
+			output.WriteString(fmt.Sprintf("Parent: %s\n\n", result.ParentObject))
+		}
+		output.WriteString("### Errors\n\n")
+		for _, failure := range result.Error {
+			output.WriteString("- ")
+			if failure.Severity != "" {
+				output.WriteString(fmt.Sprintf("**%s** ", failure.Severity))
+			}
+			output.WriteString(failure.Text)
+			if failure.FieldPath != "" {
+				output.WriteString(fmt.Sprintf(" (`%s`)", failure.FieldPath))
+			}
+			output.WriteString("\n")
+			if failure.KubernetesDoc != "" {
+				output.WriteString(fmt.Sprintf("  - Kubernetes doc: %s\n", failure.KubernetesDoc))
+			}
+		}
+		if result.Details != "" {
+			output.WriteString(fmt.Sprintf("\n### Explanation\n\n%s\n", strings.TrimSpace(result.Details)))
+		}
+		if len(result.Warnings) > 0 {
+			output.WriteString("\n### Warnings\n\n")
+			for _, warning := range result.Warnings {
+				output.WriteString(fmt.Sprintf("- %s\n", warning))
+			}
+		}
+		if a.WithCommands {
+			if commands := SuggestCommands(result); len(commands) > 0 {
+				output.WriteString("\n### Suggested commands\n\n```sh\n")
+				output.WriteString(strings.Join(commands, "\n"))
+				output.WriteString("\n```\n")
+			}
+		}
+	}
+	if len(analysis.Resolved) > 0 {
+		output.WriteString("\n## Resolved since the last run\n\n")
+		for _, result := range analysis.Resolved {
+			for _, failure := range result.Error {
+				output.WriteString(fmt.Sprintf("- %s %s: %s\n", result.Kind, result.Name, failure.Text))
+			}
+		}
+	}
+	return []byte(output.String()), nil
+}
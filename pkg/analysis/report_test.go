@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteReport(t *testing.T) {
+	a := &Analysis{
+		AnalysisAIProvider: "openai",
+		Errors:             []string{"[Node] timed out after 30s"},
+		Results: []common.Result{
+			{
+				Kind: "Pod",
+				Name: "default/web",
+				Error: []common.Failure{
+					{
+						Text:      "the image nginx:lates can't be pulled",
+						Severity:  common.SeverityHigh,
+						FieldPath: "spec.containers[0].image",
+					},
+				},
+				Details:      "Error: the image tag is misspelled.\nSolution: use nginx:latest.\n",
+				ParentObject: "Deployment/web",
+			},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "report.md")
+	require.NoError(t, os.WriteFile(path, []byte("stale report"), 0o600))
+	require.NoError(t, a.WriteReport(path))
+
+	report, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "# K8sGPT analysis report\n\n"+
+		"- Status: ProblemDetected\n"+
+		"- Problems: 1\n"+
+		"- AI provider: openai\n"+
+		"\n## Analysis errors\n\n"+
+		"- [Node] timed out after 30s\n"+
+		"\n## Pod default/web\n\n"+
+		"Parent: Deployment/web\n\n"+
+		"### Errors\n\n"+
+		"- **high** the image nginx:lates can't be pulled (`spec.containers[0].image`)\n"+
+		"\n### Explanation\n\n"+
+		"Error: the image tag is misspelled.\nSolution: use nginx:latest.\n", string(report))
+
+	// Only the report is left in the directory.
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestWriteReportMissingDirectory(t *testing.T) {
+	a := &Analysis{}
+	path := filepath.Join(t.TempDir(), "missing", "report.md")
+	require.ErrorContains(t, a.WriteReport(path), "error creating")
+	_, err := os.Stat(path)
+	require.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	// Used when ai_retries and ai_retry_delay are not configured.
+	defaultAIRetries    = 3
+	defaultAIRetryDelay = time.Second
+)
+
+// retryableMessagePattern matches rate limiting and unavailability errors of
+// the providers that don't return typed errors.
+var retryableMessagePattern = regexp.MustCompile(`(?i)\b(429|503)\b|too many requests|service unavailable|rate limit`)
+
+// getCompletionWithRetry calls the AI provider, retrying transient errors up
+// to AIRetries times with an exponential backoff from AIRetryDelay and
+// jitter. Other errors, and the cancellation of ctx, return immediately.
+func (a *Analysis) getCompletionWithRetry(ctx context.Context, client ai.IAI, prompt string) (string, error) {
+	delay := a.AIRetryDelay
+	for attempt := 0; ; attempt++ {
+		response, err := client.GetCompletion(ctx, prompt)
+		if err == nil || attempt >= a.AIRetries || ctx.Err() != nil || !isRetryableAIError(err) {
+			return response, err
+		}
+
+		wait := delay
+		if delay/2 > 0 {
+			wait += rand.N(delay / 2)
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(wait):
+		}
+		delay *= 2
+	}
+}
+
+// isRetryableAIError reports whether the error is a timeout, or the provider
+// rate limiting requests or being unavailable.
+func isRetryableAIError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return isRetryableStatus(apiErr.HTTPStatusCode)
+	}
+	var requestErr *openai.RequestError
+	if errors.As(err, &requestErr) {
+		return isRetryableStatus(requestErr.HTTPStatusCode)
+	}
+	return retryableMessagePattern.MatchString(err.Error())
+}
+
+func isRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code == http.StatusServiceUnavailable
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyAIClient fails with the given errors before succeeding.
+type flakyAIClient struct {
+	ai.NoOpAIClient
+	errs  []error
+	calls int
+}
+
+func (c *flakyAIClient) GetCompletion(_ context.Context, prompt string) (string, error) {
+	c.calls++
+	if c.calls <= len(c.errs) {
+		return "", c.errs[c.calls-1]
+	}
+	return "explained", nil
+}
+
+func TestGetCompletionWithRetry(t *testing.T) {
+	rateLimited := &openai.APIError{HTTPStatusCode: 429, Message: "Rate limit reached"}
+	unavailable := fmt.Errorf("error, status code: 503, message: overloaded")
+	invalidKey := &openai.APIError{HTTPStatusCode: 401, Message: "Incorrect API key provided"}
+
+	tests := []struct {
+		name          string
+		retries       int
+		delay         time.Duration
+		errs          []error
+		expectedCalls int
+		expectedErr   error
+	}{
+		{
+			name:          "succeeds after two transient errors",
+			retries:       3,
+			errs:          []error{rateLimited, unavailable},
+			expectedCalls: 3,
+		},
+		{
+			name:          "retries with a delay too short for jitter",
+			retries:       2,
+			delay:         time.Nanosecond,
+			errs:          []error{rateLimited, rateLimited},
+			expectedCalls: 3,
+		},
+		{
+			name:          "gives up after the retries",
+			retries:       1,
+			errs:          []error{rateLimited, rateLimited},
+			expectedCalls: 2,
+			expectedErr:   rateLimited,
+		},
+		{
+			name:          "does not retry other errors",
+			retries:       3,
+			errs:          []error{invalidKey},
+			expectedCalls: 1,
+			expectedErr:   invalidKey,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			client := &flakyAIClient{errs: tt.errs}
+			delay := tt.delay
+			if delay == 0 {
+				delay = time.Millisecond
+			}
+			a := &Analysis{AIRetries: tt.retries, AIRetryDelay: delay}
+
+			response, err := a.getCompletionWithRetry(context.Background(), client, "prompt")
+			require.Equal(t, tt.expectedCalls, client.calls)
+			if tt.expectedErr == nil {
+				require.NoError(t, err)
+				require.Equal(t, "explained", response)
+			} else {
+				require.ErrorIs(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestGetCompletionWithRetryCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	client := &flakyAIClient{errs: []error{errors.New("429 Too Many Requests")}}
+	a := &Analysis{AIRetries: 3, AIRetryDelay: time.Hour}
+
+	go cancel()
+	_, err := a.getCompletionWithRetry(ctx, client, "prompt")
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 1, client.calls)
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/spf13/viper"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations"`
+	Results     []sarifResult     `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level   string       `json:"level"`
+	Message sarifMessage `json:"message"`
+}
+
+type sarifResult struct {
+	RuleID     string            `json:"ruleId"`
+	RuleIndex  int               `json:"ruleIndex"`
+	Level      string            `json:"level"`
+	Message    sarifMessage      `json:"message"`
+	Locations  []sarifLocation   `json:"locations"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// sarifLevel maps a failure severity to a SARIF result level. Failures
+// without a severity are reported as warnings.
+func sarifLevel(severity common.Severity) string {
+	switch severity {
+	case common.SeverityHigh:
+		return "error"
+	case common.SeverityLow:
+		return "note"
+	default:
+		return "warning"
+	}
+}
+
+// sarifOutput renders the analysis as a SARIF 2.1.0 log so code scanning and
+// compliance tools can ingest the findings. Each analyzed kind is a rule and
+// each failure a result located at its resource; analyzers that could not run
+// are reported as tool execution notifications.
+func (a *Analysis) sarifOutput() ([]byte, error) {
+	kinds := map[string]bool{}
+	for _, result := range a.Results {
+		kinds[result.Kind] = true
+	}
+	ruleIDs := make([]string, 0, len(kinds))
+	for kind := range kinds {
+		ruleIDs = append(ruleIDs, kind)
+	}
+	sort.Strings(ruleIDs)
+
+	rules := make([]sarifRule, 0, len(ruleIDs))
+	ruleIndex := map[string]int{}
+	for i, kind := range ruleIDs {
+		ruleIndex[kind] = i
+		rules = append(rules, sarifRule{
+			ID:               kind,
+			Name:             kind + "Analyzer",
+			ShortDescription: sarifMessage{Text: fmt.Sprintf("Problems found by the %s analyzer", kind)},
+		})
+	}
+
+	results := []sarifResult{}
+	for _, result := range a.Results {
+		namespace, name := "", result.Name
+		if i := strings.Index(result.Name, "/"); i >= 0 {
+			namespace, name = result.Name[:i], result.Name[i+1:]
+		}
+		for _, failure := range result.Error {
+			properties := map[string]string{
+				"kind": result.Kind,
+			}
+			if namespace != "" {
+				properties["namespace"] = namespace
+			}
+			if failure.FieldPath != "" {
+				properties["fieldPath"] = failure.FieldPath
+			}
+			if result.Details != "" {
+				properties["explanation"] = result.Details
+			}
+			results = append(results, sarifResult{
+				RuleID:    result.Kind,
+				RuleIndex: ruleIndex[result.Kind],
+				Level:     sarifLevel(failure.Severity),
+				Message:   sarifMessage{Text: failure.Text},
+				Locations: []sarifLocation{
+					{
+						LogicalLocations: []sarifLogicalLocation{
+							{
+								Name:               name,
+								FullyQualifiedName: fmt.Sprintf("%s/%s", result.Kind, result.Name),
+								Kind:               "resource",
+							},
+						},
+					},
+				},
+				Properties: properties,
+			})
+		}
+	}
+
+	invocation := sarifInvocation{ExecutionSuccessful: len(a.Errors) == 0}
+	for _, analysisError := range a.Errors {
+		invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications, sarifNotification{
+			Level:   "error",
+			Message: sarifMessage{Text: analysisError},
+		})
+	}
+
+	report := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{
+					Driver: sarifDriver{
+						Name:           "k8sgpt",
+						Version:        viper.GetString("Version"),
+						InformationURI: "https://github.com/k8sgpt-ai/k8sgpt",
+						Rules:          rules,
+					},
+				},
+				Invocations: []sarifInvocation{invocation},
+				Results:     results,
+			},
+		},
+	}
+
+	output, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling sarif: %v", err)
+	}
+	return output, nil
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"sort"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+)
+
+// applySeverity orders the failures of the result from the most to the
+// least severe and drops those below MinSeverity. It returns false when no
+// failure is left.
+func (a *Analysis) applySeverity(result common.Result) (common.Result, bool) {
+	if len(result.Error) == 0 {
+		return result, true
+	}
+	minRank := a.MinSeverity.Rank()
+	failures := make([]common.Failure, 0, len(result.Error))
+	for _, failure := range result.Error {
+		if rank := failure.Severity.Rank(); rank == 0 || rank >= minRank {
+			failures = append(failures, failure)
+		}
+	}
+	if len(failures) == 0 {
+		return result, false
+	}
+	sort.SliceStable(failures, func(i, j int) bool {
+		return failures[i].Severity.Rank() > failures[j].Severity.Rank()
+	})
+	result.Error = failures
+	return result, true
+}
+
+// applySeverities applies applySeverity to each result.
+func (a *Analysis) applySeverities(results []common.Result) []common.Result {
+	var kept []common.Result
+	for _, result := range results {
+		if result, ok := a.applySeverity(result); ok {
+			kept = append(kept, result)
+		}
+	}
+	return kept
+}
+
+// Findings returns the number of failures of the results and the highest
+// severity among them, empty when there are none. Unranked failures count
+// as low ones, so that no failure goes unnoticed. The analyze command exits
+// with an error code from them.
+func (a *Analysis) Findings() (int, common.Severity) {
+	var count int
+	var maxSeverity common.Severity
+	for _, result := range a.Results {
+		for _, failure := range result.Error {
+			count++
+			severity := failure.Severity
+			if severity.Rank() == 0 {
+				severity = common.SeverityLow
+			}
+			if severity.Rank() > maxSeverity.Rank() {
+				maxSeverity = severity
+			}
+		}
+	}
+	return count, maxSeverity
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplySeverities(t *testing.T) {
+	results := func() []common.Result {
+		return []common.Result{
+			{
+				Kind: "Pod",
+				Name: "default/web",
+				Error: []common.Failure{
+					{Text: "Readiness probe failed", Severity: common.SeverityMedium},
+					{Text: "unranked"},
+					{Text: "OOMKilled", Severity: common.SeverityHigh},
+				},
+			},
+			{
+				Kind:  "Pod",
+				Name:  "default/batch",
+				Error: []common.Failure{{Text: "Readiness probe failed", Severity: common.SeverityMedium}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		minSeverity common.Severity
+		expected    map[string][]string
+	}{
+		{
+			name: "sorted by severity",
+			expected: map[string][]string{
+				"default/web":   {"OOMKilled", "Readiness probe failed", "unranked"},
+				"default/batch": {"Readiness probe failed"},
+			},
+		},
+		{
+			name:        "high only",
+			minSeverity: common.SeverityHigh,
+			expected: map[string][]string{
+				"default/web": {"OOMKilled", "unranked"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Analysis{MinSeverity: tt.minSeverity}
+			got := map[string][]string{}
+			for _, result := range a.applySeverities(results()) {
+				for _, failure := range result.Error {
+					got[result.Name] = append(got[result.Name], failure.Text)
+				}
+			}
+			require.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestFindings(t *testing.T) {
+	tests := []struct {
+		name        string
+		results     []common.Result
+		count       int
+		maxSeverity common.Severity
+	}{
+		{
+			name: "clean cluster",
+		},
+		{
+			name: "unranked failures",
+			results: []common.Result{
+				{Kind: "Pod", Name: "default/web", Error: []common.Failure{{Text: "a"}, {Text: "b"}}},
+			},
+			count:       2,
+			maxSeverity: common.SeverityLow,
+		},
+		{
+			name: "ranked failures",
+			results: []common.Result{
+				{Kind: "Pod", Name: "default/web", Error: []common.Failure{{Text: "a", Severity: common.SeverityLow}, {Text: "b"}}},
+				{Kind: "Node", Name: "worker-1", Error: []common.Failure{{Text: "c", Severity: common.SeverityMedium}}},
+			},
+			count:       3,
+			maxSeverity: common.SeverityMedium,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Analysis{Results: tt.results}
+			count, maxSeverity := a.Findings()
+			require.Equal(t, tt.count, count)
+			require.Equal(t, tt.maxSeverity, maxSeverity)
+			// any failure makes --fail-on-severity low exit with an error
+			require.Equal(t, count > 0, maxSeverity.Rank() >= common.SeverityLow.Rank())
+		})
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"errors"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+)
+
+// StreamResults runs the analysis in the background, custom analyzers
+// included when configured, and sends each result on the first channel as
+// soon as its analyzer finishes. Once all analyzers are done, that channel
+// is closed and the analysis warnings are sent on the second one, which is
+// closed in turn. The warnings of failed analyzers are their AnalyzerError.
+// Results are still collected in Results and OnResult is still called. Once
+// ctx is done, nothing more is sent and the analyzers still running stop as
+// soon as their API calls fail.
+func (a *Analysis) StreamResults(ctx context.Context) (<-chan common.Result, <-chan error) {
+	results := make(chan common.Result)
+	errs := make(chan error)
+
+	a.Context = ctx
+	onResult := a.OnResult
+	a.OnResult = func(result common.Result) {
+		if onResult != nil {
+			onResult(result)
+		}
+		select {
+		case results <- result:
+		case <-ctx.Done():
+		}
+	}
+
+	go func() {
+		defer close(errs)
+		if a.CustomAnalyzersAreAvailable() {
+			a.RunCustomAnalysis()
+		}
+		a.RunAnalysis()
+		close(results)
+
+		analyzerErrs := map[string]error{}
+		for _, err := range a.AnalyzerErrors {
+			analyzerErrs[err.Error()] = err
+		}
+		for _, warning := range a.Errors {
+			err, ok := analyzerErrs[warning]
+			if !ok {
+				err = errors.New(warning)
+			}
+			select {
+			case errs <- err:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return results, errs
+}
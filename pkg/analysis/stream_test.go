@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestStreamResults(t *testing.T) {
+	a := &Analysis{
+		Filters:   []string{"Pod", "Unknown"},
+		Namespace: "default",
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				&v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default"},
+					Status: v1.PodStatus{
+						Phase: v1.PodPending,
+						Conditions: []v1.PodCondition{
+							{
+								Type:    v1.PodScheduled,
+								Reason:  "Unschedulable",
+								Message: "0/1 nodes are available: 1 Insufficient cpu.",
+							},
+						},
+					},
+				},
+			),
+		},
+	}
+
+	results, errs := a.StreamResults(context.Background())
+
+	var streamed []common.Result
+	for result := range results {
+		streamed = append(streamed, result)
+	}
+	var warnings []string
+	for err := range errs {
+		warnings = append(warnings, err.Error())
+	}
+
+	require.Len(t, streamed, 1)
+	require.Equal(t, "default/example", streamed[0].Name)
+	require.Equal(t, streamed, a.Results)
+	require.Equal(t, []string{`"Unknown" filter does not exist. Please run k8sgpt filters list. Available filter groups: networking, workloads.`}, warnings)
+}
+
+func TestStreamResultsCancelled(t *testing.T) {
+	a := &Analysis{
+		Filters: []string{"Pod"},
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(),
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, errs := a.StreamResults(ctx)
+	for range results {
+	}
+	for range errs {
+	}
+}
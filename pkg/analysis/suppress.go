@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+)
+
+const (
+	// IgnoreAnnotation set to "true" on an object drops all of its results.
+	IgnoreAnnotation = "k8sgpt.ai/ignore"
+	// IgnoreAnalyzersAnnotation drops the results of the listed analyzers
+	// (comma separated, e.g. "Pod,Service") for the object.
+	IgnoreAnalyzersAnnotation = "k8sgpt.ai/ignore-analyzers"
+)
+
+// kindInfo is how the objects of a result kind are fetched.
+type kindInfo struct {
+	gvk        schema.GroupVersionKind
+	namespaced bool
+}
+
+// unaddressedResults are the analyzers whose results aren't named after an
+// object of their kind, e.g. the Event analyzer names them after the objects
+// the events are about, so there is no object to read annotations from.
+var unaddressedResults = map[string]bool{
+	"Event": true,
+}
+
+// suppressions looks up the annotations of the objects results are reported
+// for, so their owners can silence accepted issues on the object itself.
+// Lookups are cached for the run, and objects that can't be fetched are
+// never suppressed. Analyzers look up different objects concurrently.
+type suppressions struct {
+	ctx    context.Context
+	client *kubernetes.Client
+
+	kindsOnce sync.Once
+	kinds     map[string]kindInfo
+
+	mu      sync.Mutex
+	objects map[string]*objectAnnotations
+}
+
+// objectAnnotations are the annotations of an object, fetched once.
+type objectAnnotations struct {
+	once        sync.Once
+	annotations map[string]string
+}
+
+func newSuppressions(ctx context.Context, client *kubernetes.Client) *suppressions {
+	return &suppressions{
+		ctx:     ctx,
+		client:  client,
+		objects: map[string]*objectAnnotations{},
+	}
+}
+
+// suppressed reports whether the object of the result opted out of the
+// results of the analyzer.
+func (s *suppressions) suppressed(analyzerName string, result common.Result) bool {
+	if unaddressedResults[analyzerName] {
+		return false
+	}
+	annotations := s.objectAnnotations(result.Kind, result.Name)
+	if strings.EqualFold(annotations[IgnoreAnnotation], "true") {
+		return true
+	}
+	for _, name := range strings.Split(annotations[IgnoreAnalyzersAnnotation], ",") {
+		if strings.TrimSpace(name) == analyzerName {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *suppressions) objectAnnotations(kind string, name string) map[string]string {
+	if s.client == nil || s.client.CtrlClient == nil {
+		return nil
+	}
+
+	s.kindsOnce.Do(func() {
+		s.kinds = discoverKinds(s.client.GetClient().Discovery())
+	})
+	info, ok := s.kinds[kind]
+	if !ok {
+		return nil
+	}
+
+	// Results of namespaced kinds are named namespace/name, those of cluster
+	// scoped kinds name; others don't name an object of their kind.
+	namespace, objectName, found := strings.Cut(name, "/")
+	if found != info.namespaced || strings.Contains(objectName, "/") {
+		return nil
+	}
+	objectKey := types.NamespacedName{Name: name}
+	if found {
+		objectKey = types.NamespacedName{Namespace: namespace, Name: objectName}
+	}
+
+	key := kind + "/" + name
+	s.mu.Lock()
+	object, ok := s.objects[key]
+	if !ok {
+		object = &objectAnnotations{}
+		s.objects[key] = object
+	}
+	s.mu.Unlock()
+
+	object.once.Do(func() {
+		metadata := &metav1.PartialObjectMetadata{}
+		metadata.SetGroupVersionKind(info.gvk)
+		if err := s.client.CtrlClient.Get(s.ctx, objectKey, metadata); err == nil {
+			object.annotations = metadata.Annotations
+		}
+	})
+	return object.annotations
+}
+
+// discoverKinds maps the kinds served by the cluster to their preferred
+// version. Core kinds win over kinds of the same name in other groups.
+func discoverKinds(client discovery.DiscoveryInterface) map[string]kindInfo {
+	kinds := map[string]kindInfo{}
+	lists, _ := discovery.ServerPreferredResources(client)
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range list.APIResources {
+			if strings.Contains(resource.Name, "/") {
+				continue
+			}
+			if existing, ok := kinds[resource.Kind]; ok && existing.gvk.Group == "" {
+				continue
+			}
+			kinds[resource.Kind] = kindInfo{gvk: gv.WithKind(resource.Kind), namespaced: resource.Namespaced}
+		}
+	}
+	return kinds
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSuppressions(t *testing.T) {
+	pod := func(name string, annotations map[string]string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations}}
+	}
+
+	clientset := fake.NewSimpleClientset()
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: metav1.Verbs{"get", "list"}},
+			},
+		},
+	}
+	client := &kubernetes.Client{
+		Client: clientset,
+		CtrlClient: fakeclient.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			pod("ignored", map[string]string{IgnoreAnnotation: "true"}),
+			pod("ignored-by-log", map[string]string{IgnoreAnalyzersAnnotation: "Log, Service"}),
+			pod("reported", nil),
+		).Build(),
+	}
+
+	a := Analysis{
+		Context:      context.Background(),
+		Client:       client,
+		suppressions: newSuppressions(context.Background(), client),
+	}
+	var results []common.Result
+	for _, name := range []string{"ignored", "ignored-by-log", "reported", "missing"} {
+		results = append(results, common.Result{Kind: "Pod", Name: "default/" + name})
+	}
+	results = append(results,
+		common.Result{Kind: "Unknown", Name: "default/ignored"},
+		// not the namespace/name of a Pod
+		common.Result{Kind: "Pod", Name: "ignored"},
+	)
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	for _, analyzerName := range []string{"Pod", "Log", "Event"} {
+		wg.Add(1)
+		semaphore := make(chan struct{}, 1)
+		semaphore <- struct{}{}
+		a.executeAnalyzer(stubAnalyzer{results: results}, analyzerName, common.Analyzer{}, semaphore, &wg, &mutex)
+	}
+
+	var names []string
+	for _, result := range a.Results {
+		names = append(names, result.Kind+" "+result.Name)
+	}
+	require.Equal(t, []string{
+		// Pod analyzer
+		"Pod default/ignored-by-log",
+		"Pod default/reported",
+		"Pod default/missing",
+		"Unknown default/ignored",
+		"Pod ignored",
+		// Log analyzer
+		"Pod default/reported",
+		"Pod default/missing",
+		"Unknown default/ignored",
+		"Pod ignored",
+		// Event analyzer, whose results are named after the objects the
+		// events are about
+		"Pod default/ignored",
+		"Pod default/ignored-by-log",
+		"Pod default/reported",
+		"Pod default/missing",
+		"Unknown default/ignored",
+		"Pod ignored",
+	}, names)
+}
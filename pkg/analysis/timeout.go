@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+)
+
+// Used when analyzer_timeout is not configured.
+const defaultAnalyzerTimeout = 30 * time.Second
+
+// analyzeWithTimeout runs the analyzer with a context cancelled after
+// AnalyzerTimeout. An analyzer still running then, for instance because a
+// call ignores the context, is abandoned so it can't hang the analysis, and
+// a timeout error is returned instead of its results.
+func (a *Analysis) analyzeWithTimeout(analyzer common.IAnalyzer, analyzerConfig common.Analyzer) ([]common.Result, error) {
+	timeout := a.AnalyzerTimeout
+	if timeout <= 0 {
+		timeout = defaultAnalyzerTimeout
+	}
+	parent := analyzerConfig.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+	analyzerConfig.Context = ctx
+
+	type outcome struct {
+		results []common.Result
+		err     error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		results, err := analyzer.Analyze(analyzerConfig)
+		done <- outcome{results: results, err: err}
+	}()
+
+	timedOut := fmt.Errorf("%w after %s, its results are missing", ErrAnalyzerTimedOut, timeout)
+	select {
+	case o := <-done:
+		if o.err != nil && errors.Is(o.err, context.DeadlineExceeded) && ctx.Err() != nil {
+			return nil, timedOut
+		}
+		return o.results, o.err
+	case <-ctx.Done():
+		if parent.Err() != nil {
+			return nil, parent.Err()
+		}
+		return nil, timedOut
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+// hangingAnalyzer blocks until its context is done, or forever when it
+// ignores the context.
+type hangingAnalyzer struct {
+	ignoreContext bool
+}
+
+func (h hangingAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+	if h.ignoreContext {
+		select {}
+	}
+	<-a.Context.Done()
+	return nil, a.Context.Err()
+}
+
+func TestAnalyzerTimeout(t *testing.T) {
+	a := &Analysis{
+		Context:         context.Background(),
+		AnalyzerTimeout: 50 * time.Millisecond,
+	}
+	semaphore := make(chan struct{}, 3)
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	analyzers := map[string]common.IAnalyzer{
+		"Fast":    slowAnalyzer{name: "default/web"},
+		"Slow":    hangingAnalyzer{},
+		"Hanging": hangingAnalyzer{ignoreContext: true},
+	}
+	for name, analyzer := range analyzers {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go a.executeAnalyzer(analyzer, name, common.Analyzer{Context: a.Context}, semaphore, &wg, &mutex)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the analysis did not finish after the analyzer timeout")
+	}
+
+	require.Equal(t, []common.Result{{Kind: "Pod", Name: "default/web"}}, a.Results)
+	require.ElementsMatch(t, []string{
+		"[Slow] timed out after 50ms, its results are missing",
+		"[Hanging] timed out after 50ms, its results are missing",
+	}, a.Errors)
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ExplanationValidator cross-checks an AI explanation against the result it
+// explains, or the cluster, and returns a warning for each likely
+// hallucination, such as a reference to an object that doesn't exist.
+type ExplanationValidator interface {
+	Validate(ctx context.Context, result common.Result, explanation string) []string
+}
+
+var (
+	// namespacedNamePattern matches namespace/name references, which is how
+	// results and most explanations refer to objects.
+	namespacedNamePattern = regexp.MustCompile(`(?:^|[\s"'(\x60])([a-z0-9]([-a-z0-9]*[a-z0-9])?)/([a-z0-9]([-a-z0-9.]*[a-z0-9])?)\b`)
+	// namespacePhrasePattern matches phrases like "in the namespace foo".
+	namespacePhrasePattern = regexp.MustCompile(`(?i)\bnamespace\s+["'\x60]?([a-z0-9]([-a-z0-9]*[a-z0-9])?)\b`)
+	// apiVersionPattern matches the version of group/version references like apps/v1.
+	apiVersionPattern = regexp.MustCompile(`^v[0-9]+((alpha|beta)[0-9]+)?$`)
+)
+
+// NamespaceValidator flags explanations that mention namespaces other than
+// the result's which don't exist in the cluster.
+type NamespaceValidator struct {
+	Client kubernetes.Interface
+
+	exists map[string]bool
+}
+
+func (v *NamespaceValidator) Validate(ctx context.Context, result common.Result, explanation string) []string {
+	resultNamespace, _, _ := strings.Cut(result.Name, "/")
+
+	var namespaces []string
+	for _, match := range namespacedNamePattern.FindAllStringSubmatch(explanation, -1) {
+		if !apiVersionPattern.MatchString(match[3]) {
+			namespaces = append(namespaces, match[1])
+		}
+	}
+	for _, match := range namespacePhrasePattern.FindAllStringSubmatch(explanation, -1) {
+		namespaces = append(namespaces, match[1])
+	}
+
+	var warnings []string
+	reported := map[string]bool{}
+	for _, namespace := range namespaces {
+		if namespace == resultNamespace || reported[namespace] || v.namespaceExists(ctx, namespace) {
+			continue
+		}
+		reported[namespace] = true
+		warnings = append(warnings, fmt.Sprintf("the explanation mentions namespace %s, which does not exist in the cluster", namespace))
+	}
+	return warnings
+}
+
+// namespaceExists reports whether the namespace exists. Lookup errors other
+// than not found count as existing, so they don't raise false warnings.
+func (v *NamespaceValidator) namespaceExists(ctx context.Context, namespace string) bool {
+	if v.exists == nil {
+		v.exists = map[string]bool{}
+	}
+	if exists, ok := v.exists[namespace]; ok {
+		return exists
+	}
+	_, err := v.Client.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	exists := !apierrors.IsNotFound(err)
+	v.exists[namespace] = exists
+	return exists
+}
+
+// validateExplanation runs the explanation validators on the result and
+// returns it with their warnings.
+func (a *Analysis) validateExplanation(result common.Result) common.Result {
+	for _, validator := range a.ExplanationValidators {
+		result.Warnings = append(result.Warnings, validator.Validate(a.Context, result, result.Details)...)
+	}
+	return result
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/cache"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNamespaceValidator(t *testing.T) {
+	validator := &NamespaceValidator{
+		Client: fake.NewSimpleClientset(
+			&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+			&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+		),
+	}
+	result := common.Result{Kind: "Service", Name: "shop/web"}
+	explanation := "Error: Service shop/web has no endpoints. Solution: " +
+		"1. Check the apps/v1 Deployment staging/api and kube-system/coredns. " +
+		"2. Compare with the namespace prod and the namespace default. " +
+		"3. See https://kubernetes.io/docs/concepts/services-networking/service/ and staging/api again."
+
+	require.Equal(t, []string{
+		"the explanation mentions namespace staging, which does not exist in the cluster",
+		"the explanation mentions namespace prod, which does not exist in the cluster",
+	}, validator.Validate(context.Background(), result, explanation))
+}
+
+// mentionValidator flags explanations that mention a word.
+type mentionValidator string
+
+func (v mentionValidator) Validate(_ context.Context, _ common.Result, explanation string) []string {
+	if !strings.Contains(explanation, string(v)) {
+		return nil
+	}
+	return []string{"mentions " + string(v)}
+}
+
+func TestGetAIResultsValidatesExplanations(t *testing.T) {
+	disabledCache := cache.New("disabled-cache")
+	disabledCache.DisableCache()
+	a := Analysis{
+		Context:  context.Background(),
+		AIClient: &ai.NoOpAIClient{},
+		Cache:    disabledCache,
+		Language: "English",
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/web", Error: []common.Failure{{Text: "Back-off pulling image nginx:lates"}}},
+			{Kind: "Pod", Name: "default/api", Error: []common.Failure{{Text: "the last termination reason is OOMKilled"}}},
+		},
+		ExplanationValidators: []ExplanationValidator{mentionValidator("nginx")},
+	}
+
+	require.NoError(t, a.GetAIResults("json", false))
+	// The noop provider echoes the prompt, so only the first explanation mentions nginx.
+	require.Equal(t, []string{"mentions nginx"}, a.Results[0].Warnings)
+	require.Empty(t, a.Results[1].Warnings)
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"fmt"
+
+	regv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k "k8s.io/client-go/kubernetes"
+)
+
+// blockingWebhookWarnings returns a warning for each webhook with
+// failurePolicy Fail whose service has no ready endpoints. The API server
+// rejects every write such a webhook intercepts, so pods and other objects
+// can't be created and the findings of the analyzers may only be symptoms.
+// Lookup errors (e.g. missing RBAC) are not reported.
+func blockingWebhookWarnings(ctx context.Context, client k.Interface) []string {
+	type webhook struct {
+		kind          string
+		name          string
+		failurePolicy *regv1.FailurePolicyType
+		service       *regv1.ServiceReference
+	}
+	var webhooks []webhook
+
+	if list, err := client.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{}); err == nil {
+		for _, config := range list.Items {
+			for _, w := range config.Webhooks {
+				webhooks = append(webhooks, webhook{"ValidatingWebhookConfiguration", config.Name + "/" + w.Name, w.FailurePolicy, w.ClientConfig.Service})
+			}
+		}
+	}
+	if list, err := client.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{}); err == nil {
+		for _, config := range list.Items {
+			for _, w := range config.Webhooks {
+				webhooks = append(webhooks, webhook{"MutatingWebhookConfiguration", config.Name + "/" + w.Name, w.FailurePolicy, w.ClientConfig.Service})
+			}
+		}
+	}
+
+	var warnings []string
+	for _, w := range webhooks {
+		// failurePolicy defaults to Fail in admissionregistration/v1.
+		if w.service == nil || (w.failurePolicy != nil && *w.failurePolicy != regv1.Fail) {
+			continue
+		}
+		endpoints, err := client.CoreV1().Endpoints(w.service.Namespace).Get(ctx, w.service.Name, metav1.GetOptions{})
+		if err != nil {
+			continue
+		}
+		ready := false
+		for _, subset := range endpoints.Subsets {
+			if len(subset.Addresses) > 0 {
+				ready = true
+				break
+			}
+		}
+		if !ready {
+			warnings = append(warnings, fmt.Sprintf("[AdmissionWebhook] %s %s has failurePolicy Fail but service %s/%s has no ready endpoints: cluster writes it intercepts are blocked, so other results may be symptoms of this",
+				w.kind, w.name, w.service.Namespace, w.service.Name))
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	regv1 "k8s.io/api/admissionregistration/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestBlockingWebhookWarnings(t *testing.T) {
+	ignore := regv1.Ignore
+	service := func(name string) regv1.WebhookClientConfig {
+		return regv1.WebhookClientConfig{
+			Service: &regv1.ServiceReference{Namespace: "webhooks", Name: name},
+		}
+	}
+	endpoints := func(name string, ready bool) *v1.Endpoints {
+		ep := &v1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "webhooks"}}
+		if ready {
+			ep.Subsets = []v1.EndpointSubset{{Addresses: []v1.EndpointAddress{{IP: "10.0.0.1"}}}}
+		} else {
+			ep.Subsets = []v1.EndpointSubset{{NotReadyAddresses: []v1.EndpointAddress{{IP: "10.0.0.2"}}}}
+		}
+		return ep
+	}
+
+	client := fake.NewSimpleClientset(
+		endpoints("down", false),
+		endpoints("up", true),
+		&regv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+			Webhooks: []regv1.ValidatingWebhook{
+				// failurePolicy defaults to Fail.
+				{Name: "validate.policy.io", ClientConfig: service("down")},
+				{Name: "audit.policy.io", ClientConfig: service("down"), FailurePolicy: &ignore},
+				{Name: "healthy.policy.io", ClientConfig: service("up")},
+			},
+		},
+		&regv1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "injector"},
+			Webhooks: []regv1.MutatingWebhook{
+				// Services that can't be found are reported by the webhook analyzers.
+				{Name: "inject.mesh.io", ClientConfig: service("missing")},
+			},
+		},
+	)
+
+	require.Equal(t, []string{
+		"[AdmissionWebhook] ValidatingWebhookConfiguration policy/validate.policy.io has failurePolicy Fail but service webhooks/down has no ready endpoints: cluster writes it intercepts are blocked, so other results may be symptoms of this",
+	}, blockingWebhookWarnings(context.Background(), client))
+}
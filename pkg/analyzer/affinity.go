@@ -0,0 +1,382 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// affinityLookup gives the affinity checks access to the cluster state they
+// compare a pending pod against. Each function lists lazily and returns nil
+// when listing fails.
+type affinityLookup struct {
+	nodes      func() []v1.Node
+	pods       func(namespace string) []v1.Pod
+	namespaces func() []v1.Namespace
+}
+
+// analyzeAffinityConflicts explains which required scheduling rule of a pending
+// pod can't be satisfied: a nodeSelector or node affinity no node matches, a
+// podAffinity no placed pod satisfies, or a podAntiAffinity that rules out
+// every node. Preferred rules never block scheduling and are ignored.
+func analyzeAffinityConflicts(pod v1.Pod, lookup affinityLookup) []common.Failure {
+	nodes := lookup.nodes()
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	var texts []string
+	texts = append(texts, nodeSelectorConflicts(pod, nodes)...)
+	if affinity := pod.Spec.Affinity; affinity != nil {
+		if affinity.NodeAffinity != nil && affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+			texts = append(texts, nodeAffinityConflicts(pod, nodes, affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms)...)
+		}
+		if affinity.PodAffinity != nil {
+			for _, term := range affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+				if text, ok := podAffinityConflict(pod, nodes, term, lookup); ok {
+					texts = append(texts, text)
+				}
+			}
+		}
+		if affinity.PodAntiAffinity != nil {
+			for _, term := range affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+				if text, ok := podAntiAffinityConflict(pod, nodes, term, lookup); ok {
+					texts = append(texts, text)
+				}
+			}
+		}
+	}
+
+	var failures []common.Failure
+	for _, text := range texts {
+		failures = append(failures, common.Failure{
+			Text:      text,
+			Sensitive: []common.Sensitive{},
+			Severity:  common.SeverityMedium,
+		})
+	}
+	return failures
+}
+
+func nodeSelectorConflicts(pod v1.Pod, nodes []v1.Node) []string {
+	if len(pod.Spec.NodeSelector) == 0 {
+		return nil
+	}
+	selector := labels.SelectorFromSet(pod.Spec.NodeSelector)
+	for _, node := range nodes {
+		if selector.Matches(labels.Set(node.Labels)) {
+			return nil
+		}
+	}
+
+	keys := make([]string, 0, len(pod.Spec.NodeSelector))
+	for key := range pod.Spec.NodeSelector {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var texts, pairs []string
+	for _, key := range keys {
+		pair := fmt.Sprintf("%s=%s", key, pod.Spec.NodeSelector[key])
+		pairs = append(pairs, pair)
+		if !anyNodeMatches(nodes, labels.SelectorFromSet(labels.Set{key: pod.Spec.NodeSelector[key]})) {
+			texts = append(texts, fmt.Sprintf("pod %s requires label %s in its nodeSelector, but no node has it", pod.Name, pair))
+		}
+	}
+	if len(texts) == 0 {
+		texts = append(texts, fmt.Sprintf("pod %s requires labels %s in its nodeSelector, but no node has all of them", pod.Name, strings.Join(pairs, ", ")))
+	}
+	return texts
+}
+
+// nodeAffinityConflicts reports the required node affinity terms when no node
+// matches any of them, as the terms are ORed.
+func nodeAffinityConflicts(pod v1.Pod, nodes []v1.Node, terms []v1.NodeSelectorTerm) []string {
+	var texts []string
+	for _, term := range terms {
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			// An empty term matches no node.
+			continue
+		}
+		matched := false
+		for _, node := range nodes {
+			if nodeMatchesTerm(node, term) {
+				matched = true
+				break
+			}
+		}
+		if matched {
+			return nil
+		}
+
+		var rules, unmatched []string
+		for _, expression := range term.MatchExpressions {
+			rule := describeNodeRequirement("label", expression)
+			rules = append(rules, rule)
+			if !anyNode(nodes, func(node v1.Node) bool { return nodeMatchesRequirement(node.Labels, expression) }) {
+				unmatched = append(unmatched, rule)
+			}
+		}
+		for _, field := range term.MatchFields {
+			rule := describeNodeRequirement("field", field)
+			rules = append(rules, rule)
+			if !anyNode(nodes, func(node v1.Node) bool { return nodeMatchesRequirement(nodeFields(node), field) }) {
+				unmatched = append(unmatched, rule)
+			}
+		}
+		for _, rule := range unmatched {
+			texts = append(texts, fmt.Sprintf("pod %s requires %s in its node affinity, but no node matches it", pod.Name, rule))
+		}
+		if len(unmatched) == 0 {
+			texts = append(texts, fmt.Sprintf("pod %s requires %s in its node affinity, but no node matches all of them", pod.Name, strings.Join(rules, ", ")))
+		}
+	}
+	return texts
+}
+
+// podAffinityConflict reports a required podAffinity term that no placed pod
+// satisfies. Like the scheduler, a pod matching its own term may be placed
+// first when no other pod matches.
+func podAffinityConflict(pod v1.Pod, nodes []v1.Node, term v1.PodAffinityTerm, lookup affinityLookup) (string, bool) {
+	matching, selector, ok := podsMatchingTerm(pod, term, lookup)
+	if !ok {
+		return "", false
+	}
+	if len(matching) == 0 {
+		if podMatchesOwnTerm(pod, term, selector) {
+			return "", false
+		}
+		return fmt.Sprintf("pod %s requires a pod matching %s %s in its podAffinity, but there is none running", pod.Name, metav1.FormatLabelSelector(term.LabelSelector), describeTermNamespaces(pod, term)), true
+	}
+
+	domains := topologyDomains(nodes, matching, term.TopologyKey)
+	if len(domains) == 0 {
+		return fmt.Sprintf("pod %s requires a pod matching %s in its podAffinity, but the %d matching pod(s) run on nodes without the topology label %s", pod.Name, metav1.FormatLabelSelector(term.LabelSelector), len(matching), term.TopologyKey), true
+	}
+	return "", false
+}
+
+// podAntiAffinityConflict reports a required podAntiAffinity term when every
+// node shares its topology domain with a pod matching the term.
+func podAntiAffinityConflict(pod v1.Pod, nodes []v1.Node, term v1.PodAffinityTerm, lookup affinityLookup) (string, bool) {
+	matching, _, ok := podsMatchingTerm(pod, term, lookup)
+	if !ok || len(matching) == 0 {
+		return "", false
+	}
+
+	domains := topologyDomains(nodes, matching, term.TopologyKey)
+	for _, node := range nodes {
+		value, ok := node.Labels[term.TopologyKey]
+		if !ok || !domains[value] {
+			return "", false
+		}
+	}
+	return fmt.Sprintf("pod %s must not share a %s with a pod matching %s in its podAntiAffinity, but all %d %s domain(s) already run one", pod.Name, term.TopologyKey, metav1.FormatLabelSelector(term.LabelSelector), len(domains), term.TopologyKey), true
+}
+
+// podsMatchingTerm returns the pods placed on a node that match an affinity
+// term. It reports false when the term or the cluster can't be evaluated.
+func podsMatchingTerm(pod v1.Pod, term v1.PodAffinityTerm, lookup affinityLookup) ([]v1.Pod, labels.Selector, bool) {
+	if term.LabelSelector == nil {
+		return nil, nil, false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(term.LabelSelector)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	namespaces, ok := termNamespaces(pod, term, lookup)
+	if !ok {
+		return nil, nil, false
+	}
+
+	var matching []v1.Pod
+	for _, namespace := range namespaces {
+		pods := lookup.pods(namespace)
+		if pods == nil {
+			return nil, nil, false
+		}
+		for _, candidate := range pods {
+			if candidate.Spec.NodeName == "" || candidate.Status.Phase == v1.PodSucceeded || candidate.Status.Phase == v1.PodFailed {
+				continue
+			}
+			if selector.Matches(labels.Set(candidate.Labels)) {
+				matching = append(matching, candidate)
+			}
+		}
+	}
+	return matching, selector, true
+}
+
+// termNamespaces resolves the namespaces an affinity term applies to: the
+// listed ones plus those matching its namespaceSelector, or the pod's own
+// namespace when neither is set.
+func termNamespaces(pod v1.Pod, term v1.PodAffinityTerm, lookup affinityLookup) ([]string, bool) {
+	if len(term.Namespaces) == 0 && term.NamespaceSelector == nil {
+		return []string{pod.Namespace}, true
+	}
+
+	set := map[string]bool{}
+	for _, namespace := range term.Namespaces {
+		set[namespace] = true
+	}
+	if term.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(term.NamespaceSelector)
+		if err != nil {
+			return nil, false
+		}
+		namespaces := lookup.namespaces()
+		if namespaces == nil {
+			return nil, false
+		}
+		for _, namespace := range namespaces {
+			if selector.Matches(labels.Set(namespace.Labels)) {
+				set[namespace.Name] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, true
+}
+
+func podMatchesOwnTerm(pod v1.Pod, term v1.PodAffinityTerm, selector labels.Selector) bool {
+	if !selector.Matches(labels.Set(pod.Labels)) {
+		return false
+	}
+	if len(term.Namespaces) == 0 && term.NamespaceSelector == nil {
+		return true
+	}
+	for _, namespace := range term.Namespaces {
+		if namespace == pod.Namespace {
+			return true
+		}
+	}
+	// The pod's namespace may match the namespaceSelector; give it the
+	// benefit of the doubt rather than list the namespace again.
+	return term.NamespaceSelector != nil
+}
+
+func describeTermNamespaces(pod v1.Pod, term v1.PodAffinityTerm) string {
+	if len(term.Namespaces) == 0 && term.NamespaceSelector == nil {
+		return fmt.Sprintf("in namespace %s", pod.Namespace)
+	}
+	var scopes []string
+	if len(term.Namespaces) > 0 {
+		scopes = append(scopes, fmt.Sprintf("namespaces %s", strings.Join(term.Namespaces, ", ")))
+	}
+	if term.NamespaceSelector != nil {
+		scopes = append(scopes, fmt.Sprintf("namespaces matching %s", metav1.FormatLabelSelector(term.NamespaceSelector)))
+	}
+	return "in " + strings.Join(scopes, " or ")
+}
+
+// topologyDomains returns the values of the topology label on the nodes that
+// run the given pods.
+func topologyDomains(nodes []v1.Node, pods []v1.Pod, topologyKey string) map[string]bool {
+	nodeLabels := map[string]map[string]string{}
+	for _, node := range nodes {
+		nodeLabels[node.Name] = node.Labels
+	}
+	domains := map[string]bool{}
+	for _, pod := range pods {
+		if value, ok := nodeLabels[pod.Spec.NodeName][topologyKey]; ok {
+			domains[value] = true
+		}
+	}
+	return domains
+}
+
+func nodeMatchesTerm(node v1.Node, term v1.NodeSelectorTerm) bool {
+	for _, expression := range term.MatchExpressions {
+		if !nodeMatchesRequirement(node.Labels, expression) {
+			return false
+		}
+	}
+	fields := nodeFields(node)
+	for _, field := range term.MatchFields {
+		if !nodeMatchesRequirement(fields, field) {
+			return false
+		}
+	}
+	return true
+}
+
+// nodeFields holds the only field node affinity can select on.
+func nodeFields(node v1.Node) map[string]string {
+	return map[string]string{"metadata.name": node.Name}
+}
+
+func nodeMatchesRequirement(values map[string]string, requirement v1.NodeSelectorRequirement) bool {
+	operators := map[v1.NodeSelectorOperator]selection.Operator{
+		v1.NodeSelectorOpIn:           selection.In,
+		v1.NodeSelectorOpNotIn:        selection.NotIn,
+		v1.NodeSelectorOpExists:       selection.Exists,
+		v1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+		v1.NodeSelectorOpGt:           selection.GreaterThan,
+		v1.NodeSelectorOpLt:           selection.LessThan,
+	}
+	operator, ok := operators[requirement.Operator]
+	if !ok {
+		return false
+	}
+	r, err := labels.NewRequirement(requirement.Key, operator, requirement.Values)
+	if err != nil {
+		return false
+	}
+	return r.Matches(labels.Set(values))
+}
+
+func describeNodeRequirement(subject string, requirement v1.NodeSelectorRequirement) string {
+	switch requirement.Operator {
+	case v1.NodeSelectorOpIn:
+		if len(requirement.Values) == 1 {
+			return fmt.Sprintf("%s %s=%s", subject, requirement.Key, requirement.Values[0])
+		}
+		return fmt.Sprintf("%s %s in (%s)", subject, requirement.Key, strings.Join(requirement.Values, ", "))
+	case v1.NodeSelectorOpNotIn:
+		return fmt.Sprintf("%s %s not in (%s)", subject, requirement.Key, strings.Join(requirement.Values, ", "))
+	case v1.NodeSelectorOpExists:
+		return fmt.Sprintf("%s %s to exist", subject, requirement.Key)
+	case v1.NodeSelectorOpDoesNotExist:
+		return fmt.Sprintf("%s %s to be absent", subject, requirement.Key)
+	default:
+		return fmt.Sprintf("%s %s %s %s", subject, requirement.Key, requirement.Operator, strings.Join(requirement.Values, ", "))
+	}
+}
+
+func anyNodeMatches(nodes []v1.Node, selector labels.Selector) bool {
+	return anyNode(nodes, func(node v1.Node) bool { return selector.Matches(labels.Set(node.Labels)) })
+}
+
+func anyNode(nodes []v1.Node, match func(v1.Node) bool) bool {
+	for _, node := range nodes {
+		if match(node) {
+			return true
+		}
+	}
+	return false
+}
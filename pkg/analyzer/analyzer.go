@@ -16,6 +16,7 @@ package analyzer
 import (
 	"fmt"
 	"os"
+	"sync"
 
 	"github.com/fatih/color"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
@@ -29,6 +30,19 @@ var (
 		Name: "analyzer_errors",
 		Help: "Number of errors detected by analyzer",
 	}, []string{"analyzer_name", "object_name", "namespace"})
+	AnalyzerDurationMetric = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "analyzer_duration_seconds",
+		Help:    "Duration of the runs of each analyzer",
+		Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"analyzer_name"})
+	AICacheHitsMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ai_cache_hits_total",
+		Help: "Number of AI explanations served from the cache",
+	}, []string{"backend"})
+	AICacheMissesMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ai_cache_misses_total",
+		Help: "Number of AI explanations not found in the cache and requested from the backend",
+	}, []string{"backend"})
 )
 
 var coreAnalyzerMap = map[string]common.IAnalyzer{
@@ -46,13 +60,82 @@ var coreAnalyzerMap = map[string]common.IAnalyzer{
 }
 
 var additionalAnalyzerMap = map[string]common.IAnalyzer{
-	"HorizontalPodAutoScaler": HpaAnalyzer{},
-	"PodDisruptionBudget":     PdbAnalyzer{},
-	"NetworkPolicy":           NetworkPolicyAnalyzer{},
-	"Log":                     LogAnalyzer{},
-	"GatewayClass":            GatewayClassAnalyzer{},
-	"Gateway":                 GatewayAnalyzer{},
-	"HTTPRoute":               HTTPRouteAnalyzer{},
+	"HorizontalPodAutoScaler":   HpaAnalyzer{},
+	"PodDisruptionBudget":       PdbAnalyzer{},
+	"NetworkPolicy":             NetworkPolicyAnalyzer{},
+	"Log":                       LogAnalyzer{},
+	"GatewayClass":              GatewayClassAnalyzer{},
+	"Gateway":                   GatewayAnalyzer{},
+	"HTTPRoute":                 HTTPRouteAnalyzer{},
+	"CertificateSigningRequest": CertificateSigningRequestAnalyzer{},
+	"RestartStorm":              RestartStormAnalyzer{},
+	"HighAvailability":          HighAvailabilityAnalyzer{},
+	"Finalizer":                 FinalizerAnalyzer{},
+	"Job":                       JobAnalyzer{},
+	"Secret":                    SecretAnalyzer{},
+	"ConfigMap":                 ConfigMapAnalyzer{},
+	"PersistentVolume":          PersistentVolumeAnalyzer{},
+	"StorageClass":              StorageClassAnalyzer{},
+	"ResourceQuota":             ResourceQuotaAnalyzer{},
+	"LimitRange":                LimitRangeAnalyzer{},
+	"Endpoints":                 EndpointsAnalyzer{},
+	"DaemonSet":                 DaemonSetAnalyzer{},
+	"IngressClass":              IngressClassAnalyzer{},
+	"PriorityClass":             PriorityClassAnalyzer{},
+	"ServiceAccount":            ServiceAccountAnalyzer{},
+	"Event":                     EventAnalyzer{},
+	"VolumeAttachment":          VolumeAttachmentAnalyzer{},
+	"CustomResource":            CustomResourceAnalyzer{},
+}
+
+// analyzerErrorsSeries holds the objects of the series of each analyzer in
+// AnalyzerErrorsMetric, so those no longer failing can be deleted.
+var (
+	analyzerErrorsMutex  sync.Mutex
+	analyzerErrorsSeries = map[string]map[analyzerErrorsObject]bool{}
+)
+
+type analyzerErrorsObject struct {
+	name      string
+	namespace string
+}
+
+// analyzerErrorsSnapshot collects the number of failures of each object an
+// analyzer finds during a run, applied to AnalyzerErrorsMetric at once by
+// Apply. The gauges of an analyzer thus always reflect a complete run, even
+// when runs of analyzers overlap.
+type analyzerErrorsSnapshot struct {
+	analyzer string
+	counts   map[analyzerErrorsObject]float64
+}
+
+func newAnalyzerErrorsSnapshot(analyzer string) *analyzerErrorsSnapshot {
+	return &analyzerErrorsSnapshot{analyzer: analyzer, counts: map[analyzerErrorsObject]float64{}}
+}
+
+// Set records the number of failures of an object.
+func (s *analyzerErrorsSnapshot) Set(name string, namespace string, count float64) {
+	s.counts[analyzerErrorsObject{name: name, namespace: namespace}] = count
+}
+
+// Apply replaces the series of the analyzer by those of the snapshot,
+// deleting the series of the objects that no longer fail. It is called once
+// the analyzer succeeds, so a failed run leaves the previous series.
+func (s *analyzerErrorsSnapshot) Apply() {
+	analyzerErrorsMutex.Lock()
+	defer analyzerErrorsMutex.Unlock()
+
+	for object := range analyzerErrorsSeries[s.analyzer] {
+		if _, ok := s.counts[object]; !ok {
+			AnalyzerErrorsMetric.DeleteLabelValues(s.analyzer, object.name, object.namespace)
+		}
+	}
+	series := make(map[analyzerErrorsObject]bool, len(s.counts))
+	for object, count := range s.counts {
+		AnalyzerErrorsMetric.WithLabelValues(s.analyzer, object.name, object.namespace).Set(count)
+		series[object] = true
+	}
+	analyzerErrorsSeries[s.analyzer] = series
 }
 
 func ListFilters() ([]string, []string, []string) {
@@ -118,5 +201,14 @@ func GetAnalyzerMap() (map[string]common.IAnalyzer, map[string]common.IAnalyzer)
 		}
 	}
 
+	plugins, err := PluginAnalyzers()
+	if err != nil {
+		fmt.Println(color.RedString(err.Error()))
+		os.Exit(1)
+	}
+	for _, plugin := range plugins {
+		mergedAnalyzerMap[plugin.Name] = plugin
+	}
+
 	return coreAnalyzer, mergedAnalyzerMap
 }
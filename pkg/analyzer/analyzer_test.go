@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAnalyzerErrorsSnapshot(t *testing.T) {
+	series := testutil.CollectAndCount(AnalyzerErrorsMetric)
+
+	first := newAnalyzerErrorsSnapshot("SnapshotTest")
+	first.Set("web", "default", 2)
+	first.Set("api", "default", 1)
+	// Nothing is set until the snapshot is applied.
+	require.Equal(t, series, testutil.CollectAndCount(AnalyzerErrorsMetric))
+	first.Apply()
+	require.Equal(t, series+2, testutil.CollectAndCount(AnalyzerErrorsMetric))
+
+	second := newAnalyzerErrorsSnapshot("SnapshotTest")
+	second.Set("web", "default", 3)
+	second.Apply()
+	require.Equal(t, series+1, testutil.CollectAndCount(AnalyzerErrorsMetric))
+	require.Equal(t, float64(3), testutil.ToFloat64(AnalyzerErrorsMetric.WithLabelValues("SnapshotTest", "web", "default")))
+
+	// A run that isn't applied, e.g. as it failed, leaves the series.
+	newAnalyzerErrorsSnapshot("SnapshotTest").Set("db", "default", 1)
+	require.Equal(t, series+1, testutil.CollectAndCount(AnalyzerErrorsMetric))
+
+	newAnalyzerErrorsSnapshot("SnapshotTest").Apply()
+	require.Equal(t, series, testutil.CollectAndCount(AnalyzerErrorsMetric))
+}
+
+func TestAnalyzerErrorsStaleSeries(t *testing.T) {
+	crashLooping := func(name string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "metrics"},
+			Status: v1.PodStatus{
+				Phase: v1.PodRunning,
+				ContainerStatuses: []v1.ContainerStatus{
+					{
+						Name:                 "app",
+						State:                v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+						LastTerminationState: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Reason: "Error"}},
+					},
+				},
+			},
+		}
+	}
+	analyze := func(pods ...*v1.Pod) {
+		clientset := fake.NewSimpleClientset()
+		for _, pod := range pods {
+			require.NoError(t, clientset.Tracker().Add(pod))
+		}
+		_, err := PodAnalyzer{}.Analyze(common.Analyzer{
+			Client:    &kubernetes.Client{Client: clientset},
+			Context:   context.Background(),
+			Namespace: "metrics",
+		})
+		require.NoError(t, err)
+	}
+
+	analyze(crashLooping("web"), crashLooping("api"))
+	require.Equal(t, float64(1), testutil.ToFloat64(AnalyzerErrorsMetric.WithLabelValues("Pod", "api", "metrics")))
+
+	// Once the api pod recovers, its series is removed.
+	analyze(crashLooping("web"))
+	require.False(t, AnalyzerErrorsMetric.DeleteLabelValues("Pod", "api", "metrics"))
+	require.True(t, AnalyzerErrorsMetric.DeleteLabelValues("Pod", "web", "metrics"))
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConfigMapAnalyzer reports Pods and Deployments referencing ConfigMaps, or
+// ConfigMap keys, that don't exist, before their containers fail to start.
+// Results are reported for the consuming workload. Pods managed by an
+// analyzed Deployment are left to their Deployment.
+type ConfigMapAnalyzer struct{}
+
+// configMapRef is a reference of a pod to a ConfigMap, or to one of its
+// keys when key is set.
+type configMapRef struct {
+	name string
+	key  string
+	// source is where the reference is made, e.g. "volume config".
+	source string
+}
+
+func (ConfigMapAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	analyzerName := "ConfigMap"
+
+	snapshot := newAnalyzerErrorsSnapshot(analyzerName)
+
+	configMapList, err := a.Client.GetClient().CoreV1().ConfigMaps(a.Namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	configMapKeys := map[string]map[string]bool{}
+	for _, cm := range configMapList.Items {
+		keys := map[string]bool{}
+		for key := range cm.Data {
+			keys[key] = true
+		}
+		for key := range cm.BinaryData {
+			keys[key] = true
+		}
+		configMapKeys[cm.Namespace+"/"+cm.Name] = keys
+	}
+
+	deployments, err := a.Client.GetClient().AppsV1().Deployments(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector})
+	if err != nil {
+		return nil, err
+	}
+	analyzedDeployments := map[string]bool{}
+	for _, deployment := range deployments.Items {
+		if util.CreatedWithin(deployment.ObjectMeta, a.MinAge) {
+			continue
+		}
+		analyzedDeployments[deployment.Namespace+"/Deployment/"+deployment.Name] = true
+		failures := danglingConfigMapRefs(deployment.Namespace, deployment.Spec.Template.Spec, configMapKeys)
+		if len(failures) > 0 {
+			a.Results = append(a.Results, common.Result{
+				Kind:  "Deployment",
+				Name:  fmt.Sprintf("%s/%s", deployment.Namespace, deployment.Name),
+				Error: failures,
+			})
+			snapshot.Set(deployment.Name, deployment.Namespace, float64(len(failures)))
+		}
+	}
+
+	pods, err := a.Client.GetClient().CoreV1().Pods(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector})
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods.Items {
+		if util.CreatedWithin(pod.ObjectMeta, a.MinAge) {
+			continue
+		}
+		failures := danglingConfigMapRefs(pod.Namespace, pod.Spec, configMapKeys)
+		if len(failures) == 0 {
+			continue
+		}
+		result := common.Result{
+			Kind:  "Pod",
+			Name:  fmt.Sprintf("%s/%s", pod.Namespace, pod.Name),
+			Error: failures,
+		}
+		if parent, found := util.GetParent(a.Client, pod.ObjectMeta); found {
+			if analyzedDeployments[pod.Namespace+"/"+parent] {
+				continue
+			}
+			result.ParentObject = parent
+		}
+		a.Results = append(a.Results, result)
+		snapshot.Set(pod.Name, pod.Namespace, float64(len(failures)))
+	}
+
+	snapshot.Apply()
+	return a.Results, nil
+}
+
+// danglingConfigMapRefs reports the required references of the pod spec to
+// ConfigMaps or keys missing from configMapKeys.
+func danglingConfigMapRefs(namespace string, spec v1.PodSpec, configMapKeys map[string]map[string]bool) []common.Failure {
+	var failures []common.Failure
+	reported := map[configMapRef]bool{}
+	for _, ref := range requiredConfigMapRefs(spec) {
+		keys, exists := configMapKeys[namespace+"/"+ref.name]
+		var text string
+		switch {
+		case !exists:
+			text = fmt.Sprintf("ConfigMap %s used by %s does not exist", ref.name, ref.source)
+		case ref.key != "" && !keys[ref.key]:
+			text = fmt.Sprintf("ConfigMap %s has no key %s used by %s", ref.name, ref.key, ref.source)
+		default:
+			continue
+		}
+		// A missing ConfigMap is reported once per place it is used.
+		dedup := configMapRef{name: ref.name, source: ref.source}
+		if exists {
+			dedup.key = ref.key
+		}
+		if reported[dedup] {
+			continue
+		}
+		reported[dedup] = true
+
+		failures = append(failures, common.Failure{
+			Text: text,
+			Sensitive: []common.Sensitive{
+				{
+					Unmasked: ref.name,
+					Masked:   util.MaskString(ref.name),
+				},
+			},
+			Severity: common.SeverityHigh,
+		})
+	}
+	return failures
+}
+
+// requiredConfigMapRefs returns the references of the pod spec to
+// ConfigMaps it can't start without. Optional references are left out.
+func requiredConfigMapRefs(spec v1.PodSpec) []configMapRef {
+	var refs []configMapRef
+	addProjection := func(projection *v1.ConfigMapProjection, source string) {
+		if isOptional(projection.Optional) {
+			return
+		}
+		if len(projection.Items) == 0 {
+			refs = append(refs, configMapRef{name: projection.Name, source: source})
+		}
+		for _, item := range projection.Items {
+			refs = append(refs, configMapRef{name: projection.Name, key: item.Key, source: source})
+		}
+	}
+	for _, volume := range spec.Volumes {
+		source := "volume " + volume.Name
+		if cm := volume.ConfigMap; cm != nil {
+			addProjection(&v1.ConfigMapProjection{LocalObjectReference: cm.LocalObjectReference, Items: cm.Items, Optional: cm.Optional}, source)
+		}
+		if volume.Projected != nil {
+			for _, projection := range volume.Projected.Sources {
+				if projection.ConfigMap != nil {
+					addProjection(projection.ConfigMap, source)
+				}
+			}
+		}
+	}
+	for _, container := range append(append([]v1.Container{}, spec.InitContainers...), spec.Containers...) {
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.ConfigMapRef != nil && !isOptional(envFrom.ConfigMapRef.Optional) {
+				refs = append(refs, configMapRef{name: envFrom.ConfigMapRef.Name, source: "envFrom of container " + container.Name})
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom == nil || env.ValueFrom.ConfigMapKeyRef == nil || isOptional(env.ValueFrom.ConfigMapKeyRef.Optional) {
+				continue
+			}
+			ref := env.ValueFrom.ConfigMapKeyRef
+			refs = append(refs, configMapRef{
+				name:   ref.Name,
+				key:    ref.Key,
+				source: fmt.Sprintf("env %s of container %s", env.Name, container.Name),
+			})
+		}
+	}
+	return refs
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestConfigMapAnalyzer(t *testing.T) {
+	optional := true
+	webSpec := v1.PodSpec{
+		Containers: []v1.Container{
+			{
+				Name: "web",
+				EnvFrom: []v1.EnvFromSource{
+					{ConfigMapRef: &v1.ConfigMapEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: "web-env"}}},
+				},
+				Env: []v1.EnvVar{
+					{Name: "LOG_LEVEL", ValueFrom: &v1.EnvVarSource{ConfigMapKeyRef: &v1.ConfigMapKeySelector{
+						LocalObjectReference: v1.LocalObjectReference{Name: "settings"},
+						Key:                  "log-level",
+					}}},
+					{Name: "PORT", ValueFrom: &v1.EnvVarSource{ConfigMapKeyRef: &v1.ConfigMapKeySelector{
+						LocalObjectReference: v1.LocalObjectReference{Name: "settings"},
+						Key:                  "port",
+					}}},
+				},
+			},
+		},
+		Volumes: []v1.Volume{
+			// Optional ConfigMaps may be missing.
+			{Name: "extra", VolumeSource: v1.VolumeSource{ConfigMap: &v1.ConfigMapVolumeSource{
+				LocalObjectReference: v1.LocalObjectReference{Name: "extra"},
+				Optional:             &optional,
+			}}},
+		},
+	}
+
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				&v1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "default"},
+					Data:       map[string]string{"port": "8080"},
+				},
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+					Spec: appsv1.DeploymentSpec{
+						Template: v1.PodTemplateSpec{Spec: webSpec},
+					},
+				},
+				&appsv1.ReplicaSet{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            "web-5d4f8",
+						Namespace:       "default",
+						OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web"}},
+					},
+				},
+				&v1.Pod{
+					// Reported through its Deployment.
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            "web-5d4f8-x2k9p",
+						Namespace:       "default",
+						OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-5d4f8"}},
+					},
+					Spec: webSpec,
+				},
+				&v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: "default"},
+					Spec: v1.PodSpec{
+						Volumes: []v1.Volume{
+							{Name: "config", VolumeSource: v1.VolumeSource{ConfigMap: &v1.ConfigMapVolumeSource{
+								LocalObjectReference: v1.LocalObjectReference{Name: "settings"},
+								Items:                []v1.KeyToPath{{Key: "port", Path: "port"}, {Key: "host", Path: "host"}},
+							}}},
+						},
+					},
+				},
+			),
+		},
+		Context:   context.Background(),
+		Namespace: "default",
+	}
+
+	results, err := ConfigMapAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Kind < results[j].Kind
+	})
+
+	require.Len(t, results, 2)
+
+	require.Equal(t, "Deployment", results[0].Kind)
+	require.Equal(t, "default/web", results[0].Name)
+	var texts []string
+	for _, failure := range results[0].Error {
+		texts = append(texts, failure.Text)
+	}
+	require.Equal(t, []string{
+		"ConfigMap web-env used by envFrom of container web does not exist",
+		"ConfigMap settings has no key log-level used by env LOG_LEVEL of container web",
+	}, texts)
+
+	require.Equal(t, "Pod", results[1].Kind)
+	require.Equal(t, "default/job", results[1].Name)
+	require.Len(t, results[1].Error, 1)
+	require.Equal(t, "ConfigMap settings has no key host used by volume config", results[1].Error[0].Text)
+}
@@ -39,11 +39,9 @@ func (analyzer CronJobAnalyzer) Analyze(a common.Analyzer) ([]common.Result, err
 		OpenapiSchema: a.OpenapiSchema,
 	}
 
-	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
-		"analyzer_name": kind,
-	})
+	snapshot := newAnalyzerErrorsSnapshot(kind)
 
-	cronJobList, err := a.Client.GetClient().BatchV1().CronJobs(a.Namespace).List(a.Context, v1.ListOptions{LabelSelector: a.LabelSelector})
+	cronJobList, err := a.Client.GetClient().BatchV1().CronJobs(a.Namespace).List(a.Context, v1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor("CronJob", a.FieldSelector)})
 	if err != nil {
 		return nil, err
 	}
@@ -51,6 +49,9 @@ func (analyzer CronJobAnalyzer) Analyze(a common.Analyzer) ([]common.Result, err
 	var preAnalysis = map[string]common.PreAnalysis{}
 
 	for _, cronJob := range cronJobList.Items {
+		if util.CreatedWithin(cronJob.ObjectMeta, a.MinAge) {
+			continue
+		}
 		var failures []common.Failure
 		if cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend {
 			doc := apiDoc.GetApiDocV2("spec.suspend")
@@ -58,6 +59,7 @@ func (analyzer CronJobAnalyzer) Analyze(a common.Analyzer) ([]common.Result, err
 			failures = append(failures, common.Failure{
 				Text:          fmt.Sprintf("CronJob %s is suspended", cronJob.Name),
 				KubernetesDoc: doc,
+				FieldPath:     "spec.suspend",
 				Sensitive: []common.Sensitive{
 					{
 						Unmasked: cronJob.Namespace,
@@ -77,6 +79,7 @@ func (analyzer CronJobAnalyzer) Analyze(a common.Analyzer) ([]common.Result, err
 				failures = append(failures, common.Failure{
 					Text:          fmt.Sprintf("CronJob %s has an invalid schedule: %s", cronJob.Name, err.Error()),
 					KubernetesDoc: doc,
+					FieldPath:     "spec.schedule",
 					Sensitive: []common.Sensitive{
 						{
 							Unmasked: cronJob.Namespace,
@@ -99,6 +102,7 @@ func (analyzer CronJobAnalyzer) Analyze(a common.Analyzer) ([]common.Result, err
 					failures = append(failures, common.Failure{
 						Text:          fmt.Sprintf("CronJob %s has a negative starting deadline", cronJob.Name),
 						KubernetesDoc: doc,
+						FieldPath:     "spec.startingDeadlineSeconds",
 						Sensitive: []common.Sensitive{
 							{
 								Unmasked: cronJob.Namespace,
@@ -120,7 +124,7 @@ func (analyzer CronJobAnalyzer) Analyze(a common.Analyzer) ([]common.Result, err
 			preAnalysis[fmt.Sprintf("%s/%s", cronJob.Namespace, cronJob.Name)] = common.PreAnalysis{
 				FailureDetails: failures,
 			}
-			AnalyzerErrorsMetric.WithLabelValues(kind, cronJob.Name, cronJob.Namespace).Set(float64(len(failures)))
+			snapshot.Set(cronJob.Name, cronJob.Namespace, float64(len(failures)))
 
 		}
 	}
@@ -134,6 +138,7 @@ func (analyzer CronJobAnalyzer) Analyze(a common.Analyzer) ([]common.Result, err
 		a.Results = append(a.Results, currentAnalysis)
 	}
 
+	snapshot.Apply()
 	return a.Results, nil
 }
 
@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// CSRs left without a certificate for longer than this are reported.
+	csrPendingGracePeriod = time.Hour
+	// Issued certificates expiring within this window are reported.
+	certificateExpiryWarningWindow = 30 * 24 * time.Hour
+)
+
+type CertificateSigningRequestAnalyzer struct{}
+
+func (CertificateSigningRequestAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "CertificateSigningRequest"
+
+	snapshot := newAnalyzerErrorsSnapshot(kind)
+
+	list, err := a.Client.GetClient().CertificatesV1().CertificateSigningRequests().List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor("CertificateSigningRequest", a.FieldSelector)})
+	if err != nil {
+		return nil, err
+	}
+
+	var preAnalysis = map[string]common.PreAnalysis{}
+
+	for _, csr := range list.Items {
+		var failures []common.Failure
+
+		// Expired or unissued serving/client certificates break kubelets and
+		// API clients cluster-wide, so every finding here is high severity.
+		addFailure := func(text string) {
+			failure := common.Failure{
+				Text:      text,
+				Sensitive: []common.Sensitive{},
+				Severity:  common.SeverityHigh,
+			}
+			if csr.Spec.Username != "" {
+				failure.Sensitive = append(failure.Sensitive, common.Sensitive{
+					Unmasked: csr.Spec.Username,
+					Masked:   util.MaskString(csr.Spec.Username),
+				})
+			}
+			failures = append(failures, failure)
+		}
+
+		approved, rejected := false, false
+		for _, condition := range csr.Status.Conditions {
+			if condition.Status != v1.ConditionTrue {
+				continue
+			}
+			switch condition.Type {
+			case certificatesv1.CertificateApproved:
+				approved = true
+			case certificatesv1.CertificateDenied, certificatesv1.CertificateFailed:
+				rejected = true
+				addFailure(fmt.Sprintf("CertificateSigningRequest %s for signer %s requested by %s is %s, reason %s: %s", csr.Name, csr.Spec.SignerName, csr.Spec.Username, condition.Type, condition.Reason, condition.Message))
+			}
+		}
+
+		stale := time.Since(csr.CreationTimestamp.Time) > csrPendingGracePeriod
+		switch {
+		case rejected:
+		case !approved && stale:
+			addFailure(fmt.Sprintf("CertificateSigningRequest %s for signer %s requested by %s has been pending approval since %s", csr.Name, csr.Spec.SignerName, csr.Spec.Username, csr.CreationTimestamp.UTC().Format(time.RFC3339)))
+		case approved && len(csr.Status.Certificate) == 0 && stale:
+			addFailure(fmt.Sprintf("CertificateSigningRequest %s for signer %s requested by %s was approved but no certificate has been issued since %s", csr.Name, csr.Spec.SignerName, csr.Spec.Username, csr.CreationTimestamp.UTC().Format(time.RFC3339)))
+		case approved && len(csr.Status.Certificate) > 0:
+			notAfter, ok := certificateNotAfter(csr.Status.Certificate)
+			if !ok {
+				break
+			}
+			if time.Now().After(notAfter) {
+				addFailure(fmt.Sprintf("certificate issued for CertificateSigningRequest %s (signer %s) expired at %s", csr.Name, csr.Spec.SignerName, notAfter.UTC().Format(time.RFC3339)))
+			} else if time.Until(notAfter) < certificateExpiryWarningWindow {
+				addFailure(fmt.Sprintf("certificate issued for CertificateSigningRequest %s (signer %s) expires at %s", csr.Name, csr.Spec.SignerName, notAfter.UTC().Format(time.RFC3339)))
+			}
+		}
+
+		if len(failures) > 0 {
+			preAnalysis[csr.Name] = common.PreAnalysis{
+				CertificateSigningRequest: csr,
+				FailureDetails:            failures,
+			}
+			snapshot.Set(csr.Name, "", float64(len(failures)))
+		}
+	}
+
+	for key, value := range preAnalysis {
+		var currentAnalysis = common.Result{
+			Kind:  kind,
+			Name:  key,
+			Error: value.FailureDetails,
+		}
+		a.Results = append(a.Results, currentAnalysis)
+	}
+
+	snapshot.Apply()
+	return a.Results, nil
+}
+
+// certificateNotAfter returns the expiry of the first certificate in a PEM bundle.
+func certificateNotAfter(data []byte) (time.Time, bool) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return time.Time{}, false
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return cert.NotAfter, true
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func generateTestCertificate(t *testing.T, notAfter time.Time) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "system:node:node1"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestCertificateSigningRequestAnalyzer(t *testing.T) {
+	approved := []certificatesv1.CertificateSigningRequestCondition{
+		{
+			Type:   certificatesv1.CertificateApproved,
+			Status: v1.ConditionTrue,
+		},
+	}
+	created := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	expiring := time.Now().Add(7 * 24 * time.Hour).Truncate(time.Second)
+	expired := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				&certificatesv1.CertificateSigningRequest{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "csr-denied",
+					},
+					Spec: certificatesv1.CertificateSigningRequestSpec{
+						SignerName: certificatesv1.KubeletServingSignerName,
+						Username:   "system:node:node1",
+					},
+					Status: certificatesv1.CertificateSigningRequestStatus{
+						Conditions: []certificatesv1.CertificateSigningRequestCondition{
+							{
+								Type:    certificatesv1.CertificateDenied,
+								Status:  v1.ConditionTrue,
+								Reason:  "AutoDenied",
+								Message: "node IP not allowed",
+							},
+						},
+					},
+				},
+				&certificatesv1.CertificateSigningRequest{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "csr-pending",
+						CreationTimestamp: metav1.NewTime(created),
+					},
+					Spec: certificatesv1.CertificateSigningRequestSpec{
+						SignerName: certificatesv1.KubeletServingSignerName,
+						Username:   "system:node:node1",
+					},
+				},
+				&certificatesv1.CertificateSigningRequest{
+					// Recently created, still within the grace period.
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "csr-new",
+						CreationTimestamp: metav1.NewTime(time.Now()),
+					},
+				},
+				&certificatesv1.CertificateSigningRequest{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "csr-unissued",
+						CreationTimestamp: metav1.NewTime(created),
+					},
+					Spec: certificatesv1.CertificateSigningRequestSpec{
+						SignerName: certificatesv1.KubeletServingSignerName,
+						Username:   "system:node:node2",
+					},
+					Status: certificatesv1.CertificateSigningRequestStatus{
+						Conditions: approved,
+					},
+				},
+				&certificatesv1.CertificateSigningRequest{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "csr-expiring",
+					},
+					Spec: certificatesv1.CertificateSigningRequestSpec{
+						SignerName: certificatesv1.KubeAPIServerClientKubeletSignerName,
+					},
+					Status: certificatesv1.CertificateSigningRequestStatus{
+						Conditions:  approved,
+						Certificate: generateTestCertificate(t, expiring),
+					},
+				},
+				&certificatesv1.CertificateSigningRequest{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "csr-expired",
+					},
+					Spec: certificatesv1.CertificateSigningRequestSpec{
+						SignerName: certificatesv1.KubeAPIServerClientKubeletSignerName,
+					},
+					Status: certificatesv1.CertificateSigningRequestStatus{
+						Conditions:  approved,
+						Certificate: generateTestCertificate(t, expired),
+					},
+				},
+				&certificatesv1.CertificateSigningRequest{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "csr-valid",
+					},
+					Status: certificatesv1.CertificateSigningRequestStatus{
+						Conditions:  approved,
+						Certificate: generateTestCertificate(t, time.Now().Add(300*24*time.Hour)),
+					},
+				},
+			),
+		},
+		Context: context.Background(),
+	}
+
+	results, err := CertificateSigningRequestAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	expectations := []struct {
+		name      string
+		text      string
+		sensitive string
+	}{
+		{
+			name:      "csr-denied",
+			text:      "CertificateSigningRequest csr-denied for signer kubernetes.io/kubelet-serving requested by system:node:node1 is Denied, reason AutoDenied: node IP not allowed",
+			sensitive: "system:node:node1",
+		},
+		{
+			name: "csr-expired",
+			text: "certificate issued for CertificateSigningRequest csr-expired (signer kubernetes.io/kube-apiserver-client-kubelet) expired at " + expired.UTC().Format(time.RFC3339),
+		},
+		{
+			name: "csr-expiring",
+			text: "certificate issued for CertificateSigningRequest csr-expiring (signer kubernetes.io/kube-apiserver-client-kubelet) expires at " + expiring.UTC().Format(time.RFC3339),
+		},
+		{
+			name:      "csr-pending",
+			text:      "CertificateSigningRequest csr-pending for signer kubernetes.io/kubelet-serving requested by system:node:node1 has been pending approval since " + created.UTC().Format(time.RFC3339),
+			sensitive: "system:node:node1",
+		},
+		{
+			name:      "csr-unissued",
+			text:      "CertificateSigningRequest csr-unissued for signer kubernetes.io/kubelet-serving requested by system:node:node2 was approved but no certificate has been issued since " + created.UTC().Format(time.RFC3339),
+			sensitive: "system:node:node2",
+		},
+	}
+	require.Equal(t, len(expectations), len(results))
+	for i, result := range results {
+		require.Equal(t, expectations[i].name, result.Name)
+		require.Len(t, result.Error, 1)
+		require.Equal(t, expectations[i].text, result.Error[0].Text)
+		require.Equal(t, common.SeverityHigh, result.Error[0].Severity)
+		if expectations[i].sensitive == "" {
+			require.Empty(t, result.Error[0].Sensitive)
+		} else {
+			require.Len(t, result.Error[0].Sensitive, 1)
+			require.Equal(t, expectations[i].sensitive, result.Error[0].Sensitive[0].Unmasked)
+		}
+	}
+}
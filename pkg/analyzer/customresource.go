@@ -0,0 +1,179 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	"github.com/spf13/viper"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// abnormalTrueConditions are the condition types, of the kstatus and
+// operator conventions, that are False when all is well.
+var abnormalTrueConditions = []string{"Degraded", "Reconciling", "Stalled"}
+
+// CustomResourceAnalyzer reports the False status conditions, such as Ready
+// or Available, of the kinds of custom resources of
+// common.Analyzer.CustomResources, so resources managed by operators can be
+// diagnosed without an analyzer for each of them. Kinds the cluster doesn't
+// serve are skipped.
+type CustomResourceAnalyzer struct{}
+
+func (CustomResourceAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "CustomResource"
+
+	snapshot := newAnalyzerErrorsSnapshot(kind)
+
+	resources := a.CustomResources
+	if len(resources) > 0 && a.Client.GetDynamicClient() == nil {
+		return nil, fmt.Errorf("custom resources can't be listed without a dynamic client")
+	}
+
+	for _, resource := range resources {
+		gvk := schema.GroupVersionKind{Group: resource.Group, Version: resource.Version, Kind: resource.Kind}
+		apiResource, err := findAPIResource(a, gvk)
+		if err != nil {
+			return nil, err
+		}
+		if apiResource == nil {
+			continue
+		}
+
+		gvr := gvk.GroupVersion().WithResource(apiResource.Name)
+		client := a.Client.GetDynamicClient().Resource(gvr)
+		var list *unstructured.UnstructuredList
+		if apiResource.Namespaced {
+			list, err = client.Namespace(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector})
+		} else {
+			list, err = client.List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector})
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range list.Items {
+			if util.CreatedWithin(metav1.ObjectMeta{CreationTimestamp: item.GetCreationTimestamp()}, a.MinAge) {
+				continue
+			}
+			failures := conditionFailures(item, resource.Conditions)
+			if len(failures) == 0 {
+				continue
+			}
+			name := item.GetName()
+			if item.GetNamespace() != "" {
+				name = fmt.Sprintf("%s/%s", item.GetNamespace(), item.GetName())
+			}
+			snapshot.Set(item.GetName(), item.GetNamespace(), float64(len(failures)))
+			a.Results = append(a.Results, common.Result{
+				Kind:  gvk.Kind,
+				Name:  name,
+				Error: failures,
+			})
+		}
+	}
+
+	snapshot.Apply()
+	return a.Results, nil
+}
+
+// CustomResources returns the kinds of custom resources set with the
+// custom_resources configuration key.
+func CustomResources() ([]common.CustomResource, error) {
+	var resources []common.CustomResource
+	if err := viper.UnmarshalKey("custom_resources", &resources); err != nil {
+		return nil, fmt.Errorf("invalid custom_resources: %w", err)
+	}
+	for _, resource := range resources {
+		if resource.Version == "" || resource.Kind == "" {
+			return nil, fmt.Errorf("invalid custom_resources: a custom resource needs a version and a kind")
+		}
+	}
+	return resources, nil
+}
+
+// findAPIResource returns the API resource serving gvk, or nil when the
+// cluster doesn't serve it, e.g. as its CRD isn't installed.
+func findAPIResource(a common.Analyzer, gvk schema.GroupVersionKind) (*metav1.APIResource, error) {
+	list, err := a.Client.GetClient().Discovery().ServerResourcesForGroupVersion(gvk.GroupVersion().String())
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i, resource := range list.APIResources {
+		// Subresources, such as status, share the kind of their resource.
+		if resource.Kind == gvk.Kind && !strings.Contains(resource.Name, "/") {
+			return &list.APIResources[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// conditionFailures returns a failure for each status condition of item that
+// is False, of the given types or, without any, of any type but those that
+// are False when all is well.
+func conditionFailures(item unstructured.Unstructured, types []string) []common.Failure {
+	conditions, _, _ := unstructured.NestedSlice(item.Object, "status", "conditions")
+	var failures []common.Failure
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditionType, _, _ := unstructured.NestedString(condition, "type")
+		status, _, _ := unstructured.NestedString(condition, "status")
+		if status != "False" {
+			continue
+		}
+		if len(types) > 0 && !slices.Contains(types, conditionType) {
+			continue
+		}
+		if len(types) == 0 && slices.Contains(abnormalTrueConditions, conditionType) {
+			continue
+		}
+
+		text := fmt.Sprintf("%s %s has condition %s False", item.GetKind(), item.GetName(), conditionType)
+		if reason, _, _ := unstructured.NestedString(condition, "reason"); reason != "" {
+			text += fmt.Sprintf(" (%s)", reason)
+		}
+		if message, _, _ := unstructured.NestedString(condition, "message"); message != "" {
+			text += ": " + message
+		}
+		severity := common.SeverityMedium
+		if conditionType == "Ready" || conditionType == "Available" {
+			severity = common.SeverityHigh
+		}
+		failures = append(failures, common.Failure{
+			Text: text,
+			Sensitive: []common.Sensitive{
+				{
+					Unmasked: item.GetName(),
+					Masked:   util.MaskString(item.GetName()),
+				},
+			},
+			Severity: severity,
+		})
+	}
+	return failures
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func customResource(apiVersion string, kind string, namespace string, name string, conditions ...map[string]interface{}) runtime.Object {
+	object := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	object.SetAPIVersion(apiVersion)
+	object.SetKind(kind)
+	object.SetNamespace(namespace)
+	object.SetName(name)
+	items := make([]interface{}, 0, len(conditions))
+	for _, condition := range conditions {
+		items = append(items, condition)
+	}
+	_ = unstructured.SetNestedSlice(object.Object, items, "status", "conditions")
+	return object
+}
+
+func TestCustomResourceAnalyzer(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "cert-manager.io/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "certificates/status", Kind: "Certificate", Namespaced: true},
+				{Name: "certificates", Kind: "Certificate", Namespaced: true},
+			},
+		},
+		{
+			GroupVersion: "example.com/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "clusterwidgets", Kind: "ClusterWidget"},
+			},
+		},
+	}
+	client := &kubernetes.Client{
+		Client: clientset,
+		DynamicClient: dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(),
+			customResource("cert-manager.io/v1", "Certificate", "shop", "web",
+				map[string]interface{}{"type": "Ready", "status": "False", "reason": "DoesNotExist", "message": "Issuing certificate as Secret does not exist"},
+				map[string]interface{}{"type": "Issuing", "status": "True"},
+			),
+			customResource("cert-manager.io/v1", "Certificate", "shop", "api",
+				map[string]interface{}{"type": "Ready", "status": "True"},
+				map[string]interface{}{"type": "Degraded", "status": "False"},
+			),
+			customResource("cert-manager.io/v1", "Certificate", "blog", "blog",
+				map[string]interface{}{"type": "Ready", "status": "True"},
+				map[string]interface{}{"type": "Renewing", "status": "False", "message": "Renewal failed"},
+			),
+			customResource("example.com/v1", "ClusterWidget", "", "main",
+				map[string]interface{}{"type": "Available", "status": "False"},
+			),
+		),
+	}
+
+	certificates := common.CustomResource{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+	widgets := common.CustomResource{Group: "example.com", Version: "v1", Kind: "ClusterWidget"}
+	// The CRD of Orders isn't installed.
+	orders := common.CustomResource{Group: "acme.cert-manager.io", Version: "v1", Kind: "Order"}
+
+	tests := []struct {
+		name      string
+		resources []common.CustomResource
+		namespace string
+		expected  map[string][]string
+	}{
+		{
+			name:      "all conditions",
+			resources: []common.CustomResource{certificates, widgets, orders},
+			expected: map[string][]string{
+				"Certificate shop/web":  {"Certificate web has condition Ready False (DoesNotExist): Issuing certificate as Secret does not exist"},
+				"Certificate blog/blog": {"Certificate blog has condition Renewing False: Renewal failed"},
+				"ClusterWidget main":    {"ClusterWidget main has condition Available False"},
+			},
+		},
+		{
+			name: "given conditions",
+			resources: []common.CustomResource{
+				{Group: "cert-manager.io", Version: "v1", Kind: "Certificate", Conditions: []string{"Ready"}},
+			},
+			expected: map[string][]string{
+				"Certificate shop/web": {"Certificate web has condition Ready False (DoesNotExist): Issuing certificate as Secret does not exist"},
+			},
+		},
+		{
+			name:      "namespace",
+			resources: []common.CustomResource{certificates, widgets},
+			namespace: "blog",
+			expected: map[string][]string{
+				"Certificate blog/blog": {"Certificate blog has condition Renewing False: Renewal failed"},
+				"ClusterWidget main":    {"ClusterWidget main has condition Available False"},
+			},
+		},
+		{
+			name:     "no custom resources",
+			expected: map[string][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := CustomResourceAnalyzer{}.Analyze(common.Analyzer{
+				Client:          client,
+				Context:         context.Background(),
+				Namespace:       tt.namespace,
+				CustomResources: tt.resources,
+			})
+			require.NoError(t, err)
+
+			got := map[string][]string{}
+			for _, result := range results {
+				var texts []string
+				for _, failure := range result.Error {
+					texts = append(texts, failure.Text)
+				}
+				got[result.Kind+" "+result.Name] = texts
+			}
+			require.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestCustomResourceAnalyzerSeverity(t *testing.T) {
+	failures := conditionFailures(*customResource("example.com/v1", "Database", "shop", "db",
+		map[string]interface{}{"type": "Available", "status": "False"},
+		map[string]interface{}{"type": "BackupsHealthy", "status": "False"},
+		map[string]interface{}{"type": "Stalled", "status": "False"},
+	).(*unstructured.Unstructured), nil)
+	require.Len(t, failures, 2)
+	require.Equal(t, common.SeverityHigh, failures[0].Severity)
+	require.Equal(t, common.SeverityMedium, failures[1].Severity)
+}
+
+func TestCustomResourcesConfig(t *testing.T) {
+	viper.Set("custom_resources", []map[string]interface{}{{"group": "cert-manager.io", "kind": "Certificate"}})
+	defer viper.Set("custom_resources", nil)
+
+	_, err := CustomResources()
+	require.EqualError(t, err, "invalid custom_resources: a custom resource needs a version and a kind")
+
+	viper.Set("custom_resources", []map[string]interface{}{{"group": "cert-manager.io", "version": "v1", "kind": "Certificate", "conditions": []string{"Ready"}}})
+	resources, err := CustomResources()
+	require.NoError(t, err)
+	require.Equal(t, []common.CustomResource{
+		{Group: "cert-manager.io", Version: "v1", Kind: "Certificate", Conditions: []string{"Ready"}},
+	}, resources)
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// DaemonSetAnalyzer reports DaemonSets whose pods aren't all ready or
+// available, that run pods on nodes they shouldn't, or whose nodeSelector
+// matches no node. The nodeSelector and tolerations are included, as they
+// usually explain on which nodes the pods can't run.
+type DaemonSetAnalyzer struct{}
+
+func (DaemonSetAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "DaemonSet"
+	apiDoc := kubernetes.K8sApiReference{
+		Kind: kind,
+		ApiVersion: schema.GroupVersion{
+			Group:   "apps",
+			Version: "v1",
+		},
+		OpenapiSchema: a.OpenapiSchema,
+	}
+
+	snapshot := newAnalyzerErrorsSnapshot(kind)
+
+	list, err := a.Client.GetClient().AppsV1().DaemonSets(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor(kind, a.FieldSelector)})
+	if err != nil {
+		return nil, err
+	}
+
+	var preAnalysis = map[string]common.PreAnalysis{}
+
+	for _, ds := range list.Items {
+		if util.CreatedWithin(ds.ObjectMeta, a.MinAge) {
+			continue
+		}
+		sensitive := []common.Sensitive{
+			{
+				Unmasked: ds.Namespace,
+				Masked:   util.MaskString(ds.Namespace),
+			},
+			{
+				Unmasked: ds.Name,
+				Masked:   util.MaskString(ds.Name),
+			},
+		}
+		scheduling := daemonSetScheduling(ds.Spec.Template.Spec)
+		status := ds.Status
+		var failures []common.Failure
+
+		if status.DesiredNumberScheduled == 0 && len(ds.Spec.Template.Spec.NodeSelector) > 0 {
+			failures = append(failures, common.Failure{
+				Text:          fmt.Sprintf("DaemonSet %s does not schedule any pod: no node matches it (%s)", ds.Name, scheduling),
+				KubernetesDoc: apiDoc.GetApiDocV2("spec.template.spec.nodeSelector"),
+				FieldPath:     "spec.template.spec.nodeSelector",
+				Sensitive:     sensitive,
+				Severity:      common.SeverityMedium,
+			})
+		}
+
+		if status.NumberReady < status.DesiredNumberScheduled || status.NumberUnavailable > 0 {
+			text := fmt.Sprintf("DaemonSet %s has %d of %d desired pods ready and %d unavailable (%s)",
+				ds.Name, status.NumberReady, status.DesiredNumberScheduled, status.NumberUnavailable, scheduling)
+			// The latest event usually says why pods can't be created or scheduled.
+			if evt, err := util.FetchLatestEvent(a.Context, a.Client, ds.Namespace, ds.Name); err == nil && evt != nil && evt.Type != v1.EventTypeNormal && evt.Message != "" {
+				text += fmt.Sprintf("; latest event %s: %s", evt.Reason, evt.Message)
+			}
+			severity := common.SeverityMedium
+			if status.NumberReady == 0 {
+				severity = common.SeverityHigh
+			}
+			failures = append(failures, common.Failure{
+				Text:      text,
+				Sensitive: sensitive,
+				Severity:  severity,
+			})
+		}
+
+		if status.NumberMisscheduled > 0 {
+			failures = append(failures, common.Failure{
+				Text: fmt.Sprintf("DaemonSet %s runs %d pods on nodes it should not run on (%s); node labels or taints likely changed after the pods were scheduled",
+					ds.Name, status.NumberMisscheduled, scheduling),
+				KubernetesDoc: apiDoc.GetApiDocV2("status.numberMisscheduled"),
+				Sensitive:     sensitive,
+				Severity:      common.SeverityMedium,
+			})
+		}
+
+		if len(failures) > 0 {
+			preAnalysis[fmt.Sprintf("%s/%s", ds.Namespace, ds.Name)] = common.PreAnalysis{
+				DaemonSet:      ds,
+				FailureDetails: failures,
+			}
+			snapshot.Set(ds.Name, ds.Namespace, float64(len(failures)))
+		}
+	}
+
+	for key, value := range preAnalysis {
+		a.Results = append(a.Results, common.Result{
+			Kind:  kind,
+			Name:  key,
+			Error: value.FailureDetails,
+		})
+	}
+
+	snapshot.Apply()
+	return a.Results, nil
+}
+
+// daemonSetScheduling describes the nodeSelector and tolerations of the pods,
+// e.g. "nodeSelector: gpu=true; tolerations: dedicated=gpu:NoSchedule".
+func daemonSetScheduling(spec v1.PodSpec) string {
+	nodeSelector := "none"
+	if len(spec.NodeSelector) > 0 {
+		var labels []string
+		for key, value := range spec.NodeSelector {
+			labels = append(labels, fmt.Sprintf("%s=%s", key, value))
+		}
+		sort.Strings(labels)
+		nodeSelector = strings.Join(labels, ",")
+	}
+
+	tolerations := "none"
+	if len(spec.Tolerations) > 0 {
+		var descriptions []string
+		for _, toleration := range spec.Tolerations {
+			description := toleration.Key
+			if toleration.Operator == v1.TolerationOpExists {
+				if description == "" {
+					description = "all taints"
+				}
+			} else {
+				description += "=" + toleration.Value
+			}
+			if toleration.Effect != "" {
+				description += ":" + string(toleration.Effect)
+			}
+			descriptions = append(descriptions, description)
+		}
+		tolerations = strings.Join(descriptions, ",")
+	}
+
+	affinity := ""
+	if spec.Affinity != nil && spec.Affinity.NodeAffinity != nil {
+		affinity = "; node affinity is set"
+	}
+	return fmt.Sprintf("nodeSelector: %s; tolerations: %s%s", nodeSelector, tolerations, affinity)
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDaemonSetAnalyzer(t *testing.T) {
+	daemonSet := func(namespace string, name string, spec v1.PodSpec, status appsv1.DaemonSetStatus) *appsv1.DaemonSet {
+		return &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       appsv1.DaemonSetSpec{Template: v1.PodTemplateSpec{Spec: spec}},
+			Status:     status,
+		}
+	}
+	gpuSpec := v1.PodSpec{
+		NodeSelector: map[string]string{"gpu": "true"},
+		Tolerations: []v1.Toleration{
+			{Key: "dedicated", Operator: v1.TolerationOpEqual, Value: "gpu", Effect: v1.TaintEffectNoSchedule},
+			{Operator: v1.TolerationOpExists},
+		},
+	}
+
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				daemonSet("default", "healthy", v1.PodSpec{}, appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: 3, NumberAvailable: 3}),
+				daemonSet("gpu", "no-nodes", gpuSpec, appsv1.DaemonSetStatus{}),
+				daemonSet("logging", "fluentd", v1.PodSpec{}, appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: 0, NumberUnavailable: 3}),
+				&v1.Event{
+					ObjectMeta:     metav1.ObjectMeta{Name: "fluentd.1", Namespace: "logging"},
+					InvolvedObject: v1.ObjectReference{Kind: "DaemonSet", Name: "fluentd", Namespace: "logging"},
+					Type:           v1.EventTypeWarning,
+					Reason:         "FailedCreate",
+					Message:        `Error creating: pods "fluentd-" is forbidden: exceeded quota: pods`,
+				},
+				daemonSet("monitoring", "node-exporter", v1.PodSpec{}, appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: 3, NumberAvailable: 3, NumberMisscheduled: 1}),
+			),
+		},
+		Context: context.Background(),
+	}
+
+	results, err := DaemonSetAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+
+	texts := map[string][]string{}
+	for _, result := range results {
+		require.Equal(t, "DaemonSet", result.Kind)
+		for _, failure := range result.Error {
+			texts[result.Name] = append(texts[result.Name], failure.Text)
+		}
+	}
+	require.Equal(t, map[string][]string{
+		"gpu/no-nodes": {
+			"DaemonSet no-nodes does not schedule any pod: no node matches it (nodeSelector: gpu=true; tolerations: dedicated=gpu:NoSchedule,all taints)",
+		},
+		"logging/fluentd": {
+			"DaemonSet fluentd has 0 of 3 desired pods ready and 3 unavailable (nodeSelector: none; tolerations: none); " +
+				`latest event FailedCreate: Error creating: pods "fluentd-" is forbidden: exceeded quota: pods`,
+		},
+		"monitoring/node-exporter": {
+			"DaemonSet node-exporter runs 1 pods on nodes it should not run on (nodeSelector: none; tolerations: none); node labels or taints likely changed after the pods were scheduled",
+		},
+	}, texts)
+}
@@ -42,24 +42,31 @@ func (d DeploymentAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 		OpenapiSchema: a.OpenapiSchema,
 	}
 
-	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
-		"analyzer_name": kind,
-	})
+	snapshot := newAnalyzerErrorsSnapshot(kind)
 
-	deployments, err := a.Client.GetClient().AppsV1().Deployments(a.Namespace).List(context.Background(), v1.ListOptions{LabelSelector: a.LabelSelector})
+	deployments, err := a.Client.GetClient().AppsV1().Deployments(a.Namespace).List(context.Background(), v1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor("Deployment", a.FieldSelector)})
 	if err != nil {
 		return nil, err
 	}
 	var preAnalysis = map[string]common.PreAnalysis{}
 
 	for _, deployment := range deployments.Items {
+		if util.CreatedWithin(deployment.ObjectMeta, a.MinAge) {
+			continue
+		}
 		var failures []common.Failure
-		if *deployment.Spec.Replicas != deployment.Status.Replicas {
+		// Replicas defaults to 1, e.g. in manifests read from files.
+		replicas := int32(1)
+		if deployment.Spec.Replicas != nil {
+			replicas = *deployment.Spec.Replicas
+		}
+		if replicas != deployment.Status.Replicas {
 			doc := apiDoc.GetApiDocV2("spec.replicas")
 
 			failures = append(failures, common.Failure{
-				Text:          fmt.Sprintf("Deployment %s/%s has %d replicas but %d are available", deployment.Namespace, deployment.Name, *deployment.Spec.Replicas, deployment.Status.Replicas),
+				Text:          fmt.Sprintf("Deployment %s/%s has %d replicas but %d are available", deployment.Namespace, deployment.Name, replicas, deployment.Status.Replicas),
 				KubernetesDoc: doc,
+				FieldPath:     "spec.replicas",
 				Sensitive: []common.Sensitive{
 					{
 						Unmasked: deployment.Namespace,
@@ -76,7 +83,7 @@ func (d DeploymentAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 				FailureDetails: failures,
 				Deployment:     deployment,
 			}
-			AnalyzerErrorsMetric.WithLabelValues(kind, deployment.Name, deployment.Namespace).Set(float64(len(failures)))
+			snapshot.Set(deployment.Name, deployment.Namespace, float64(len(failures)))
 		}
 
 	}
@@ -91,5 +98,6 @@ func (d DeploymentAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 		a.Results = append(a.Results, currentAnalysis)
 	}
 
+	snapshot.Apply()
 	return a.Results, nil
 }
@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// EndpointsAnalyzer reports Services with a selector whose EndpointSlices
+// have no ready endpoint, telling apart a selector that matches no pods
+// from pods that match but aren't ready.
+type EndpointsAnalyzer struct{}
+
+func (EndpointsAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	analyzerName := "Endpoints"
+
+	snapshot := newAnalyzerErrorsSnapshot(analyzerName)
+
+	list, err := a.Client.GetClient().CoreV1().Services(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor("Service", a.FieldSelector)})
+	if err != nil {
+		return nil, err
+	}
+
+	// Pods are only listed once per namespace with a Service without endpoints.
+	podsByNamespace := map[string][]v1.Pod{}
+
+	for _, svc := range list.Items {
+		if len(svc.Spec.Selector) == 0 || svc.Spec.Type == v1.ServiceTypeExternalName || util.CreatedWithin(svc.ObjectMeta, a.MinAge) {
+			continue
+		}
+
+		slices, err := a.Client.GetClient().DiscoveryV1().EndpointSlices(svc.Namespace).List(a.Context, metav1.ListOptions{
+			LabelSelector: fmt.Sprintf("%s=%s", discoveryv1.LabelServiceName, svc.Name),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if hasReadyEndpoint(slices.Items) {
+			continue
+		}
+
+		pods, ok := podsByNamespace[svc.Namespace]
+		if !ok {
+			podList, err := a.Client.GetClient().CoreV1().Pods(svc.Namespace).List(a.Context, metav1.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			pods = podList.Items
+			podsByNamespace[svc.Namespace] = pods
+		}
+
+		selector := labels.SelectorFromSet(svc.Spec.Selector)
+		matched := 0
+		for _, pod := range pods {
+			if pod.Status.Phase != v1.PodSucceeded && pod.Status.Phase != v1.PodFailed && selector.Matches(labels.Set(pod.Labels)) {
+				matched++
+			}
+		}
+
+		text := fmt.Sprintf("Service %s/%s has no ready endpoints: no pods match its selector %s", svc.Namespace, svc.Name, selector)
+		if matched > 0 {
+			text = fmt.Sprintf("Service %s/%s has no ready endpoints: %d pods match its selector %s but none are ready", svc.Namespace, svc.Name, matched, selector)
+		}
+		failures := []common.Failure{
+			{
+				Text: text,
+				Sensitive: []common.Sensitive{
+					{
+						Unmasked: svc.Name,
+						Masked:   util.MaskString(svc.Name),
+					},
+				},
+				FieldPath: "spec.selector",
+				Severity:  common.SeverityHigh,
+			},
+		}
+		snapshot.Set(svc.Name, svc.Namespace, float64(len(failures)))
+		a.Results = append(a.Results, common.Result{
+			Kind:  "Service",
+			Name:  fmt.Sprintf("%s/%s", svc.Namespace, svc.Name),
+			Error: failures,
+		})
+	}
+
+	snapshot.Apply()
+	return a.Results, nil
+}
+
+// hasReadyEndpoint reports whether any endpoint of the slices is ready. An
+// unknown readiness counts as ready, as consumers of EndpointSlices do.
+func hasReadyEndpoint(slices []discoveryv1.EndpointSlice) bool {
+	for _, slice := range slices {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func TestEndpointsAnalyzer(t *testing.T) {
+	service := func(name string, selector map[string]string) *v1.Service {
+		return &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       v1.ServiceSpec{Selector: selector},
+		}
+	}
+	slice := func(name string, service string, ready *bool) *discoveryv1.EndpointSlice {
+		return &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{discoveryv1.LabelServiceName: service},
+			},
+			Endpoints: []discoveryv1.Endpoint{{Conditions: discoveryv1.EndpointConditions{Ready: ready}}},
+		}
+	}
+	pod := func(name string, app string, phase v1.PodPhase) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": app}},
+			Status:     v1.PodStatus{Phase: phase},
+		}
+	}
+
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				service("ready", map[string]string{"app": "ready"}),
+				slice("ready-abc", "ready", ptr.To(true)),
+				pod("ready-1", "ready", v1.PodRunning),
+				service("unknown", map[string]string{"app": "unknown"}),
+				slice("unknown-abc", "unknown", nil),
+				service("unready", map[string]string{"app": "unready"}),
+				slice("unready-abc", "unready", ptr.To(false)),
+				pod("unready-1", "unready", v1.PodRunning),
+				pod("unready-2", "unready", v1.PodPending),
+				pod("unready-3", "unready", v1.PodSucceeded),
+				service("typo", map[string]string{"app": "frontnd"}),
+				pod("frontend-1", "frontend", v1.PodRunning),
+				// Services without a selector manage their own endpoints.
+				service("external", nil),
+			),
+		},
+		Context:   context.Background(),
+		Namespace: "default",
+	}
+
+	results, err := EndpointsAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+
+	texts := map[string]string{}
+	for _, result := range results {
+		require.Equal(t, "Service", result.Kind)
+		require.Len(t, result.Error, 1)
+		texts[result.Name] = result.Error[0].Text
+	}
+	require.Equal(t, map[string]string{
+		"default/unready": "Service default/unready has no ready endpoints: 2 pods match its selector app=unready but none are ready",
+		"default/typo":    "Service default/typo has no ready endpoints: no pods match its selector app=frontnd",
+	}, texts)
+}
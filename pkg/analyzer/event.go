@@ -0,0 +1,185 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// defaultEventLookback is how far back Warning events are reported,
+	// unless common.Analyzer.EventLookback is set.
+	defaultEventLookback = time.Hour
+	// maxEventReasons is the number of reasons reported per object, the most
+	// frequent ones.
+	maxEventReasons = 5
+	// repeatedEventCount is the number of occurrences from which a warning
+	// is ranked medium rather than low.
+	repeatedEventCount = 5
+)
+
+// EventAnalyzer reports the Warning events of the lookback window grouped by
+// the object they involve, with the number of times each reason occurred,
+// catching what the analyzers of the objects' kinds miss.
+type EventAnalyzer struct{}
+
+// eventWarning aggregates the Warning events of an object with a reason.
+type eventWarning struct {
+	reason  string
+	message string
+	count   int32
+	last    time.Time
+}
+
+// eventObject gathers the warnings of an object.
+type eventObject struct {
+	ref      v1.ObjectReference
+	warnings map[string]*eventWarning
+}
+
+func (EventAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "Event"
+
+	snapshot := newAnalyzerErrorsSnapshot(kind)
+
+	fieldSelector := "type=Warning"
+	if selector := util.FieldSelectorFor(kind, a.FieldSelector); selector != "" {
+		fieldSelector += "," + selector
+	}
+	list, err := a.Client.GetClient().CoreV1().Events(a.Namespace).List(a.Context, metav1.ListOptions{
+		LabelSelector: a.LabelSelector,
+		FieldSelector: fieldSelector,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lookback := a.EventLookback
+	if lookback == 0 {
+		lookback = defaultEventLookback
+	}
+	since := time.Now().Add(-lookback)
+
+	objects := map[string]*eventObject{}
+	for _, event := range list.Items {
+		last := eventTime(event)
+		if event.Type != v1.EventTypeWarning || last.Before(since) {
+			continue
+		}
+		ref := event.InvolvedObject
+		key := strings.Join([]string{ref.Kind, ref.Namespace, ref.Name}, "/")
+		object, ok := objects[key]
+		if !ok {
+			object = &eventObject{ref: ref, warnings: map[string]*eventWarning{}}
+			objects[key] = object
+		}
+		warning, ok := object.warnings[event.Reason]
+		if !ok {
+			warning = &eventWarning{reason: event.Reason}
+			object.warnings[event.Reason] = warning
+		}
+		warning.count += eventCount(event)
+		if !last.Before(warning.last) {
+			warning.last = last
+			warning.message = event.Message
+		}
+	}
+
+	for _, key := range mapKeys(objects) {
+		object := objects[key]
+		warnings := make([]*eventWarning, 0, len(object.warnings))
+		for _, warning := range object.warnings {
+			warnings = append(warnings, warning)
+		}
+		sort.Slice(warnings, func(i, j int) bool {
+			if warnings[i].count != warnings[j].count {
+				return warnings[i].count > warnings[j].count
+			}
+			return warnings[i].reason < warnings[j].reason
+		})
+		if len(warnings) > maxEventReasons {
+			warnings = warnings[:maxEventReasons]
+		}
+
+		name := object.ref.Name
+		if object.ref.Namespace != "" {
+			name = fmt.Sprintf("%s/%s", object.ref.Namespace, object.ref.Name)
+		}
+		sensitive := []common.Sensitive{
+			{
+				Unmasked: object.ref.Name,
+				Masked:   util.MaskString(object.ref.Name),
+			},
+		}
+		if object.ref.Namespace != "" {
+			sensitive = append(sensitive, common.Sensitive{
+				Unmasked: object.ref.Namespace,
+				Masked:   util.MaskString(object.ref.Namespace),
+			})
+		}
+		var failures []common.Failure
+		for _, warning := range warnings {
+			severity := common.SeverityLow
+			if warning.count >= repeatedEventCount {
+				severity = common.SeverityMedium
+			}
+			failures = append(failures, common.Failure{
+				Text: fmt.Sprintf("%s %s: %s occurred %d time(s): %s",
+					object.ref.Kind, name, warning.reason, warning.count, warning.message),
+				Sensitive: sensitive,
+				Severity:  severity,
+			})
+		}
+
+		snapshot.Set(object.ref.Name, object.ref.Namespace, float64(len(failures)))
+		a.Results = append(a.Results, common.Result{
+			Kind:  kind,
+			Name:  name,
+			Error: failures,
+		})
+	}
+
+	snapshot.Apply()
+	return a.Results, nil
+}
+
+// eventTime returns when an event last occurred.
+func eventTime(event v1.Event) time.Time {
+	switch {
+	case event.Series != nil && !event.Series.LastObservedTime.IsZero():
+		return event.Series.LastObservedTime.Time
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// eventCount returns the number of times an event occurred.
+func eventCount(event v1.Event) int32 {
+	if event.Series != nil && event.Series.Count > 0 {
+		return event.Series.Count
+	}
+	return max(event.Count, 1)
+}
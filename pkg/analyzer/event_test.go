@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func recentEvent(name string, kind string, object string, reason string, message string, count int32, age time.Duration) *v1.Event {
+	event := warningEvent(name, kind, object, reason, message)
+	event.Count = count
+	event.LastTimestamp = metav1.NewTime(time.Now().Add(-age))
+	return event
+}
+
+func TestEventAnalyzer(t *testing.T) {
+	tests := []struct {
+		name     string
+		lookback time.Duration
+		objects  []runtime.Object
+		expected map[string][]string
+		severity []common.Severity
+	}{
+		{
+			name: "no warnings",
+			objects: []runtime.Object{
+				&v1.Event{
+					ObjectMeta:     metav1.ObjectMeta{Name: "web.1", Namespace: "default"},
+					InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: "web", Namespace: "default"},
+					Type:           v1.EventTypeNormal,
+					Reason:         "Pulled",
+					LastTimestamp:  metav1.Now(),
+				},
+			},
+			expected: map[string][]string{},
+		},
+		{
+			name: "warnings deduplicated by reason and object",
+			objects: []runtime.Object{
+				recentEvent("web.1", "Pod", "web", "FailedScheduling", "0/3 nodes are available: 3 Insufficient cpu.", 4, 10*time.Minute),
+				recentEvent("web.2", "Pod", "web", "FailedScheduling", "0/3 nodes are available: 3 Insufficient memory.", 3, time.Minute),
+				recentEvent("web.3", "Pod", "web", "FailedMount", "MountVolume.SetUp failed for volume \"config\"", 1, time.Minute),
+				recentEvent("db.1", "StatefulSet", "db", "FailedCreate", "create Pod db-0 failed", 0, time.Minute),
+			},
+			expected: map[string][]string{
+				"default/db":  {"StatefulSet default/db: FailedCreate occurred 1 time(s): create Pod db-0 failed"},
+				"default/web": {"Pod default/web: FailedScheduling occurred 7 time(s): 0/3 nodes are available: 3 Insufficient memory.", "Pod default/web: FailedMount occurred 1 time(s): MountVolume.SetUp failed for volume \"config\""},
+			},
+			severity: []common.Severity{common.SeverityMedium, common.SeverityLow, common.SeverityLow},
+		},
+		{
+			name: "warnings older than the lookback",
+			objects: []runtime.Object{
+				recentEvent("web.1", "Pod", "web", "BackOff", "Back-off restarting failed container", 20, 2*time.Hour),
+			},
+			expected: map[string][]string{},
+		},
+		{
+			name:     "configured lookback",
+			lookback: 3 * time.Hour,
+			objects: []runtime.Object{
+				recentEvent("web.1", "Pod", "web", "BackOff", "Back-off restarting failed container", 20, 2*time.Hour),
+			},
+			expected: map[string][]string{
+				"default/web": {"Pod default/web: BackOff occurred 20 time(s): Back-off restarting failed container"},
+			},
+			severity: []common.Severity{common.SeverityMedium},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := common.Analyzer{
+				Client: &kubernetes.Client{
+					Client: fake.NewSimpleClientset(tt.objects...),
+				},
+				Context:       context.Background(),
+				Namespace:     "default",
+				EventLookback: tt.lookback,
+			}
+
+			results, err := EventAnalyzer{}.Analyze(config)
+			require.NoError(t, err)
+
+			got := map[string][]string{}
+			var severity []common.Severity
+			for _, result := range results {
+				require.Equal(t, "Event", result.Kind)
+				for _, failure := range result.Error {
+					got[result.Name] = append(got[result.Name], failure.Text)
+					severity = append(severity, failure.Severity)
+				}
+			}
+			require.Equal(t, tt.expected, got)
+			require.Equal(t, tt.severity, severity)
+		})
+	}
+}
+
+func TestEventAnalyzerMostFrequentReasons(t *testing.T) {
+	var objects []runtime.Object
+	for i, reason := range []string{"A", "B", "C", "D", "E", "F", "G"} {
+		objects = append(objects, recentEvent("web."+reason, "Pod", "web", reason, "failed", int32(i+1), time.Minute))
+	}
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(objects...),
+		},
+		Context:   context.Background(),
+		Namespace: "default",
+	}
+
+	results, err := EventAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Len(t, results[0].Error, maxEventReasons)
+	require.Equal(t, "Pod default/web: G occurred 7 time(s): failed", results[0].Error[0].Text)
+	require.Equal(t, "Pod default/web: C occurred 3 time(s): failed", results[0].Error[maxEventReasons-1].Text)
+}
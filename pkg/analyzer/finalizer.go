@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Objects still deleting this long after their deletionTimestamp are reported.
+const finalizerGracePeriod = 15 * time.Minute
+
+// FinalizerAnalyzer reports objects of any kind, including custom resources,
+// whose deletion has been blocked by finalizers for longer than a grace
+// period, usually because the controller owning the finalizer is gone.
+type FinalizerAnalyzer struct{}
+
+func (FinalizerAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	analyzerName := "Finalizer"
+
+	snapshot := newAnalyzerErrorsSnapshot(analyzerName)
+
+	resources, err := finalizerResources(a.Client.GetClient().Discovery(), a.Namespace != "")
+	if err != nil {
+		return nil, err
+	}
+
+	labelSelector := util.LabelStrToSelector(a.LabelSelector)
+	for _, gvk := range resources {
+		list := &metav1.PartialObjectMetadataList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		// Objects the user can't list are skipped rather than failing the analyzer.
+		listOptions := &ctrl.ListOptions{
+			Namespace:     a.Namespace,
+			LabelSelector: labelSelector,
+			FieldSelector: util.FieldStrToSelector(gvk.Kind, a.FieldSelector),
+		}
+		if err := a.Client.CtrlClient.List(a.Context, list, listOptions); err != nil {
+			continue
+		}
+
+		for _, object := range list.Items {
+			if object.DeletionTimestamp == nil || len(object.Finalizers) == 0 ||
+				time.Since(object.DeletionTimestamp.Time) < finalizerGracePeriod {
+				continue
+			}
+
+			name := object.Name
+			if object.Namespace != "" {
+				name = fmt.Sprintf("%s/%s", object.Namespace, object.Name)
+			}
+			failures := []common.Failure{
+				{
+					Text: fmt.Sprintf("%s %s has been deleting since %s but is blocked by finalizers %s; check that the controllers owning them are running",
+						gvk.Kind, name, object.DeletionTimestamp.UTC().Format(time.RFC3339), strings.Join(object.Finalizers, ", ")),
+					Sensitive: []common.Sensitive{
+						{
+							Unmasked: object.Name,
+							Masked:   util.MaskString(object.Name),
+						},
+					},
+					Severity: common.SeverityMedium,
+				},
+			}
+			snapshot.Set(object.Name, object.Namespace, float64(len(object.Finalizers)))
+			a.Results = append(a.Results, common.Result{
+				Kind:  gvk.Kind,
+				Name:  name,
+				Error: failures,
+			})
+		}
+	}
+
+	snapshot.Apply()
+	return a.Results, nil
+}
+
+// finalizerResources returns the listable kinds served by the cluster, in
+// their preferred version. Only namespaced kinds are returned when the
+// analysis is scoped to a namespace. Events are skipped: they are numerous
+// and never carry finalizers.
+func finalizerResources(client discovery.DiscoveryInterface, namespacedOnly bool) ([]schema.GroupVersionKind, error) {
+	lists, err := discovery.ServerPreferredResources(client)
+	// Partial discovery failures (e.g. an unavailable aggregated API) still
+	// return the other groups.
+	if err != nil && len(lists) == 0 {
+		return nil, err
+	}
+
+	var resources []schema.GroupVersionKind
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range list.APIResources {
+			if strings.Contains(resource.Name, "/") || resource.Name == "events" ||
+				(namespacedOnly && !resource.Namespaced) || !hasVerb(resource.Verbs, "list") {
+				continue
+			}
+			resources = append(resources, gv.WithKind(resource.Kind))
+		}
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].String() < resources[j].String()
+	})
+	return resources, nil
+}
+
+func hasVerb(verbs metav1.Verbs, verb string) bool {
+	for _, v := range verbs {
+		if v == verb {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestFinalizerAnalyzer(t *testing.T) {
+	widgetGVK := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}
+	widget := func(name string, deleting time.Duration, finalizers ...string) *metav1.PartialObjectMetadata {
+		object := &metav1.PartialObjectMetadata{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       name,
+				Namespace:  "default",
+				Finalizers: finalizers,
+			},
+		}
+		object.SetGroupVersionKind(widgetGVK)
+		if deleting > 0 {
+			deletionTimestamp := metav1.NewTime(time.Now().Add(-deleting))
+			object.DeletionTimestamp = &deletionTimestamp
+		}
+		return object
+	}
+
+	clientset := fake.NewSimpleClientset()
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "example.com/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "widgets", Kind: "Widget", Namespaced: true, Verbs: metav1.Verbs{"get", "list"}},
+				{Name: "widgets/status", Kind: "Widget", Namespaced: true, Verbs: metav1.Verbs{"get"}},
+			},
+		},
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "nodes", Kind: "Node", Namespaced: false, Verbs: metav1.Verbs{"list"}},
+			},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(widgetGVK, &metav1.PartialObjectMetadata{})
+	scheme.AddKnownTypeWithName(widgetGVK.GroupVersion().WithKind("WidgetList"), &metav1.PartialObjectMetadataList{})
+	ctrlClient := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(
+		widget("stuck", time.Hour, "example.com/cleanup", "example.com/backup"),
+		widget("deleting", time.Minute, "example.com/cleanup"),
+		widget("healthy", 0, "example.com/cleanup"),
+	).Build()
+
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client:     clientset,
+			CtrlClient: ctrlClient,
+		},
+		Context:   context.Background(),
+		Namespace: "default",
+	}
+
+	results, err := FinalizerAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "Widget", results[0].Kind)
+	require.Equal(t, "default/stuck", results[0].Name)
+	require.Contains(t, results[0].Error[0].Text, "blocked by finalizers example.com/cleanup, example.com/backup")
+}
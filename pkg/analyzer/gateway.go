@@ -30,9 +30,7 @@ type GatewayAnalyzer struct{}
 func (GatewayAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 
 	kind := "Gateway"
-	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
-		"analyzer_name": kind,
-	})
+	snapshot := newAnalyzerErrorsSnapshot(kind)
 
 	gtwList := &gtwapi.GatewayList{}
 	gc := &gtwapi.GatewayClass{}
@@ -43,7 +41,7 @@ func (GatewayAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 	}
 
 	labelSelector := util.LabelStrToSelector(a.LabelSelector)
-	if err := client.List(a.Context, gtwList, &ctrl.ListOptions{LabelSelector: labelSelector}); err != nil {
+	if err := client.List(a.Context, gtwList, &ctrl.ListOptions{LabelSelector: labelSelector, FieldSelector: util.FieldStrToSelector("Gateway", a.FieldSelector)}); err != nil {
 		return nil, err
 	}
 
@@ -98,7 +96,7 @@ func (GatewayAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				Gateway:        gtw,
 				FailureDetails: failures,
 			}
-			AnalyzerErrorsMetric.WithLabelValues(kind, gtwName, gtwNamespace).Set(float64(len(failures)))
+			snapshot.Set(gtwName, gtwNamespace, float64(len(failures)))
 		}
 	}
 	for key, value := range preAnalysis {
@@ -109,5 +107,6 @@ func (GatewayAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		}
 		a.Results = append(a.Results, currentAnalysis)
 	}
+	snapshot.Apply()
 	return a.Results, nil
 }
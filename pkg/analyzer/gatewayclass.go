@@ -29,9 +29,7 @@ type GatewayClassAnalyzer struct{}
 func (GatewayClassAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 
 	kind := "GatewayClass"
-	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
-		"analyzer_name": kind,
-	})
+	snapshot := newAnalyzerErrorsSnapshot(kind)
 
 	gcList := &gtwapi.GatewayClassList{}
 	client := a.Client.CtrlClient
@@ -41,7 +39,7 @@ func (GatewayClassAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 	}
 
 	labelSelector := util.LabelStrToSelector(a.LabelSelector)
-	if err := client.List(a.Context, gcList, &ctrl.ListOptions{LabelSelector: labelSelector}); err != nil {
+	if err := client.List(a.Context, gcList, &ctrl.ListOptions{LabelSelector: labelSelector, FieldSelector: util.FieldStrToSelector("GatewayClass", a.FieldSelector)}); err != nil {
 		return nil, err
 	}
 	var preAnalysis = map[string]common.PreAnalysis{}
@@ -74,7 +72,7 @@ func (GatewayClassAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 				GatewayClass:   gc,
 				FailureDetails: failures,
 			}
-			AnalyzerErrorsMetric.WithLabelValues(kind, gcName, "").Set(float64(len(failures)))
+			snapshot.Set(gcName, "", float64(len(failures)))
 		}
 	}
 	for key, value := range preAnalysis {
@@ -85,5 +83,6 @@ func (GatewayClassAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 		}
 		a.Results = append(a.Results, currentAnalysis)
 	}
+	snapshot.Apply()
 	return a.Results, nil
 }
@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/integration"
+	"github.com/spf13/viper"
+)
+
+// filterGroups name sets of filters that can be given instead of the filters,
+// e.g. --filter workloads.
+var filterGroups = map[string][]string{
+	"workloads":  {"Pod", "Deployment", "ReplicaSet", "StatefulSet", "DaemonSet", "CronJob", "Job"},
+	"networking": {"Service", "Ingress", "NetworkPolicy", "Gateway", "HTTPRoute"},
+}
+
+// FilterGroups returns the filter groups by name. Groups set with the
+// filter_groups configuration key are added to the built-in ones, replacing
+// those of the same name. Group names are case insensitive.
+func FilterGroups() map[string][]string {
+	groups := make(map[string][]string, len(filterGroups))
+	for name, filters := range filterGroups {
+		groups[name] = filters
+	}
+	for name, filters := range viper.GetStringMapStringSlice("filter_groups") {
+		groups[strings.ToLower(name)] = filters
+	}
+	return groups
+}
+
+// FilterGroupNames returns the sorted names of the filter groups.
+func FilterGroupNames() []string {
+	groups := FilterGroups()
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ExpandFilterGroups replaces the names of filter groups by their filters,
+// keeping the first occurrence of each filter. Filters are matched first, so
+// a group can't hide one. Names that are neither a filter of analyzers nor a
+// group are returned apart.
+func ExpandFilterGroups(filters []string, analyzers map[string]common.IAnalyzer) ([]string, []string) {
+	groups := FilterGroups()
+	seen := map[string]bool{}
+	var expanded, unknown []string
+	add := func(filter string) {
+		if _, ok := analyzers[filter]; !ok {
+			unknown = append(unknown, filter)
+			return
+		}
+		if !seen[filter] {
+			seen[filter] = true
+			expanded = append(expanded, filter)
+		}
+	}
+	for _, filter := range filters {
+		if _, ok := analyzers[filter]; ok {
+			add(filter)
+			continue
+		}
+		group, ok := groups[strings.ToLower(filter)]
+		if !ok {
+			unknown = append(unknown, filter)
+			continue
+		}
+		for _, member := range group {
+			add(member)
+		}
+	}
+	return expanded, unknown
+}
+
+// ExcludeFilters removes the excluded analyzers from filters. Exclusions are
+// filters of analyzers, groups, or integrations standing for all their
+// analyzers, so an integration can stay active without one of its analyzers,
+// e.g. --exclude ClusterPolicyReport. Exclusions that are none of them are
+// returned apart.
+func ExcludeFilters(filters []string, exclusions []string, analyzers map[string]common.IAnalyzer) ([]string, []string) {
+	integrationProvider := integration.NewIntegration()
+	var names, unknown []string
+	for _, exclusion := range exclusions {
+		if _, ok := analyzers[exclusion]; !ok {
+			if in, err := integrationProvider.Get(strings.ToLower(exclusion)); err == nil {
+				names = append(names, in.GetAnalyzerName()...)
+				continue
+			}
+		}
+		expanded, notFound := ExpandFilterGroups([]string{exclusion}, analyzers)
+		names = append(names, expanded...)
+		unknown = append(unknown, notFound...)
+	}
+
+	var kept []string
+	for _, filter := range filters {
+		if !slices.Contains(names, filter) {
+			kept = append(kept, filter)
+		}
+	}
+	return kept, unknown
+}
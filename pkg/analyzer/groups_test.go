@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandFilterGroups(t *testing.T) {
+	_, analyzers := GetAnalyzerMap()
+
+	tests := []struct {
+		name            string
+		filters         []string
+		configGroups    map[string][]string
+		expectedFilters []string
+		expectedUnknown []string
+	}{
+		{
+			name:            "filters only",
+			filters:         []string{"Pod", "Service"},
+			expectedFilters: []string{"Pod", "Service"},
+		},
+		{
+			name:            "built-in group with a filter it holds",
+			filters:         []string{"Service", "networking"},
+			expectedFilters: []string{"Service", "Ingress", "NetworkPolicy", "Gateway", "HTTPRoute"},
+		},
+		{
+			name:            "group names are case insensitive",
+			filters:         []string{"Workloads"},
+			expectedFilters: []string{"Pod", "Deployment", "ReplicaSet", "StatefulSet", "DaemonSet", "CronJob", "Job"},
+		},
+		{
+			name:            "unknown names",
+			filters:         []string{"Pod", "storage", "Unknown"},
+			expectedFilters: []string{"Pod"},
+			expectedUnknown: []string{"storage", "Unknown"},
+		},
+		{
+			name:    "configured groups",
+			filters: []string{"storage", "networking"},
+			configGroups: map[string][]string{
+				"Storage":    {"PersistentVolumeClaim", "PersistentVolume", "Unknown"},
+				"networking": {"Service"},
+			},
+			expectedFilters: []string{"PersistentVolumeClaim", "PersistentVolume", "Service"},
+			expectedUnknown: []string{"Unknown"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Set("filter_groups", tt.configGroups)
+			defer viper.Set("filter_groups", nil)
+
+			filters, unknown := ExpandFilterGroups(tt.filters, analyzers)
+			require.Equal(t, tt.expectedFilters, filters)
+			require.Equal(t, tt.expectedUnknown, unknown)
+		})
+	}
+}
+
+func TestFilterGroupNames(t *testing.T) {
+	require.Equal(t, []string{"networking", "workloads"}, FilterGroupNames())
+
+	viper.Set("filter_groups", map[string][]string{"Storage": {"PersistentVolume"}})
+	defer viper.Set("filter_groups", nil)
+	require.Equal(t, []string{"networking", "storage", "workloads"}, FilterGroupNames())
+}
+
+func TestExcludeFilters(t *testing.T) {
+	_, analyzers := GetAnalyzerMap()
+	// The Kyverno integration adds these analyzers when active.
+	analyzers["PolicyReport"] = nil
+	analyzers["ClusterPolicyReport"] = nil
+
+	tests := []struct {
+		name            string
+		filters         []string
+		exclusions      []string
+		expectedFilters []string
+		expectedUnknown []string
+	}{
+		{
+			name:            "analyzer",
+			filters:         []string{"Pod", "Service", "Node"},
+			exclusions:      []string{"Node"},
+			expectedFilters: []string{"Pod", "Service"},
+		},
+		{
+			name:            "group",
+			filters:         []string{"Pod", "Service", "Ingress", "Node"},
+			exclusions:      []string{"networking"},
+			expectedFilters: []string{"Pod", "Node"},
+		},
+		{
+			name:            "analyzer of an integration",
+			filters:         []string{"Pod", "PolicyReport", "ClusterPolicyReport"},
+			exclusions:      []string{"ClusterPolicyReport"},
+			expectedFilters: []string{"Pod", "PolicyReport"},
+		},
+		{
+			name:            "integration",
+			filters:         []string{"Pod", "PolicyReport", "ClusterPolicyReport"},
+			exclusions:      []string{"Kyverno"},
+			expectedFilters: []string{"Pod"},
+		},
+		{
+			name:            "unknown names",
+			filters:         []string{"Pod"},
+			exclusions:      []string{"Unknown", "Pod"},
+			expectedUnknown: []string{"Unknown"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filters, unknown := ExcludeFilters(tt.filters, tt.exclusions, analyzers)
+			require.Equal(t, tt.expectedFilters, filters)
+			require.Equal(t, tt.expectedUnknown, unknown)
+		})
+	}
+}
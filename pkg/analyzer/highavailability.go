@@ -0,0 +1,227 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// HighAvailabilityAnalyzer reports multi-replica Deployments and StatefulSets
+// that are not spread across nodes or zones, so a single node or zone outage
+// takes down every replica.
+type HighAvailabilityAnalyzer struct{}
+
+// haWorkload is the part of a Deployment or StatefulSet the analyzer needs.
+type haWorkload struct {
+	kind      string
+	namespace string
+	name      string
+	replicas  int32
+	selector  *metav1.LabelSelector
+	template  v1.PodTemplateSpec
+}
+
+func (HighAvailabilityAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	analyzerName := "HighAvailability"
+
+	snapshot := newAnalyzerErrorsSnapshot(analyzerName)
+
+	var workloads []haWorkload
+
+	deployments, err := a.Client.GetClient().AppsV1().Deployments(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor("Deployment", a.FieldSelector)})
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range deployments.Items {
+		workloads = append(workloads, haWorkload{
+			kind:      "Deployment",
+			namespace: deployment.Namespace,
+			name:      deployment.Name,
+			replicas:  replicasOrDefault(deployment.Spec.Replicas),
+			selector:  deployment.Spec.Selector,
+			template:  deployment.Spec.Template,
+		})
+	}
+
+	statefulSets, err := a.Client.GetClient().AppsV1().StatefulSets(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor("StatefulSet", a.FieldSelector)})
+	if err != nil {
+		return nil, err
+	}
+	for _, sts := range statefulSets.Items {
+		workloads = append(workloads, haWorkload{
+			kind:      "StatefulSet",
+			namespace: sts.Namespace,
+			name:      sts.Name,
+			replicas:  replicasOrDefault(sts.Spec.Replicas),
+			selector:  sts.Spec.Selector,
+			template:  sts.Spec.Template,
+		})
+	}
+
+	var pods []v1.Pod
+	var zoneOf map[string]string
+	var clusterZones int
+	listed := false
+
+	for _, workload := range workloads {
+		if workload.replicas <= 1 {
+			continue
+		}
+
+		// Pods and nodes are only needed once a multi-replica workload exists.
+		if !listed {
+			listed = true
+			podList, err := a.Client.GetClient().CoreV1().Pods(a.Namespace).List(a.Context, metav1.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			pods = podList.Items
+			// Zones are best effort: without node access only nodes are compared.
+			if nodeList, err := a.Client.GetClient().CoreV1().Nodes().List(a.Context, metav1.ListOptions{}); err == nil {
+				zoneOf, clusterZones = nodeZones(nodeList.Items)
+			}
+		}
+
+		nodes, zones, scheduled := observedSpread(workload, pods, zoneOf)
+
+		var failures []common.Failure
+		addFailure := func(text string) {
+			failures = append(failures, common.Failure{
+				Text: text,
+				Sensitive: []common.Sensitive{
+					{
+						Unmasked: workload.namespace,
+						Masked:   util.MaskString(workload.namespace),
+					},
+					{
+						Unmasked: workload.name,
+						Masked:   util.MaskString(workload.name),
+					},
+				},
+				Severity: common.SeverityMedium,
+			})
+		}
+
+		if !hasSpreadConstraints(workload.template.Spec) {
+			addFailure(fmt.Sprintf("%s %s/%s has %d replicas but no pod anti-affinity or topology spread constraints; its pods are running on %d node(s) and %d zone(s)",
+				workload.kind, workload.namespace, workload.name, workload.replicas, len(nodes), len(zones)))
+		}
+		if len(nodes) == 1 && scheduled > 1 {
+			addFailure(fmt.Sprintf("all scheduled replicas of %s %s/%s are running on node %s",
+				workload.kind, workload.namespace, workload.name, firstKey(nodes)))
+		} else if len(zones) == 1 && clusterZones > 1 {
+			addFailure(fmt.Sprintf("all scheduled replicas of %s %s/%s are running in zone %s although the cluster spans %d zones",
+				workload.kind, workload.namespace, workload.name, firstKey(zones), clusterZones))
+		}
+
+		if len(failures) > 0 {
+			snapshot.Set(workload.name, workload.namespace, float64(len(failures)))
+			a.Results = append(a.Results, common.Result{
+				Kind:  workload.kind,
+				Name:  fmt.Sprintf("%s/%s", workload.namespace, workload.name),
+				Error: failures,
+			})
+		}
+	}
+
+	snapshot.Apply()
+	return a.Results, nil
+}
+
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// hasSpreadConstraints reports whether the pod spec asks the scheduler to
+// spread its replicas, either through pod anti-affinity or topology spread.
+func hasSpreadConstraints(spec v1.PodSpec) bool {
+	if len(spec.TopologySpreadConstraints) > 0 {
+		return true
+	}
+	if spec.Affinity == nil || spec.Affinity.PodAntiAffinity == nil {
+		return false
+	}
+	antiAffinity := spec.Affinity.PodAntiAffinity
+	return len(antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution) > 0 ||
+		len(antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution) > 0
+}
+
+// nodeZones maps node names to their zone and counts the distinct zones.
+func nodeZones(nodes []v1.Node) (map[string]string, int) {
+	zoneOf := map[string]string{}
+	zones := map[string]struct{}{}
+	for _, node := range nodes {
+		zone := node.Labels[v1.LabelTopologyZone]
+		if zone == "" {
+			continue
+		}
+		zoneOf[node.Name] = zone
+		zones[zone] = struct{}{}
+	}
+	return zoneOf, len(zones)
+}
+
+// observedSpread returns the nodes and zones the workload's pods are running
+// on, and how many of its pods are scheduled.
+func observedSpread(workload haWorkload, pods []v1.Pod, zoneOf map[string]string) (map[string]struct{}, map[string]struct{}, int) {
+	nodes := map[string]struct{}{}
+	zones := map[string]struct{}{}
+	var scheduled int
+	for _, pod := range workloadPods(workload, pods) {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		scheduled++
+		nodes[pod.Spec.NodeName] = struct{}{}
+		if zone, ok := zoneOf[pod.Spec.NodeName]; ok {
+			zones[zone] = struct{}{}
+		}
+	}
+	return nodes, zones, scheduled
+}
+
+func workloadPods(workload haWorkload, pods []v1.Pod) []v1.Pod {
+	if workload.selector == nil {
+		return nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(workload.selector)
+	if err != nil || selector.Empty() {
+		return nil
+	}
+	var matching []v1.Pod
+	for _, pod := range pods {
+		if pod.Namespace == workload.namespace && pod.DeletionTimestamp == nil && selector.Matches(labels.Set(pod.Labels)) {
+			matching = append(matching, pod)
+		}
+	}
+	return matching
+}
+
+// firstKey returns the only key of a single-element set.
+func firstKey(set map[string]struct{}) string {
+	for key := range set {
+		return key
+	}
+	return ""
+}
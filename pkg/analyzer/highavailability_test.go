@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+)
+
+func TestHighAvailabilityAnalyzer(t *testing.T) {
+	template := func(app string, spec v1.PodSpec) v1.PodTemplateSpec {
+		return v1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": app}},
+			Spec:       spec,
+		}
+	}
+	deployment := func(name string, replicas int32, spec v1.PodSpec) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: ptr.To(replicas),
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+				Template: template(name, spec),
+			},
+		}
+	}
+	pod := func(name string, app string, node string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": app}},
+			Spec:       v1.PodSpec{NodeName: node},
+		}
+	}
+	node := func(name string, zone string) *v1.Node {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{v1.LabelTopologyZone: zone}},
+		}
+	}
+	spread := v1.PodSpec{
+		TopologySpreadConstraints: []v1.TopologySpreadConstraint{
+			{MaxSkew: 1, TopologyKey: v1.LabelTopologyZone, WhenUnsatisfiable: v1.ScheduleAnyway},
+		},
+	}
+
+	clientset := fake.NewSimpleClientset(
+		node("node-a", "zone-1"),
+		node("node-b", "zone-1"),
+		node("node-c", "zone-2"),
+		// No spreading configured and both replicas on one node.
+		deployment("web", 2, v1.PodSpec{}),
+		pod("web-1", "web", "node-a"),
+		pod("web-2", "web", "node-a"),
+		// Spreading is preferred only and the replicas ended up in one zone.
+		deployment("api", 2, spread),
+		pod("api-1", "api", "node-a"),
+		pod("api-2", "api", "node-b"),
+		// Spread across zones.
+		deployment("cache", 2, spread),
+		pod("cache-1", "cache", "node-a"),
+		pod("cache-2", "cache", "node-c"),
+		// A single replica has nothing to spread.
+		deployment("job", 1, v1.PodSpec{}),
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas: ptr.To(int32(3)),
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+				Template: template("db", v1.PodSpec{
+					Affinity: &v1.Affinity{
+						PodAntiAffinity: &v1.PodAntiAffinity{
+							RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{
+								{TopologyKey: v1.LabelHostname},
+							},
+						},
+					},
+				}),
+			},
+		},
+		pod("db-0", "db", "node-a"),
+		pod("db-1", "db", "node-b"),
+		pod("db-2", "db", "node-c"),
+	)
+
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: clientset,
+		},
+		Context:   context.Background(),
+		Namespace: "default",
+	}
+
+	results, err := HighAvailabilityAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+
+	var texts []string
+	for _, result := range results {
+		for _, failure := range result.Error {
+			require.Equal(t, common.SeverityMedium, failure.Severity)
+			texts = append(texts, failure.Text)
+		}
+	}
+	require.ElementsMatch(t, []string{
+		"Deployment default/web has 2 replicas but no pod anti-affinity or topology spread constraints; its pods are running on 1 node(s) and 1 zone(s)",
+		"all scheduled replicas of Deployment default/web are running on node node-a",
+		"all scheduled replicas of Deployment default/api are running in zone zone-1 although the cluster spans 2 zones",
+	}, texts)
+}
+
+func TestHighAvailabilityAnalyzerWithoutNodeAccess(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: ptr.To(int32(2)),
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			},
+		},
+	)
+	clientset.PrependReactor("list", "nodes", func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("nodes is forbidden")
+	})
+
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: clientset,
+		},
+		Context:   context.Background(),
+		Namespace: "default",
+	}
+
+	results, err := HighAvailabilityAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "Deployment default/web has 2 replicas but no pod anti-affinity or topology spread constraints; its pods are running on 0 node(s) and 0 zone(s)", results[0].Error[0].Text)
+}
@@ -15,11 +15,13 @@ package analyzer
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -39,11 +41,9 @@ func (HpaAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		OpenapiSchema: a.OpenapiSchema,
 	}
 
-	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
-		"analyzer_name": kind,
-	})
+	snapshot := newAnalyzerErrorsSnapshot(kind)
 
-	list, err := a.Client.GetClient().AutoscalingV2().HorizontalPodAutoscalers(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector})
+	list, err := a.Client.GetClient().AutoscalingV2().HorizontalPodAutoscalers(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor("HorizontalPodAutoscaler", a.FieldSelector)})
 	if err != nil {
 		return nil, err
 	}
@@ -51,11 +51,24 @@ func (HpaAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 	var preAnalysis = map[string]common.PreAnalysis{}
 
 	for _, hpa := range list.Items {
+		if util.CreatedWithin(hpa.ObjectMeta, a.MinAge) {
+			continue
+		}
 		var failures []common.Failure
 
 		//check the error from status field
 		conditions := hpa.Status.Conditions
 		for _, condition := range conditions {
+			if condition.Type == autoscalingv2.ScalingActive && condition.Status == corev1.ConditionFalse && hpaMetricUnavailableReasons[condition.Reason] {
+				// Without its metrics an HPA silently keeps its current replicas.
+				failures = append(failures, common.Failure{
+					Text: fmt.Sprintf("HorizontalPodAutoscaler can't scale as its metrics are unavailable (%s): %s; metrics: %s",
+						condition.Reason, condition.Message, describeHPAMetrics(hpa)),
+					Sensitive: []common.Sensitive{},
+					Severity:  common.SeverityHigh,
+				})
+				continue
+			}
 			if condition.Status != "True" {
 				failures = append(failures, common.Failure{
 					Text:      condition.Message,
@@ -64,6 +77,16 @@ func (HpaAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 			}
 		}
 
+		if saturated, reason := hpaSaturated(hpa); saturated {
+			failures = append(failures, common.Failure{
+				Text: fmt.Sprintf("HorizontalPodAutoscaler is saturated at its maximum of %d replicas (desired %d%s); metrics: %s",
+					hpa.Spec.MaxReplicas, max(hpa.Status.DesiredReplicas, hpa.Status.CurrentReplicas), reason, describeHPAMetrics(hpa)),
+				Sensitive: []common.Sensitive{},
+				Severity:  common.SeverityMedium,
+				FieldPath: "spec.maxReplicas",
+			})
+		}
+
 		// check ScaleTargetRef exist
 		scaleTargetRef := hpa.Spec.ScaleTargetRef
 		var podInfo PodInfo
@@ -139,7 +162,7 @@ func (HpaAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				HorizontalPodAutoscalers: hpa,
 				FailureDetails:           failures,
 			}
-			AnalyzerErrorsMetric.WithLabelValues(kind, hpa.Name, hpa.Namespace).Set(float64(len(failures)))
+			snapshot.Set(hpa.Name, hpa.Namespace, float64(len(failures)))
 		}
 
 	}
@@ -158,9 +181,115 @@ func (HpaAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		a.Results = append(a.Results, currentAnalysis)
 	}
 
+	snapshot.Apply()
 	return a.Results, nil
 }
 
+// hpaMetricUnavailableReasons are the reasons of a false ScalingActive
+// condition when the metrics of an HPA can't be read, e.g. as the metrics API
+// is unavailable.
+var hpaMetricUnavailableReasons = map[string]bool{
+	"FailedGetResourceMetric":          true,
+	"FailedGetContainerResourceMetric": true,
+	"FailedGetPodsMetric":              true,
+	"FailedGetObjectMetric":            true,
+	"FailedGetExternalMetric":          true,
+}
+
+// hpaSaturated reports whether an HPA runs its maximum of replicas while it
+// needs more, with the reason of its ScalingLimited condition if any.
+func hpaSaturated(hpa autoscalingv2.HorizontalPodAutoscaler) (bool, string) {
+	if hpa.Spec.MaxReplicas == 0 || hpa.Status.CurrentReplicas < hpa.Spec.MaxReplicas {
+		return false, ""
+	}
+	for _, condition := range hpa.Status.Conditions {
+		if condition.Type == autoscalingv2.ScalingLimited && condition.Status == corev1.ConditionTrue && condition.Reason == "TooManyReplicas" {
+			return true, ", " + condition.Reason
+		}
+	}
+	return hpa.Status.DesiredReplicas > hpa.Status.CurrentReplicas, ""
+}
+
+// describeHPAMetrics returns the current value and the target of each metric
+// of an HPA, e.g. "cpu current 95%, target 80%".
+func describeHPAMetrics(hpa autoscalingv2.HorizontalPodAutoscaler) string {
+	if len(hpa.Spec.Metrics) == 0 {
+		return "none"
+	}
+	var metrics []string
+	for _, spec := range hpa.Spec.Metrics {
+		name, target := hpaMetricSpec(spec)
+		current := "<unknown>"
+		for _, status := range hpa.Status.CurrentMetrics {
+			if statusName, value := hpaMetricStatus(status); status.Type == spec.Type && statusName == name && value != "" {
+				current = value
+				break
+			}
+		}
+		metrics = append(metrics, fmt.Sprintf("%s current %s, target %s", name, current, target))
+	}
+	return strings.Join(metrics, "; ")
+}
+
+// hpaMetricSpec returns the name and the target of a metric of an HPA.
+func hpaMetricSpec(spec autoscalingv2.MetricSpec) (string, string) {
+	switch {
+	case spec.Resource != nil:
+		return string(spec.Resource.Name), hpaMetricTarget(spec.Resource.Target)
+	case spec.ContainerResource != nil:
+		return fmt.Sprintf("%s of container %s", spec.ContainerResource.Name, spec.ContainerResource.Container), hpaMetricTarget(spec.ContainerResource.Target)
+	case spec.Pods != nil:
+		return spec.Pods.Metric.Name, hpaMetricTarget(spec.Pods.Target)
+	case spec.Object != nil:
+		return spec.Object.Metric.Name, hpaMetricTarget(spec.Object.Target)
+	case spec.External != nil:
+		return spec.External.Metric.Name, hpaMetricTarget(spec.External.Target)
+	}
+	return string(spec.Type), "<unknown>"
+}
+
+// hpaMetricStatus returns the name and the current value of a metric of an
+// HPA, the value being empty when unknown.
+func hpaMetricStatus(status autoscalingv2.MetricStatus) (string, string) {
+	switch {
+	case status.Resource != nil:
+		return string(status.Resource.Name), hpaMetricValue(status.Resource.Current)
+	case status.ContainerResource != nil:
+		return fmt.Sprintf("%s of container %s", status.ContainerResource.Name, status.ContainerResource.Container), hpaMetricValue(status.ContainerResource.Current)
+	case status.Pods != nil:
+		return status.Pods.Metric.Name, hpaMetricValue(status.Pods.Current)
+	case status.Object != nil:
+		return status.Object.Metric.Name, hpaMetricValue(status.Object.Current)
+	case status.External != nil:
+		return status.External.Metric.Name, hpaMetricValue(status.External.Current)
+	}
+	return string(status.Type), ""
+}
+
+func hpaMetricTarget(target autoscalingv2.MetricTarget) string {
+	switch {
+	case target.AverageUtilization != nil:
+		return fmt.Sprintf("%d%%", *target.AverageUtilization)
+	case target.AverageValue != nil:
+		return fmt.Sprintf("%s average", target.AverageValue.String())
+	case target.Value != nil:
+		return target.Value.String()
+	}
+	return "<unknown>"
+}
+
+func hpaMetricValue(value autoscalingv2.MetricValueStatus) string {
+	switch {
+	case value.AverageUtilization != nil:
+		return fmt.Sprintf("%d%%", *value.AverageUtilization)
+	case value.AverageValue != nil:
+		return fmt.Sprintf("%s average", value.AverageValue.String())
+	case value.Value != nil:
+		return value.Value.String()
+	}
+	return ""
+}
+
 type PodInfo interface {
 	GetPodSpec() corev1.PodSpec
 }
@@ -21,6 +21,7 @@ import (
 	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
 	"github.com/magiconair/properties/assert"
+	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
@@ -735,3 +736,145 @@ func TestHPAAnalyzerStatusField(t *testing.T) {
 	assert.Equal(t, len(analysisResults), 1)
 
 }
+
+func TestHPAAnalyzerMetricsAndSaturation(t *testing.T) {
+	utilization := func(value int32) *int32 { return &value }
+	metrics := []autoscalingv2.MetricSpec{
+		{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{
+				Name:   corev1.ResourceCPU,
+				Target: autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: utilization(80)},
+			},
+		},
+		{
+			Type: autoscalingv2.ExternalMetricSourceType,
+			External: &autoscalingv2.ExternalMetricSource{
+				Metric: autoscalingv2.MetricIdentifier{Name: "queue_depth"},
+				Target: autoscalingv2.MetricTarget{Type: autoscalingv2.ValueMetricType, Value: resource.NewQuantity(100, resource.DecimalSI)},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		status   autoscalingv2.HorizontalPodAutoscalerStatus
+		expected []string
+	}{
+		{
+			name: "metrics unavailable",
+			status: autoscalingv2.HorizontalPodAutoscalerStatus{
+				CurrentReplicas: 2,
+				DesiredReplicas: 2,
+				CurrentMetrics: []autoscalingv2.MetricStatus{
+					{
+						Type: autoscalingv2.ExternalMetricSourceType,
+						External: &autoscalingv2.ExternalMetricStatus{
+							Metric:  autoscalingv2.MetricIdentifier{Name: "queue_depth"},
+							Current: autoscalingv2.MetricValueStatus{Value: resource.NewQuantity(40, resource.DecimalSI)},
+						},
+					},
+				},
+				Conditions: []autoscalingv2.HorizontalPodAutoscalerCondition{
+					{
+						Type:    autoscalingv2.ScalingActive,
+						Status:  corev1.ConditionFalse,
+						Reason:  "FailedGetResourceMetric",
+						Message: "the HPA was unable to compute the replica count: failed to get cpu utilization: unable to get metrics for resource cpu: no metrics returned from resource metrics API",
+					},
+				},
+			},
+			expected: []string{
+				"HorizontalPodAutoscaler can't scale as its metrics are unavailable (FailedGetResourceMetric): the HPA was unable to compute the replica count: " +
+					"failed to get cpu utilization: unable to get metrics for resource cpu: no metrics returned from resource metrics API; " +
+					"metrics: cpu current <unknown>, target 80%; queue_depth current 40, target 100",
+			},
+		},
+		{
+			name: "saturated",
+			status: autoscalingv2.HorizontalPodAutoscalerStatus{
+				CurrentReplicas: 5,
+				DesiredReplicas: 5,
+				CurrentMetrics: []autoscalingv2.MetricStatus{
+					{
+						Type: autoscalingv2.ResourceMetricSourceType,
+						Resource: &autoscalingv2.ResourceMetricStatus{
+							Name:    corev1.ResourceCPU,
+							Current: autoscalingv2.MetricValueStatus{AverageUtilization: utilization(190)},
+						},
+					},
+				},
+				Conditions: []autoscalingv2.HorizontalPodAutoscalerCondition{
+					{
+						Type:    autoscalingv2.ScalingLimited,
+						Status:  corev1.ConditionTrue,
+						Reason:  "TooManyReplicas",
+						Message: "the desired replica count is more than the maximum replica count",
+					},
+				},
+			},
+			expected: []string{
+				"HorizontalPodAutoscaler is saturated at its maximum of 5 replicas (desired 5, TooManyReplicas); metrics: cpu current 190%, target 80%; queue_depth current <unknown>, target 100",
+			},
+		},
+		{
+			name: "at its maximum as needed",
+			status: autoscalingv2.HorizontalPodAutoscalerStatus{
+				CurrentReplicas: 5,
+				DesiredReplicas: 5,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(
+				&autoscalingv2.HorizontalPodAutoscaler{
+					ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default"},
+					Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+						ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "example"},
+						MaxReplicas:    5,
+						Metrics:        metrics,
+					},
+					Status: tt.status,
+				},
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default"},
+					Spec: appsv1.DeploymentSpec{
+						Template: corev1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								Containers: []corev1.Container{
+									{
+										Name: "example",
+										Resources: corev1.ResourceRequirements{
+											Requests: corev1.ResourceList{"cpu": resource.MustParse("100m")},
+											Limits:   corev1.ResourceList{"cpu": resource.MustParse("200m")},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			)
+			config := common.Analyzer{
+				Client: &kubernetes.Client{
+					Client: clientset,
+				},
+				Context:   context.Background(),
+				Namespace: "default",
+			}
+
+			results, err := HpaAnalyzer{}.Analyze(config)
+			require.NoError(t, err)
+
+			var got []string
+			for _, result := range results {
+				for _, failure := range result.Error {
+					got = append(got, failure.Text)
+				}
+			}
+			require.Equal(t, tt.expected, got)
+		})
+	}
+}
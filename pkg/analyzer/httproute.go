@@ -30,9 +30,7 @@ type HTTPRouteAnalyzer struct{}
 func (HTTPRouteAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 
 	kind := "HTTPRoute"
-	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
-		"analyzer_name": kind,
-	})
+	snapshot := newAnalyzerErrorsSnapshot(kind)
 
 	routeList := &gtwapi.HTTPRouteList{}
 	gtw := &gtwapi.Gateway{}
@@ -44,7 +42,7 @@ func (HTTPRouteAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 	}
 
 	labelSelector := util.LabelStrToSelector(a.LabelSelector)
-	if err := client.List(a.Context, routeList, &ctrl.ListOptions{LabelSelector: labelSelector}); err != nil {
+	if err := client.List(a.Context, routeList, &ctrl.ListOptions{LabelSelector: labelSelector, FieldSelector: util.FieldStrToSelector("HTTPRoute", a.FieldSelector)}); err != nil {
 		return nil, err
 	}
 	var preAnalysis = map[string]common.PreAnalysis{}
@@ -216,7 +214,7 @@ func (HTTPRouteAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				HTTPRoute:      route,
 				FailureDetails: failures,
 			}
-			AnalyzerErrorsMetric.WithLabelValues(kind, route.Name, route.Namespace).Set(float64(len(failures)))
+			snapshot.Set(route.Name, route.Namespace, float64(len(failures)))
 		}
 	}
 	for key, value := range preAnalysis {
@@ -227,6 +225,7 @@ func (HTTPRouteAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		}
 		a.Results = append(a.Results, currentAnalysis)
 	}
+	snapshot.Apply()
 	return a.Results, nil
 
 }
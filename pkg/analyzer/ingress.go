@@ -15,6 +15,7 @@ package analyzer
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
@@ -37,29 +38,46 @@ func (IngressAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		OpenapiSchema: a.OpenapiSchema,
 	}
 
-	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
-		"analyzer_name": kind,
-	})
+	snapshot := newAnalyzerErrorsSnapshot(kind)
 
-	list, err := a.Client.GetClient().NetworkingV1().Ingresses(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector})
+	list, err := a.Client.GetClient().NetworkingV1().Ingresses(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor("Ingress", a.FieldSelector)})
 	if err != nil {
 		return nil, err
 	}
 
 	var preAnalysis = map[string]common.PreAnalysis{}
 
+	// The default IngressClass applies to Ingresses without a class. When the
+	// classes can't be listed, whether there is a default is left unsaid.
+	var defaults []string
+	defaultClassText := ""
+	if classes, err := a.Client.GetClient().NetworkingV1().IngressClasses().List(a.Context, metav1.ListOptions{}); err == nil {
+		defaults = defaultIngressClasses(classes.Items)
+		switch len(defaults) {
+		case 0:
+			defaultClassText = " No default IngressClass exists."
+		case 1:
+			defaultClassText = fmt.Sprintf(" The default IngressClass is %s.", defaults[0])
+		default:
+			defaultClassText = fmt.Sprintf(" The default IngressClasses are %s.", strings.Join(defaults, ", "))
+		}
+	}
+
 	for _, ing := range list.Items {
+		if util.CreatedWithin(ing.ObjectMeta, a.MinAge) {
+			continue
+		}
 		var failures []common.Failure
 
 		// get ingressClassName
 		ingressClassName := ing.Spec.IngressClassName
 		if ingressClassName == nil {
-			ingClassValue := ing.Annotations["kubernetes.io/ingress.class"]
-			if ingClassValue == "" {
+			ingClassValue := ing.Annotations[ingressClassAnnotation]
+			if ingClassValue == "" && len(defaults) == 0 {
 				doc := apiDoc.GetApiDocV2("spec.ingressClassName")
 
 				failures = append(failures, common.Failure{
-					Text:          fmt.Sprintf("Ingress %s/%s does not specify an Ingress class.", ing.Namespace, ing.Name),
+					Text:          fmt.Sprintf("Ingress %s/%s does not specify an Ingress class.%s", ing.Namespace, ing.Name, defaultClassText),
 					KubernetesDoc: doc,
 					Sensitive: []common.Sensitive{
 						{
@@ -72,7 +90,7 @@ func (IngressAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 						},
 					},
 				})
-			} else {
+			} else if ingClassValue != "" {
 				ingressClassName = &ingClassValue
 			}
 		}
@@ -84,7 +102,7 @@ func (IngressAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				doc := apiDoc.GetApiDocV2("spec.ingressClassName")
 
 				failures = append(failures, common.Failure{
-					Text:          fmt.Sprintf("Ingress uses the ingress class %s which does not exist.", *ingressClassName),
+					Text:          fmt.Sprintf("Ingress uses the ingress class %s which does not exist.%s", *ingressClassName, defaultClassText),
 					KubernetesDoc: doc,
 					Sensitive: []common.Sensitive{
 						{
@@ -150,7 +168,7 @@ func (IngressAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				Ingress:        ing,
 				FailureDetails: failures,
 			}
-			AnalyzerErrorsMetric.WithLabelValues(kind, ing.Name, ing.Namespace).Set(float64(len(failures)))
+			snapshot.Set(ing.Name, ing.Namespace, float64(len(failures)))
 
 		}
 
@@ -170,5 +188,6 @@ func (IngressAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		a.Results = append(a.Results, currentAnalysis)
 	}
 
+	snapshot.Apply()
 	return a.Results, nil
 }
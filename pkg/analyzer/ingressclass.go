@@ -0,0 +1,147 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	defaultIngressClassAnnotation = "ingressclass.kubernetes.io/is-default-class"
+	ingressClassAnnotation        = "kubernetes.io/ingress.class"
+)
+
+// IngressClassAnalyzer reports IngressClasses whose controller doesn't seem to
+// be running, as none of the Ingresses using the class got an address, and
+// more than one IngressClass marked as the default.
+type IngressClassAnalyzer struct{}
+
+func (IngressClassAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "IngressClass"
+
+	snapshot := newAnalyzerErrorsSnapshot(kind)
+
+	list, err := a.Client.GetClient().NetworkingV1().IngressClasses().List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor(kind, a.FieldSelector)})
+	if err != nil {
+		return nil, err
+	}
+
+	// The default class is only meaningful among all IngressClasses.
+	defaults := defaultIngressClasses(list.Items)
+	if a.LabelSelector == "" && a.FieldSelector == "" && len(defaults) > 1 {
+		failures := []common.Failure{
+			{
+				Text:      fmt.Sprintf("%d IngressClasses are annotated as the default (%s); only one should be, the most recently created one is assigned to new Ingresses without an ingressClassName", len(defaults), strings.Join(defaults, ", ")),
+				Sensitive: []common.Sensitive{},
+				Severity:  common.SeverityMedium,
+			},
+		}
+		snapshot.Set("", "", float64(len(failures)))
+		a.Results = append(a.Results, common.Result{
+			Kind:  kind,
+			Name:  "default IngressClass",
+			Error: failures,
+		})
+	}
+
+	ingresses, err := a.Client.GetClient().NetworkingV1().Ingresses(a.Namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defaultClass := ""
+	if len(defaults) == 1 {
+		defaultClass = defaults[0]
+	}
+	byClass := map[string][]networkingv1.Ingress{}
+	for _, ing := range ingresses.Items {
+		if className := ingressClassOf(ing, defaultClass); className != "" {
+			byClass[className] = append(byClass[className], ing)
+		}
+	}
+
+	for _, ic := range list.Items {
+		if util.CreatedWithin(ic.ObjectMeta, a.MinAge) {
+			continue
+		}
+		var served bool
+		var names []string
+		for _, ing := range byClass[ic.Name] {
+			if len(ing.Status.LoadBalancer.Ingress) > 0 {
+				served = true
+				break
+			}
+			names = append(names, fmt.Sprintf("%s/%s", ing.Namespace, ing.Name))
+		}
+		if served || len(names) == 0 {
+			continue
+		}
+		sort.Strings(names)
+		failures := []common.Failure{
+			{
+				Text: fmt.Sprintf("IngressClass %s uses the controller %s, which doesn't seem to be running: none of the %d Ingress(es) using the class has an address: %s",
+					ic.Name, ic.Spec.Controller, len(names), strings.Join(names, ", ")),
+				Sensitive: []common.Sensitive{
+					{
+						Unmasked: ic.Name,
+						Masked:   util.MaskString(ic.Name),
+					},
+				},
+				FieldPath: "spec.controller",
+				Severity:  common.SeverityHigh,
+			},
+		}
+		snapshot.Set(ic.Name, "", float64(len(failures)))
+		a.Results = append(a.Results, common.Result{
+			Kind:  kind,
+			Name:  ic.Name,
+			Error: failures,
+		})
+	}
+
+	snapshot.Apply()
+	return a.Results, nil
+}
+
+// defaultIngressClasses returns the sorted names of the IngressClasses
+// annotated as the default.
+func defaultIngressClasses(classes []networkingv1.IngressClass) []string {
+	var defaults []string
+	for _, ic := range classes {
+		if ic.Annotations[defaultIngressClassAnnotation] == "true" {
+			defaults = append(defaults, ic.Name)
+		}
+	}
+	sort.Strings(defaults)
+	return defaults
+}
+
+// ingressClassOf returns the class an Ingress uses: its ingressClassName, the
+// deprecated annotation, or else the default class, if any.
+func ingressClassOf(ing networkingv1.Ingress, defaultClass string) string {
+	if ing.Spec.IngressClassName != nil {
+		return *ing.Spec.IngressClassName
+	}
+	if className := ing.Annotations[ingressClassAnnotation]; className != "" {
+		return className
+	}
+	return defaultClass
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func ingressClass(name string, isDefault bool) *networkingv1.IngressClass {
+	ic := &networkingv1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       networkingv1.IngressClassSpec{Controller: "example.com/" + name},
+	}
+	if isDefault {
+		ic.Annotations = map[string]string{defaultIngressClassAnnotation: "true"}
+	}
+	return ic
+}
+
+func classIngress(name string, className string, address bool) *networkingv1.Ingress {
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+	}
+	if className != "" {
+		ing.Spec.IngressClassName = &className
+	}
+	if address {
+		ing.Status.LoadBalancer.Ingress = []networkingv1.IngressLoadBalancerIngress{{IP: "10.0.0.1"}}
+	}
+	return ing
+}
+
+func TestIngressClassAnalyzer(t *testing.T) {
+	tests := []struct {
+		name     string
+		objects  []runtime.Object
+		expected map[string]string
+	}{
+		{
+			name: "class without a running controller",
+			objects: []runtime.Object{
+				ingressClass("nginx", false),
+				classIngress("web", "nginx", false),
+				classIngress("api", "nginx", false),
+			},
+			expected: map[string]string{
+				"nginx": "IngressClass nginx uses the controller example.com/nginx, which doesn't seem to be running: none of the 2 Ingress(es) using the class has an address: default/api, default/web",
+			},
+		},
+		{
+			name: "class serving one of its ingresses",
+			objects: []runtime.Object{
+				ingressClass("nginx", false),
+				classIngress("web", "nginx", true),
+				classIngress("api", "nginx", false),
+			},
+			expected: map[string]string{},
+		},
+		{
+			name: "default class used by ingresses without a class",
+			objects: []runtime.Object{
+				ingressClass("nginx", true),
+				classIngress("web", "", false),
+			},
+			expected: map[string]string{
+				"nginx": "IngressClass nginx uses the controller example.com/nginx, which doesn't seem to be running: none of the 1 Ingress(es) using the class has an address: default/web",
+			},
+		},
+		{
+			name: "unused class",
+			objects: []runtime.Object{
+				ingressClass("nginx", false),
+			},
+			expected: map[string]string{},
+		},
+		{
+			name: "several default classes",
+			objects: []runtime.Object{
+				ingressClass("nginx", true),
+				ingressClass("traefik", true),
+			},
+			expected: map[string]string{
+				"default IngressClass": "2 IngressClasses are annotated as the default (nginx, traefik); only one should be, the most recently created one is assigned to new Ingresses without an ingressClassName",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := common.Analyzer{
+				Client: &kubernetes.Client{
+					Client: fake.NewSimpleClientset(tt.objects...),
+				},
+				Context: context.Background(),
+			}
+
+			results, err := IngressClassAnalyzer{}.Analyze(config)
+			require.NoError(t, err)
+			texts := map[string]string{}
+			for _, result := range results {
+				require.Equal(t, "IngressClass", result.Kind)
+				require.Len(t, result.Error, 1)
+				texts[result.Name] = result.Error[0].Text
+			}
+			require.Equal(t, tt.expected, texts)
+		})
+	}
+}
+
+func TestIngressAnalyzerDefaultClass(t *testing.T) {
+	annotated := classIngress("annotated", "", false)
+	annotated.Annotations = map[string]string{ingressClassAnnotation: "missing"}
+
+	tests := []struct {
+		name     string
+		objects  []runtime.Object
+		expected []string
+	}{
+		{
+			name: "no default class",
+			objects: []runtime.Object{
+				ingressClass("nginx", false),
+				classIngress("classless", "", false),
+				classIngress("unknown", "traefik", false),
+				annotated,
+			},
+			expected: []string{
+				"Ingress default/classless does not specify an Ingress class. No default IngressClass exists.",
+				"Ingress uses the ingress class missing which does not exist. No default IngressClass exists.",
+				"Ingress uses the ingress class traefik which does not exist. No default IngressClass exists.",
+			},
+		},
+		{
+			name: "default class",
+			objects: []runtime.Object{
+				ingressClass("nginx", true),
+				// Uses the default class.
+				classIngress("classless", "", false),
+				classIngress("unknown", "traefik", false),
+			},
+			expected: []string{
+				"Ingress uses the ingress class traefik which does not exist. The default IngressClass is nginx.",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := common.Analyzer{
+				Client: &kubernetes.Client{
+					Client: fake.NewSimpleClientset(tt.objects...),
+				},
+				Context:   context.Background(),
+				Namespace: "default",
+			}
+
+			results, err := IngressAnalyzer{}.Analyze(config)
+			require.NoError(t, err)
+			var texts []string
+			for _, result := range results {
+				for _, failure := range result.Error {
+					texts = append(texts, failure.Text)
+				}
+			}
+			sort.Strings(texts)
+			require.Equal(t, tt.expected, texts)
+		})
+	}
+}
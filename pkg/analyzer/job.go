@@ -0,0 +1,145 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// The backoffLimit of Jobs that don't set one.
+	defaultJobBackoffLimit = 6
+	// Jobs without an activeDeadlineSeconds still running this long without
+	// a completion are reported as stuck.
+	jobStuckThreshold = 24 * time.Hour
+)
+
+type JobAnalyzer struct{}
+
+func (analyzer JobAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "Job"
+	apiDoc := kubernetes.K8sApiReference{
+		Kind: kind,
+		ApiVersion: schema.GroupVersion{
+			Group:   "batch",
+			Version: "v1",
+		},
+		OpenapiSchema: a.OpenapiSchema,
+	}
+
+	snapshot := newAnalyzerErrorsSnapshot(kind)
+
+	jobList, err := a.Client.GetClient().BatchV1().Jobs(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor(kind, a.FieldSelector)})
+	if err != nil {
+		return nil, err
+	}
+
+	var preAnalysis = map[string]common.PreAnalysis{}
+
+	for _, job := range jobList.Items {
+		if util.CreatedWithin(job.ObjectMeta, a.MinAge) {
+			continue
+		}
+		sensitive := []common.Sensitive{
+			{
+				Unmasked: job.Namespace,
+				Masked:   util.MaskString(job.Namespace),
+			},
+			{
+				Unmasked: job.Name,
+				Masked:   util.MaskString(job.Name),
+			},
+		}
+		var failures []common.Failure
+
+		backoffLimit := int32(defaultJobBackoffLimit)
+		if job.Spec.BackoffLimit != nil {
+			backoffLimit = *job.Spec.BackoffLimit
+		}
+		if job.Status.Failed > 0 && job.Status.Failed >= backoffLimit {
+			failures = append(failures, common.Failure{
+				Text:          fmt.Sprintf("Job %s has failed %d times, reaching its backoffLimit of %d", job.Name, job.Status.Failed, backoffLimit),
+				KubernetesDoc: apiDoc.GetApiDocV2("spec.backoffLimit"),
+				FieldPath:     "spec.backoffLimit",
+				Sensitive:     sensitive,
+				Severity:      common.SeverityHigh,
+			})
+		}
+
+		if condition, ok := jobCondition(job, batchv1.JobFailed); ok && condition.Reason == "DeadlineExceeded" {
+			failures = append(failures, common.Failure{
+				Text:          fmt.Sprintf("Job %s was terminated after running past its activeDeadlineSeconds: %s", job.Name, condition.Message),
+				KubernetesDoc: apiDoc.GetApiDocV2("spec.activeDeadlineSeconds"),
+				FieldPath:     "spec.activeDeadlineSeconds",
+				Sensitive:     sensitive,
+				Severity:      common.SeverityHigh,
+			})
+		}
+
+		if job.Status.Active > 0 && job.Status.Succeeded == 0 && job.Spec.ActiveDeadlineSeconds == nil &&
+			job.Status.StartTime != nil && time.Since(job.Status.StartTime.Time) > jobStuckThreshold {
+			failures = append(failures, common.Failure{
+				Text: fmt.Sprintf("Job %s has been active since %s without completing any pod; it may be stuck",
+					job.Name, job.Status.StartTime.UTC().Format(time.RFC3339)),
+				Sensitive: sensitive,
+				Severity:  common.SeverityMedium,
+			})
+		}
+
+		if len(failures) > 0 {
+			preAnalysis[fmt.Sprintf("%s/%s", job.Namespace, job.Name)] = common.PreAnalysis{
+				Job:            job,
+				FailureDetails: failures,
+			}
+			snapshot.Set(job.Name, job.Namespace, float64(len(failures)))
+		}
+	}
+
+	for key, value := range preAnalysis {
+		var currentAnalysis = common.Result{
+			Kind:  kind,
+			Name:  key,
+			Error: value.FailureDetails,
+		}
+
+		parent, found := util.GetParent(a.Client, value.Job.ObjectMeta)
+		if found {
+			currentAnalysis.ParentObject = parent
+		}
+		a.Results = append(a.Results, currentAnalysis)
+	}
+
+	snapshot.Apply()
+	return a.Results, nil
+}
+
+// jobCondition returns the condition of the type if it is true.
+func jobCondition(job batchv1.Job, conditionType batchv1.JobConditionType) (batchv1.JobCondition, bool) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == v1.ConditionTrue {
+			return condition, true
+		}
+	}
+	return batchv1.JobCondition{}, false
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestJobAnalyzer(t *testing.T) {
+	backoffLimit := int32(2)
+	deadline := int64(60)
+	longAgo := metav1.NewTime(time.Now().Add(-48 * time.Hour))
+	recently := metav1.NewTime(time.Now().Add(-time.Minute))
+
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				&batchv1.CronJob{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "nightly",
+						Namespace: "default",
+					},
+				},
+				&batchv1.Job{
+					// Reached its backoffLimit, owned by a CronJob.
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "backoff",
+						Namespace: "default",
+						OwnerReferences: []metav1.OwnerReference{
+							{Kind: "CronJob", Name: "nightly"},
+						},
+					},
+					Spec: batchv1.JobSpec{
+						BackoffLimit: &backoffLimit,
+					},
+					Status: batchv1.JobStatus{
+						Failed: 2,
+					},
+				},
+				&batchv1.Job{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "deadline",
+						Namespace: "default",
+					},
+					Spec: batchv1.JobSpec{
+						ActiveDeadlineSeconds: &deadline,
+					},
+					Status: batchv1.JobStatus{
+						Conditions: []batchv1.JobCondition{
+							{
+								Type:    batchv1.JobFailed,
+								Status:  v1.ConditionTrue,
+								Reason:  "DeadlineExceeded",
+								Message: "Job was active longer than specified deadline",
+							},
+						},
+					},
+				},
+				&batchv1.Job{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "stuck",
+						Namespace: "default",
+					},
+					Status: batchv1.JobStatus{
+						Active:    1,
+						StartTime: &longAgo,
+					},
+				},
+				&batchv1.Job{
+					// Still running within the threshold and retrying below its backoffLimit.
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "running",
+						Namespace: "default",
+					},
+					Status: batchv1.JobStatus{
+						Active:    1,
+						Failed:    1,
+						StartTime: &recently,
+					},
+				},
+				&batchv1.Job{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "complete",
+						Namespace: "default",
+					},
+					Status: batchv1.JobStatus{
+						Succeeded: 1,
+						StartTime: &longAgo,
+					},
+				},
+				&batchv1.Job{
+					// Filtered out by the namespace.
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "other",
+						Namespace: "test",
+					},
+					Status: batchv1.JobStatus{
+						Failed: 6,
+					},
+				},
+			),
+		},
+		Context:   context.Background(),
+		Namespace: "default",
+	}
+
+	results, err := JobAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	require.Len(t, results, 3)
+
+	require.Equal(t, "default/backoff", results[0].Name)
+	require.Equal(t, "CronJob/nightly", results[0].ParentObject)
+	require.Len(t, results[0].Error, 1)
+	require.Equal(t, "spec.backoffLimit", results[0].Error[0].FieldPath)
+	require.Equal(t, common.SeverityHigh, results[0].Error[0].Severity)
+
+	require.Equal(t, "default/deadline", results[1].Name)
+	require.Empty(t, results[1].ParentObject)
+	require.Len(t, results[1].Error, 1)
+	require.Equal(t, "spec.activeDeadlineSeconds", results[1].Error[0].FieldPath)
+
+	require.Equal(t, "default/stuck", results[2].Name)
+	require.Len(t, results[2].Error, 1)
+	require.Contains(t, results[2].Error[0].Text, "may be stuck")
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LimitRangeAnalyzer reports the Deployments and standalone Pods whose
+// requests or limits fall outside the min, max or maxLimitRequestRatio of a
+// LimitRange of their namespace. Pods that violate a LimitRange are rejected
+// on creation. Only explicit values are checked, as missing ones are set to
+// the defaults of the LimitRange.
+type LimitRangeAnalyzer struct{}
+
+// limitRangeWorkload is a pod spec checked against the LimitRanges.
+type limitRangeWorkload struct {
+	kind string
+	name string
+	spec v1.PodSpec
+}
+
+func (LimitRangeAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "LimitRange"
+
+	snapshot := newAnalyzerErrorsSnapshot(kind)
+
+	list, err := a.Client.GetClient().CoreV1().LimitRanges(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor(kind, a.FieldSelector)})
+	if err != nil {
+		return nil, err
+	}
+
+	// Workloads are only listed once per namespace with a LimitRange.
+	workloads := map[string][]limitRangeWorkload{}
+
+	for _, limitRange := range list.Items {
+		if util.CreatedWithin(limitRange.ObjectMeta, a.MinAge) {
+			continue
+		}
+		if _, ok := workloads[limitRange.Namespace]; !ok {
+			if workloads[limitRange.Namespace], err = limitRangeWorkloads(a, limitRange.Namespace); err != nil {
+				return nil, err
+			}
+		}
+
+		var failures []common.Failure
+		for _, workload := range workloads[limitRange.Namespace] {
+			for _, item := range limitRange.Spec.Limits {
+				for _, violation := range limitRangeViolations(item, workload.spec) {
+					failures = append(failures, common.Failure{
+						Text: fmt.Sprintf("%s %s/%s violates LimitRange %s: %s; its pods are rejected until the value is within the limit",
+							workload.kind, limitRange.Namespace, workload.name, limitRange.Name, violation),
+						Sensitive: []common.Sensitive{
+							{
+								Unmasked: workload.name,
+								Masked:   util.MaskString(workload.name),
+							},
+							{
+								Unmasked: limitRange.Name,
+								Masked:   util.MaskString(limitRange.Name),
+							},
+						},
+						Severity: common.SeverityHigh,
+					})
+				}
+			}
+		}
+
+		if len(failures) > 0 {
+			snapshot.Set(limitRange.Name, limitRange.Namespace, float64(len(failures)))
+			a.Results = append(a.Results, common.Result{
+				Kind:  kind,
+				Name:  fmt.Sprintf("%s/%s", limitRange.Namespace, limitRange.Name),
+				Error: failures,
+			})
+		}
+	}
+
+	snapshot.Apply()
+	return a.Results, nil
+}
+
+// limitRangeWorkloads returns the Deployments of the namespace and its Pods
+// without an owner, whose controllers would otherwise be reported.
+func limitRangeWorkloads(a common.Analyzer, namespace string) ([]limitRangeWorkload, error) {
+	deployments, err := a.Client.GetClient().AppsV1().Deployments(namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	pods, err := a.Client.GetClient().CoreV1().Pods(namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var workloads []limitRangeWorkload
+	for _, deployment := range deployments.Items {
+		workloads = append(workloads, limitRangeWorkload{kind: "Deployment", name: deployment.Name, spec: deployment.Spec.Template.Spec})
+	}
+	for _, pod := range pods.Items {
+		if len(pod.OwnerReferences) == 0 {
+			workloads = append(workloads, limitRangeWorkload{kind: "Pod", name: pod.Name, spec: pod.Spec})
+		}
+	}
+	return workloads, nil
+}
+
+// limitRangeViolations describes how the pod spec falls outside the limits
+// of the item, each with the offending value and the limit.
+func limitRangeViolations(item v1.LimitRangeItem, spec v1.PodSpec) []string {
+	var violations []string
+	switch item.Type {
+	case v1.LimitTypeContainer:
+		containers := append(append([]v1.Container{}, spec.InitContainers...), spec.Containers...)
+		for _, container := range containers {
+			subject := fmt.Sprintf("container %s", container.Name)
+			violations = append(violations, boundViolations(subject, "request", container.Resources.Requests, item)...)
+			violations = append(violations, boundViolations(subject, "limit", container.Resources.Limits, item)...)
+			for _, name := range sortedResourceNames(item.MaxLimitRequestRatio) {
+				maxRatio := item.MaxLimitRequestRatio[name]
+				request, hasRequest := container.Resources.Requests[name]
+				limit, hasLimit := container.Resources.Limits[name]
+				if !hasRequest || !hasLimit || request.IsZero() {
+					continue
+				}
+				if ratio := limit.AsApproximateFloat64() / request.AsApproximateFloat64(); ratio > maxRatio.AsApproximateFloat64() {
+					violations = append(violations, fmt.Sprintf("%s has a %s limit of %s for a request of %s, a ratio of %.2g above the maxLimitRequestRatio of %s",
+						subject, name, limit.String(), request.String(), ratio, maxRatio.String()))
+				}
+			}
+		}
+	case v1.LimitTypePod:
+		requests, limits := v1.ResourceList{}, v1.ResourceList{}
+		for _, container := range spec.Containers {
+			addResources(requests, container.Resources.Requests)
+			addResources(limits, container.Resources.Limits)
+		}
+		violations = append(violations, boundViolations("the pod", "request", requests, item)...)
+		violations = append(violations, boundViolations("the pod", "limit", limits, item)...)
+	}
+	return violations
+}
+
+// boundViolations compares the requests or limits of the subject to the min
+// and max of the item.
+func boundViolations(subject string, what string, values v1.ResourceList, item v1.LimitRangeItem) []string {
+	var violations []string
+	for _, name := range sortedResourceNames(values) {
+		value := values[name]
+		if lower, ok := item.Min[name]; ok && value.Cmp(lower) < 0 {
+			violations = append(violations, fmt.Sprintf("%s has a %s %s of %s, below the min of %s", subject, name, what, value.String(), lower.String()))
+		}
+		if upper, ok := item.Max[name]; ok && value.Cmp(upper) > 0 {
+			violations = append(violations, fmt.Sprintf("%s has a %s %s of %s, above the max of %s", subject, name, what, value.String(), upper.String()))
+		}
+	}
+	return violations
+}
+
+func addResources(total v1.ResourceList, resources v1.ResourceList) {
+	for name, quantity := range resources {
+		sum := total[name]
+		sum.Add(quantity)
+		total[name] = sum
+	}
+}
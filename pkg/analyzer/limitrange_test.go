@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLimitRangeAnalyzer(t *testing.T) {
+	container := func(name string, requests v1.ResourceList, limits v1.ResourceList) v1.Container {
+		return v1.Container{Name: name, Resources: v1.ResourceRequirements{Requests: requests, Limits: limits}}
+	}
+
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				&v1.LimitRange{
+					ObjectMeta: metav1.ObjectMeta{Name: "limits", Namespace: "default"},
+					Spec: v1.LimitRangeSpec{
+						Limits: []v1.LimitRangeItem{
+							{
+								Type:                 v1.LimitTypeContainer,
+								Min:                  v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")},
+								Max:                  v1.ResourceList{v1.ResourceMemory: resource.MustParse("2Gi")},
+								MaxLimitRequestRatio: v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")},
+							},
+							{
+								Type: v1.LimitTypePod,
+								Max:  v1.ResourceList{v1.ResourceMemory: resource.MustParse("3Gi")},
+							},
+						},
+					},
+				},
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+					Spec: appsv1.DeploymentSpec{
+						Template: v1.PodTemplateSpec{
+							Spec: v1.PodSpec{
+								Containers: []v1.Container{
+									container("app",
+										v1.ResourceList{v1.ResourceCPU: resource.MustParse("50m")},
+										v1.ResourceList{v1.ResourceMemory: resource.MustParse("4Gi")}),
+								},
+							},
+						},
+					},
+				},
+				&appsv1.Deployment{
+					// Within the limits, and without values that get the defaults.
+					ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+					Spec: appsv1.DeploymentSpec{
+						Template: v1.PodTemplateSpec{
+							Spec: v1.PodSpec{
+								Containers: []v1.Container{
+									container("app", v1.ResourceList{v1.ResourceCPU: resource.MustParse("200m")}, nil),
+									{Name: "sidecar"},
+								},
+							},
+						},
+					},
+				},
+				&v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "debug", Namespace: "default"},
+					Spec: v1.PodSpec{
+						Containers: []v1.Container{
+							container("a",
+								v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")},
+								v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("2Gi")}),
+							container("b", nil, v1.ResourceList{v1.ResourceMemory: resource.MustParse("2Gi")}),
+						},
+					},
+				},
+				&v1.Pod{
+					// Pods of controllers are reported through them.
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            "web-5d4f-x2k8p",
+						Namespace:       "default",
+						OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-5d4f"}},
+					},
+					Spec: v1.PodSpec{
+						Containers: []v1.Container{container("app", v1.ResourceList{v1.ResourceCPU: resource.MustParse("50m")}, nil)},
+					},
+				},
+			),
+		},
+		Context:   context.Background(),
+		Namespace: "default",
+	}
+
+	results, err := LimitRangeAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "LimitRange", results[0].Kind)
+	require.Equal(t, "default/limits", results[0].Name)
+
+	var texts []string
+	for _, failure := range results[0].Error {
+		texts = append(texts, failure.Text)
+	}
+	suffix := "; its pods are rejected until the value is within the limit"
+	require.Equal(t, []string{
+		"Deployment default/web violates LimitRange limits: container app has a cpu request of 50m, below the min of 100m" + suffix,
+		"Deployment default/web violates LimitRange limits: container app has a memory limit of 4Gi, above the max of 2Gi" + suffix,
+		"Deployment default/web violates LimitRange limits: the pod has a memory limit of 4Gi, above the max of 3Gi" + suffix,
+		"Pod default/debug violates LimitRange limits: container a has a cpu limit of 1 for a request of 100m, a ratio of 10 above the maxLimitRequestRatio of 4" + suffix,
+		"Pod default/debug violates LimitRange limits: the pod has a memory limit of 4Gi, above the max of 3Gi" + suffix,
+	}, texts)
+}
@@ -36,12 +36,10 @@ func (LogAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 
 	kind := "Log"
 
-	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
-		"analyzer_name": kind,
-	})
+	snapshot := newAnalyzerErrorsSnapshot(kind)
 
 	// search all namespaces for pods that are not running
-	list, err := a.Client.GetClient().CoreV1().Pods(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector})
+	list, err := a.Client.GetClient().CoreV1().Pods(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor("Pod", a.FieldSelector)})
 	if err != nil {
 		return nil, err
 	}
@@ -56,6 +54,9 @@ func (LogAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				TailLines: &tailLines,
 				Container: c.Name,
 			}
+			if err := a.Client.Wait(a.Context); err != nil {
+				return nil, err
+			}
 			podLogs, err := a.Client.Client.CoreV1().Pods(pod.Namespace).GetLogs(podName, &podLogOptions).DoRaw(a.Context)
 			if err != nil {
 				failures = append(failures, common.Failure{
@@ -86,7 +87,7 @@ func (LogAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 					FailureDetails: failures,
 					Pod:            pod,
 				}
-				AnalyzerErrorsMetric.WithLabelValues(kind, pod.Name, pod.Namespace).Set(float64(len(failures)))
+				snapshot.Set(pod.Name, pod.Namespace, float64(len(failures)))
 			}
 		}
 	}
@@ -103,6 +104,7 @@ func (LogAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		a.Results = append(a.Results, currentAnalysis)
 	}
 
+	snapshot.Apply()
 	return a.Results, nil
 }
 func printErrorLines(logs string, errorPattern *regexp.Regexp) string {
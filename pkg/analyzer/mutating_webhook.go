@@ -38,11 +38,9 @@ func (MutatingWebhookAnalyzer) Analyze(a common.Analyzer) ([]common.Result, erro
 		OpenapiSchema: a.OpenapiSchema,
 	}
 
-	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
-		"analyzer_name": kind,
-	})
+	snapshot := newAnalyzerErrorsSnapshot(kind)
 
-	mutatingWebhooks, err := a.Client.GetClient().AdmissionregistrationV1().MutatingWebhookConfigurations().List(context.Background(), v1.ListOptions{LabelSelector: a.LabelSelector})
+	mutatingWebhooks, err := a.Client.GetClient().AdmissionregistrationV1().MutatingWebhookConfigurations().List(context.Background(), v1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor("MutatingWebhookConfiguration", a.FieldSelector)})
 	if err != nil {
 		return nil, err
 	}
@@ -59,10 +57,11 @@ func (MutatingWebhookAnalyzer) Analyze(a common.Analyzer) ([]common.Result, erro
 			svc := webhook.ClientConfig.Service
 			// Get the service
 			service, err := a.Client.GetClient().CoreV1().Services(svc.Namespace).Get(context.Background(), svc.Name, v1.GetOptions{})
+			impact, _ := webhookPolicyImpact(webhook.FailurePolicy)
 			if err != nil {
 				// If the service is not found, we can't check the pods
 				failures = append(failures, common.Failure{
-					Text:          fmt.Sprintf("Service %s not found as mapped to by Mutating Webhook %s", svc.Name, webhook.Name),
+					Text:          fmt.Sprintf("Service %s not found as mapped to by Mutating Webhook %s; %s", svc.Name, webhook.Name, impact),
 					KubernetesDoc: apiDoc.GetApiDocV2("spec.webhook.clientConfig.service"),
 					Sensitive: []common.Sensitive{
 						{
@@ -79,12 +78,23 @@ func (MutatingWebhookAnalyzer) Analyze(a common.Analyzer) ([]common.Result, erro
 					MutatingWebhook: webhookConfig,
 					FailureDetails:  failures,
 				}
-				AnalyzerErrorsMetric.WithLabelValues(kind, webhook.Name, webhookConfig.Namespace).Set(float64(len(failures)))
+				snapshot.Set(webhook.Name, webhookConfig.Namespace, float64(len(failures)))
 				continue
 			}
 
+			if failure, ok := analyzeWebhookBackend(a, "Mutating", webhook.Name, svc, webhook.FailurePolicy); ok {
+				failures = append(failures, failure)
+			}
+
 			// When Service selectors are empty we defer to service analyser
 			if len(service.Spec.Selector) == 0 {
+				if len(failures) > 0 {
+					preAnalysis[fmt.Sprintf("%s/%s", webhookConfig.Namespace, webhook.Name)] = common.PreAnalysis{
+						MutatingWebhook: webhookConfig,
+						FailureDetails:  failures,
+					}
+					snapshot.Set(webhook.Name, webhookConfig.Namespace, float64(len(failures)))
+				}
 				continue
 			}
 			// Get pods within service
@@ -140,7 +150,7 @@ func (MutatingWebhookAnalyzer) Analyze(a common.Analyzer) ([]common.Result, erro
 					MutatingWebhook: webhookConfig,
 					FailureDetails:  failures,
 				}
-				AnalyzerErrorsMetric.WithLabelValues(kind, webhook.Name, webhookConfig.Namespace).Set(float64(len(failures)))
+				snapshot.Set(webhook.Name, webhookConfig.Namespace, float64(len(failures)))
 			}
 		}
 	}
@@ -158,5 +168,6 @@ func (MutatingWebhookAnalyzer) Analyze(a common.Analyzer) ([]common.Result, erro
 		a.Results = append(a.Results, currentAnalysis)
 	}
 
+	snapshot.Apply()
 	return a.Results, nil
 }
@@ -15,14 +15,20 @@ package analyzer
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
+// NetworkPolicyAnalyzer reports NetworkPolicies selecting all pods or none,
+// and, in namespaces with NetworkPolicies, the pods none of them selects,
+// whose traffic is unrestricted.
 type NetworkPolicyAnalyzer struct{}
 
 func (NetworkPolicyAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
@@ -37,13 +43,11 @@ func (NetworkPolicyAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 		OpenapiSchema: a.OpenapiSchema,
 	}
 
-	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
-		"analyzer_name": kind,
-	})
+	snapshot := newAnalyzerErrorsSnapshot(kind)
 
 	// get all network policies in the namespace
 	policies, err := a.Client.GetClient().NetworkingV1().
-		NetworkPolicies(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector})
+		NetworkPolicies(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor("NetworkPolicy", a.FieldSelector)})
 	if err != nil {
 		return nil, err
 	}
@@ -51,6 +55,9 @@ func (NetworkPolicyAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 	var preAnalysis = map[string]common.PreAnalysis{}
 
 	for _, policy := range policies.Items {
+		if util.CreatedWithin(policy.ObjectMeta, a.MinAge) {
+			continue
+		}
 		var failures []common.Failure
 
 		// Check if policy allows traffic to all pods in the namespace
@@ -91,7 +98,7 @@ func (NetworkPolicyAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 				FailureDetails: failures,
 				NetworkPolicy:  policy,
 			}
-			AnalyzerErrorsMetric.WithLabelValues(kind, policy.Name, policy.Namespace).Set(float64(len(failures)))
+			snapshot.Set(policy.Name, policy.Namespace, float64(len(failures)))
 
 		}
 	}
@@ -105,5 +112,93 @@ func (NetworkPolicyAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 		a.Results = append(a.Results, currentAnalysis)
 	}
 
+	unprotected, err := unprotectedPods(a)
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range unprotected {
+		namespace, name, _ := strings.Cut(result.Name, "/")
+		snapshot.Set(name, namespace, float64(len(result.Error)))
+		a.Results = append(a.Results, result)
+	}
+
+	snapshot.Apply()
 	return a.Results, nil
 }
+
+// unprotectedPods returns a Pod result for each running pod not selected by
+// any NetworkPolicy of its namespace, when the namespace has some: using
+// NetworkPolicies, the namespace likely means to restrict the traffic of all
+// its pods. Pods on the host network are skipped, as NetworkPolicies don't
+// apply to them.
+func unprotectedPods(a common.Analyzer) ([]common.Result, error) {
+	// All the policies of a namespace select its pods, whatever the
+	// selectors of the analysis.
+	policies, err := a.Client.GetClient().NetworkingV1().NetworkPolicies(a.Namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(policies.Items) == 0 {
+		return nil, nil
+	}
+	selectors := map[string][]labels.Selector{}
+	for _, policy := range policies.Items {
+		selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.PodSelector)
+		if err != nil {
+			// The API server validates selectors: match no pods.
+			selector = labels.Nothing()
+		}
+		selectors[policy.Namespace] = append(selectors[policy.Namespace], selector)
+	}
+
+	pods, err := a.Client.GetClient().CoreV1().Pods(a.Namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var results []common.Result
+	for _, pod := range pods.Items {
+		namespaceSelectors, ok := selectors[pod.Namespace]
+		if !ok || pod.Spec.HostNetwork || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed ||
+			util.CreatedWithin(pod.ObjectMeta, a.MinAge) {
+			continue
+		}
+		selected := false
+		for _, selector := range namespaceSelectors {
+			if selector.Matches(labels.Set(pod.Labels)) {
+				selected = true
+				break
+			}
+		}
+		if selected {
+			continue
+		}
+
+		policiesText := fmt.Sprintf("any of the %d NetworkPolicies", len(namespaceSelectors))
+		if len(namespaceSelectors) == 1 {
+			policiesText = "the only NetworkPolicy"
+		}
+		results = append(results, common.Result{
+			Kind: "Pod",
+			Name: fmt.Sprintf("%s/%s", pod.Namespace, pod.Name),
+			Error: []common.Failure{
+				{
+					Text: fmt.Sprintf("Pod %s is unprotected: it is not selected by %s of namespace %s, so its traffic is unrestricted",
+						pod.Name, policiesText, pod.Namespace),
+					Sensitive: []common.Sensitive{
+						{
+							Unmasked: pod.Name,
+							Masked:   util.MaskString(pod.Name),
+						},
+						{
+							Unmasked: pod.Namespace,
+							Masked:   util.MaskString(pod.Namespace),
+						},
+					},
+					Severity: common.SeverityMedium,
+				},
+			},
+		})
+	}
+	return results, nil
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
 	"github.com/magiconair/properties/assert"
+	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -263,3 +264,81 @@ func TestNetpolLabelSelectorFiltering(t *testing.T) {
 	}
 	assert.Equal(t, len(results), 1)
 }
+
+func TestNetpolUnprotectedPods(t *testing.T) {
+	policy := func(namespace string, name string, app string) *networkingv1.NetworkPolicy {
+		return &networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: networkingv1.NetworkPolicySpec{
+				PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": app}},
+			},
+		}
+	}
+	pod := func(namespace string, name string, app string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{"app": app}},
+			Status:     v1.PodStatus{Phase: v1.PodRunning},
+		}
+	}
+	completed := pod("shop", "migrate-1", "migrate")
+	completed.Status.Phase = v1.PodSucceeded
+	hostNetwork := pod("shop", "agent-1", "agent")
+	hostNetwork.Spec.HostNetwork = true
+
+	clientset := fake.NewSimpleClientset(
+		policy("shop", "web", "web"),
+		policy("shop", "db", "db"),
+		pod("shop", "web-1", "web"),
+		pod("shop", "db-1", "db"),
+		pod("shop", "worker-1", "worker"),
+		completed,
+		hostNetwork,
+		policy("blog", "blog", "blog"),
+		pod("blog", "blog-1", "blog"),
+		pod("blog", "cache-1", "cache"),
+		// Namespaces without NetworkPolicies aren't expected to use them.
+		pod("default", "nginx", "nginx"),
+	)
+
+	tests := []struct {
+		namespace string
+		expected  map[string]string
+	}{
+		{
+			expected: map[string]string{
+				"shop/worker-1": "Pod worker-1 is unprotected: it is not selected by any of the 2 NetworkPolicies of namespace shop, so its traffic is unrestricted",
+				"blog/cache-1":  "Pod cache-1 is unprotected: it is not selected by the only NetworkPolicy of namespace blog, so its traffic is unrestricted",
+			},
+		},
+		{
+			namespace: "blog",
+			expected: map[string]string{
+				"blog/cache-1": "Pod cache-1 is unprotected: it is not selected by the only NetworkPolicy of namespace blog, so its traffic is unrestricted",
+			},
+		},
+		{
+			namespace: "default",
+			expected:  map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.namespace, func(t *testing.T) {
+			results, err := NetworkPolicyAnalyzer{}.Analyze(common.Analyzer{
+				Client:    &kubernetes.Client{Client: clientset},
+				Context:   context.Background(),
+				Namespace: tt.namespace,
+			})
+			require.NoError(t, err)
+
+			got := map[string]string{}
+			for _, result := range results {
+				require.Equal(t, "Pod", result.Kind)
+				require.Len(t, result.Error, 1)
+				require.Equal(t, common.SeverityMedium, result.Error[0].Severity)
+				got[result.Name] = result.Error[0].Text
+			}
+			require.Equal(t, tt.expected, got)
+		})
+	}
+}
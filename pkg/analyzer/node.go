@@ -15,6 +15,7 @@ package analyzer
 
 import (
 	"fmt"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 
@@ -29,11 +30,9 @@ func (NodeAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 
 	kind := "Node"
 
-	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
-		"analyzer_name": kind,
-	})
+	snapshot := newAnalyzerErrorsSnapshot(kind)
 
-	list, err := a.Client.GetClient().CoreV1().Nodes().List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector})
+	list, err := a.Client.GetClient().CoreV1().Nodes().List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor("Node", a.FieldSelector)})
 	if err != nil {
 		return nil, err
 	}
@@ -41,6 +40,9 @@ func (NodeAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 	var preAnalysis = map[string]common.PreAnalysis{}
 
 	for _, node := range list.Items {
+		if util.CreatedWithin(node.ObjectMeta, a.MinAge) {
+			continue
+		}
 		var failures []common.Failure
 		for _, nodeCondition := range node.Status.Conditions {
 			// https://kubernetes.io/docs/concepts/architecture/nodes/#condition
@@ -65,7 +67,7 @@ func (NodeAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				Node:           node,
 				FailureDetails: failures,
 			}
-			AnalyzerErrorsMetric.WithLabelValues(kind, node.Name, "").Set(float64(len(failures)))
+			snapshot.Set(node.Name, "", float64(len(failures)))
 
 		}
 	}
@@ -84,18 +86,45 @@ func (NodeAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		a.Results = append(a.Results, currentAnalysis)
 	}
 
+	snapshot.Apply()
 	return a.Results, err
 }
 
+// nodePressureConditions are set by the kubelet when the node runs low on a
+// resource and starts evicting pods.
+var nodePressureConditions = map[v1.NodeConditionType]bool{
+	v1.NodeMemoryPressure: true,
+	v1.NodeDiskPressure:   true,
+	v1.NodePIDPressure:    true,
+}
+
 func addNodeConditionFailure(failures []common.Failure, nodeName string, nodeCondition v1.NodeCondition) []common.Failure {
+	text := fmt.Sprintf("%s has condition of type %s, reason %s: %s", nodeName, nodeCondition.Type, nodeCondition.Reason, nodeCondition.Message)
+	var severity common.Severity
+	switch {
+	case nodeCondition.Type == v1.NodeReady:
+		text = fmt.Sprintf("%s is not ready (Ready=%s), reason %s: %s", nodeName, nodeCondition.Status, nodeCondition.Reason, nodeCondition.Message)
+		severity = common.SeverityHigh
+	case nodePressureConditions[nodeCondition.Type] && nodeCondition.Status == v1.ConditionTrue:
+		text = fmt.Sprintf("%s is under %s, reason %s: %s", nodeName, nodeCondition.Type, nodeCondition.Reason, nodeCondition.Message)
+		severity = common.SeverityMedium
+	}
+	// A heartbeat long ago means the kubelet stopped reporting, e.g. as the
+	// node is down or partitioned, and the condition is stale.
+	if heartbeat := nodeCondition.LastHeartbeatTime; !heartbeat.IsZero() {
+		text += fmt.Sprintf(" (last heartbeat %s ago, at %s)", time.Since(heartbeat.Time).Round(time.Second), heartbeat.UTC().Format(time.RFC3339))
+	}
+
 	failures = append(failures, common.Failure{
-		Text: fmt.Sprintf("%s has condition of type %s, reason %s: %s", nodeName, nodeCondition.Type, nodeCondition.Reason, nodeCondition.Message),
+		Text: text,
 		Sensitive: []common.Sensitive{
 			{
 				Unmasked: nodeName,
 				Masked:   util.MaskString(nodeName),
 			},
 		},
+		FieldPath: "status.conditions",
+		Severity:  severity,
 	})
 	return failures
 }
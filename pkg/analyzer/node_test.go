@@ -15,8 +15,11 @@ package analyzer
 
 import (
 	"context"
+	"fmt"
 	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
@@ -215,3 +218,58 @@ func TestNodeAnalyzerLabelSelectorFiltering(t *testing.T) {
 	require.Equal(t, 1, len(results))
 	require.Equal(t, "Node1", results[0].Name)
 }
+
+func TestNodeAnalyzerConditionTexts(t *testing.T) {
+	heartbeat := metav1.NewTime(time.Now().Add(-10 * time.Minute))
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				&v1.Node{
+					ObjectMeta: metav1.ObjectMeta{Name: "Node1"},
+					Status: v1.NodeStatus{
+						Conditions: []v1.NodeCondition{
+							{
+								Type:              v1.NodeReady,
+								Status:            v1.ConditionUnknown,
+								Reason:            "NodeStatusUnknown",
+								Message:           "Kubelet stopped posting node status.",
+								LastHeartbeatTime: heartbeat,
+							},
+							{
+								Type:    v1.NodeDiskPressure,
+								Status:  v1.ConditionTrue,
+								Reason:  "KubeletHasDiskPressure",
+								Message: "kubelet has disk pressure",
+							},
+							{
+								Type:   v1.NodeNetworkUnavailable,
+								Status: v1.ConditionTrue,
+								Reason: "NoRouteCreated",
+							},
+						},
+					},
+				},
+			),
+		},
+		Context: context.Background(),
+	}
+
+	results, err := NodeAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	failures := results[0].Error
+	require.Len(t, failures, 3)
+
+	require.True(t, strings.HasPrefix(failures[0].Text, "Node1 is not ready (Ready=Unknown), reason NodeStatusUnknown: Kubelet stopped posting node status. (last heartbeat 10m"), failures[0].Text)
+	require.True(t, strings.HasSuffix(failures[0].Text, fmt.Sprintf(" ago, at %s)", heartbeat.UTC().Format(time.RFC3339))), failures[0].Text)
+	require.Equal(t, common.SeverityHigh, failures[0].Severity)
+
+	require.Equal(t, "Node1 is under DiskPressure, reason KubeletHasDiskPressure: kubelet has disk pressure", failures[1].Text)
+	require.Equal(t, common.SeverityMedium, failures[1].Severity)
+
+	require.Equal(t, "Node1 has condition of type NetworkUnavailable, reason NoRouteCreated: ", failures[2].Text)
+	require.Empty(t, failures[2].Severity)
+	for _, failure := range failures {
+		require.Equal(t, "status.conditions", failure.FieldPath)
+	}
+}
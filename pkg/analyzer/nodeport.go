@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// The default --service-node-port-range of the kube-apiserver.
+	defaultNodePortRangeStart = 30000
+	defaultNodePortRangeEnd   = 32767
+	// The range is reported once this share of its ports is allocated.
+	nodePortRangeWarningRatio = 0.9
+)
+
+var nodePortAllocationFailurePattern = regexp.MustCompile(`(?i)range is full|failed to allocate .*node ?port|provided port is already allocated`)
+
+// analyzeNodePortRange reports how full the cluster's nodePort range is once
+// few ports are left, and the events of objects whose Services failed to get
+// a nodePort. New NodePort and LoadBalancer Services can't be created once the
+// range is exhausted. The second return value is false when there is nothing
+// to report or the Services can't be listed.
+func analyzeNodePortRange(a common.Analyzer) (common.Result, bool) {
+	services, err := a.Client.GetClient().CoreV1().Services("").List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return common.Result{}, false
+	}
+
+	start, end := nodePortRange(a)
+	allocated := map[int32]struct{}{}
+	for _, svc := range services.Items {
+		for _, port := range svc.Spec.Ports {
+			if port.NodePort >= start && port.NodePort <= end {
+				allocated[port.NodePort] = struct{}{}
+			}
+		}
+		if svc.Spec.HealthCheckNodePort >= start && svc.Spec.HealthCheckNodePort <= end {
+			allocated[svc.Spec.HealthCheckNodePort] = struct{}{}
+		}
+	}
+
+	var failures []common.Failure
+	size := int(end-start) + 1
+	used := len(allocated)
+	if float64(used) >= nodePortRangeWarningRatio*float64(size) {
+		severity := common.SeverityMedium
+		if used >= size {
+			severity = common.SeverityHigh
+		}
+		failures = append(failures, common.Failure{
+			Text: fmt.Sprintf("%d of the %d ports of the nodePort range %d-%d are allocated; new NodePort and LoadBalancer Services fail to allocate a port once it is full",
+				used, size, start, end),
+			Sensitive: []common.Sensitive{},
+			Severity:  severity,
+		})
+	}
+
+	if events, err := a.Client.GetClient().CoreV1().Events("").List(a.Context, metav1.ListOptions{}); err == nil {
+		for _, event := range events.Items {
+			if event.Type == "Normal" || !nodePortAllocationFailurePattern.MatchString(event.Message) {
+				continue
+			}
+			failures = append(failures, common.Failure{
+				Text: fmt.Sprintf("nodePort allocation failed for %s %s/%s: %s",
+					event.InvolvedObject.Kind, event.InvolvedObject.Namespace, event.InvolvedObject.Name, event.Message),
+				Sensitive: []common.Sensitive{},
+				Severity:  common.SeverityHigh,
+			})
+		}
+	}
+
+	if len(failures) == 0 {
+		return common.Result{}, false
+	}
+	return common.Result{
+		Kind:  "Service",
+		Name:  fmt.Sprintf("nodePort range %d-%d", start, end),
+		Error: failures,
+	}, true
+}
+
+// nodePortRange returns the --service-node-port-range of the kube-apiserver
+// when it runs as a static pod that can be read, and the default otherwise.
+func nodePortRange(a common.Analyzer) (int32, int32) {
+	pods, err := a.Client.GetClient().CoreV1().Pods("kube-system").List(a.Context, metav1.ListOptions{LabelSelector: "component=kube-apiserver"})
+	if err != nil {
+		return defaultNodePortRangeStart, defaultNodePortRangeEnd
+	}
+	for _, pod := range pods.Items {
+		for _, container := range pod.Spec.Containers {
+			for _, arg := range append(container.Command, container.Args...) {
+				value, ok := strings.CutPrefix(arg, "--service-node-port-range=")
+				if !ok {
+					continue
+				}
+				if start, end, ok := parseNodePortRange(value); ok {
+					return start, end
+				}
+			}
+		}
+	}
+	return defaultNodePortRangeStart, defaultNodePortRangeEnd
+}
+
+// parseNodePortRange parses a port range such as 30000-32767 or 30000+2767.
+func parseNodePortRange(value string) (int32, int32, bool) {
+	separator := "-"
+	if strings.Contains(value, "+") {
+		separator = "+"
+	}
+	first, second, found := strings.Cut(value, separator)
+	if !found {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(strings.TrimSpace(first), 10, 32)
+	if err != nil {
+		return 0, 0, false
+	}
+	end, err := strconv.ParseInt(strings.TrimSpace(second), 10, 32)
+	if err != nil {
+		return 0, 0, false
+	}
+	if separator == "+" {
+		end += start
+	}
+	if end < start {
+		return 0, 0, false
+	}
+	return int32(start), int32(end), true
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const metricsGroupVersion = "metrics.k8s.io/v1beta1"
+
+// oomKilledDetails describes the memory limit of a container killed for
+// running out of memory and, when known, the memory it uses now, so a new
+// limit can be suggested.
+func oomKilledDetails(spec v1.PodSpec, containerName string, usage map[string]resource.Quantity) string {
+	container := specContainer(spec, containerName)
+	if container == nil {
+		return ""
+	}
+	var details string
+	if limit, ok := container.Resources.Limits[v1.ResourceMemory]; ok {
+		details = fmt.Sprintf("; its memory limit is %s", limit.String())
+	} else {
+		details = "; it has no memory limit, so it was killed as its node ran out of memory"
+	}
+	if used, ok := usage[containerName]; ok {
+		details += fmt.Sprintf(", and it currently uses %s", used.String())
+	}
+	return details
+}
+
+// specContainer returns the named container or init container of the pod
+// spec, or nil.
+func specContainer(spec v1.PodSpec, name string) *v1.Container {
+	for i := range spec.InitContainers {
+		if spec.InitContainers[i].Name == name {
+			return &spec.InitContainers[i]
+		}
+	}
+	for i := range spec.Containers {
+		if spec.Containers[i].Name == name {
+			return &spec.Containers[i]
+		}
+	}
+	return nil
+}
+
+// containerMemoryUsage returns the memory used by the containers of a pod
+// according to the metrics API. It returns nothing when metrics-server is not
+// installed or has no metrics for the pod.
+func containerMemoryUsage(a common.Analyzer, namespace string, name string) map[string]resource.Quantity {
+	if _, err := a.Client.GetClient().Discovery().ServerResourcesForGroupVersion(metricsGroupVersion); err != nil || a.Client.CtrlClient == nil {
+		return nil
+	}
+	gv, err := schema.ParseGroupVersion(metricsGroupVersion)
+	if err != nil {
+		return nil
+	}
+
+	metrics := &unstructured.Unstructured{}
+	metrics.SetGroupVersionKind(gv.WithKind("PodMetrics"))
+	if err := a.Client.CtrlClient.Get(a.Context, ctrl.ObjectKey{Namespace: namespace, Name: name}, metrics); err != nil {
+		return nil
+	}
+
+	usage := map[string]resource.Quantity{}
+	containers, _, _ := unstructured.NestedSlice(metrics.Object, "containers")
+	for _, item := range containers {
+		c, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		containerName, _, _ := unstructured.NestedString(c, "name")
+		memory, _, _ := unstructured.NestedString(c, "usage", "memory")
+		if quantity, err := resource.ParseQuantity(memory); err == nil {
+			usage[containerName] = quantity
+		}
+	}
+	return usage
+}
@@ -37,11 +37,9 @@ func (PdbAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		OpenapiSchema: a.OpenapiSchema,
 	}
 
-	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
-		"analyzer_name": kind,
-	})
+	snapshot := newAnalyzerErrorsSnapshot(kind)
 
-	list, err := a.Client.GetClient().PolicyV1().PodDisruptionBudgets(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector})
+	list, err := a.Client.GetClient().PolicyV1().PodDisruptionBudgets(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor("PodDisruptionBudget", a.FieldSelector)})
 	if err != nil {
 		return nil, err
 	}
@@ -49,6 +47,9 @@ func (PdbAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 	var preAnalysis = map[string]common.PreAnalysis{}
 
 	for _, pdb := range list.Items {
+		if util.CreatedWithin(pdb.ObjectMeta, a.MinAge) {
+			continue
+		}
 		var failures []common.Failure
 
 		// Before accessing the Conditions, check if they exist or not.
@@ -88,7 +89,7 @@ func (PdbAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				PodDisruptionBudget: pdb,
 				FailureDetails:      failures,
 			}
-			AnalyzerErrorsMetric.WithLabelValues(kind, pdb.Name, pdb.Namespace).Set(float64(len(failures)))
+			snapshot.Set(pdb.Name, pdb.Namespace, float64(len(failures)))
 		}
 	}
 
@@ -106,5 +107,6 @@ func (PdbAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		a.Results = append(a.Results, currentAnalysis)
 	}
 
+	snapshot.Apply()
 	return a.Results, err
 }
@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/spf13/viper"
+)
+
+// PluginAnalyzer runs an out-of-tree analyzer, an executable set with the
+// plugin_analyzers configuration key, e.g. to analyze proprietary custom
+// resources without forking k8sgpt.
+//
+// The contract between k8sgpt and a plugin is:
+//   - k8sgpt runs Command with Args and the environment of k8sgpt, so that
+//     the plugin finds the cluster as k8sgpt does, e.g. with KUBECONFIG.
+//   - It writes a PluginRequest as JSON on the standard input of the plugin.
+//   - The plugin writes its results as a JSON array of common.Result on its
+//     standard output, an empty array when it finds no problem. Results
+//     without a kind get the name of the plugin.
+//   - The plugin exits with a non-zero code when it fails, its standard error
+//     being reported as the error of the analyzer.
+//
+// See examples/plugin-analyzer for a plugin stub.
+type PluginAnalyzer struct {
+	// Name is the filter selecting the plugin, and the kind of its results.
+	Name    string   `mapstructure:"name"`
+	Command string   `mapstructure:"command"`
+	Args    []string `mapstructure:"args"`
+}
+
+// PluginRequest is what a PluginAnalyzer is asked to analyze.
+type PluginRequest struct {
+	Namespace     string `json:"namespace,omitempty"`
+	LabelSelector string `json:"labelSelector,omitempty"`
+	FieldSelector string `json:"fieldSelector,omitempty"`
+}
+
+func (p PluginAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := p.Name
+
+	snapshot := newAnalyzerErrorsSnapshot(kind)
+
+	request, err := json.Marshal(PluginRequest{
+		Namespace:     a.Namespace,
+		LabelSelector: a.LabelSelector,
+		FieldSelector: a.FieldSelector,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(a.Context, p.Command, p.Args...)
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("plugin %s failed: %w: %s", p.Name, err, message)
+		}
+		return nil, fmt.Errorf("plugin %s failed: %w", p.Name, err)
+	}
+
+	var results []common.Result
+	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil {
+		return nil, fmt.Errorf("plugin %s returned invalid results: %w", p.Name, err)
+	}
+	for _, result := range results {
+		if result.Kind == "" {
+			result.Kind = kind
+		}
+		namespace, name, found := strings.Cut(result.Name, "/")
+		if !found {
+			namespace, name = "", result.Name
+		}
+		snapshot.Set(name, namespace, float64(len(result.Error)))
+		a.Results = append(a.Results, result)
+	}
+
+	snapshot.Apply()
+	return a.Results, nil
+}
+
+// PluginAnalyzers returns the plugins set with the plugin_analyzers
+// configuration key.
+func PluginAnalyzers() ([]PluginAnalyzer, error) {
+	var plugins []PluginAnalyzer
+	if err := viper.UnmarshalKey("plugin_analyzers", &plugins); err != nil {
+		return nil, fmt.Errorf("invalid plugin_analyzers: %w", err)
+	}
+	for _, plugin := range plugins {
+		if plugin.Name == "" || plugin.Command == "" {
+			return nil, fmt.Errorf("invalid plugin_analyzers: a plugin needs a name and a command")
+		}
+	}
+	return plugins, nil
+}
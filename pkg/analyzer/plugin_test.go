@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPluginProcess is the plugin run by the tests, as the test binary
+// itself, and isn't a test.
+func TestPluginProcess(t *testing.T) {
+	mode := os.Getenv("K8SGPT_TEST_PLUGIN")
+	if mode == "" {
+		return
+	}
+	defer os.Exit(0)
+
+	var request PluginRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&request); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	switch mode {
+	case "results":
+		_ = json.NewEncoder(os.Stdout).Encode([]common.Result{
+			{Name: request.Namespace + "/db", Error: []common.Failure{{Text: "db is degraded: selector " + request.LabelSelector}}},
+			{Kind: "Widget", Name: request.Namespace + "/web", Error: []common.Failure{{Text: "web is not ready"}}},
+		})
+	case "fail":
+		fmt.Fprintln(os.Stderr, "cannot reach the cluster")
+		os.Exit(3)
+	case "invalid":
+		fmt.Println("not json")
+	}
+}
+
+func TestPluginAnalyzer(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		expected []common.Result
+		err      string
+	}{
+		{
+			name: "results",
+			mode: "results",
+			expected: []common.Result{
+				{Kind: "Database", Name: "shop/db", Error: []common.Failure{{Text: "db is degraded: selector app=db"}}},
+				{Kind: "Widget", Name: "shop/web", Error: []common.Failure{{Text: "web is not ready"}}},
+			},
+		},
+		{
+			name: "failing plugin",
+			mode: "fail",
+			err:  "plugin Database failed: exit status 3: cannot reach the cluster",
+		},
+		{
+			name: "invalid results",
+			mode: "invalid",
+			err:  "plugin Database returned invalid results",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("K8SGPT_TEST_PLUGIN", tt.mode)
+			plugin := PluginAnalyzer{Name: "Database", Command: os.Args[0], Args: []string{"-test.run=^TestPluginProcess$"}}
+
+			results, err := plugin.Analyze(common.Analyzer{
+				Context:       context.Background(),
+				Namespace:     "shop",
+				LabelSelector: "app=db",
+			})
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, results)
+		})
+	}
+}
+
+func TestPluginAnalyzers(t *testing.T) {
+	viper.Set("plugin_analyzers", []map[string]any{
+		{"name": "Database", "command": "/usr/local/bin/db-analyzer", "args": []string{"--verbose"}},
+	})
+	defer viper.Set("plugin_analyzers", nil)
+
+	plugins, err := PluginAnalyzers()
+	require.NoError(t, err)
+	require.Equal(t, []PluginAnalyzer{{Name: "Database", Command: "/usr/local/bin/db-analyzer", Args: []string{"--verbose"}}}, plugins)
+
+	_, analyzers := GetAnalyzerMap()
+	require.Equal(t, plugins[0], analyzers["Database"])
+
+	viper.Set("plugin_analyzers", []map[string]any{{"name": "Database"}})
+	_, err = PluginAnalyzers()
+	require.ErrorContains(t, err, "a plugin needs a name and a command")
+}
@@ -15,10 +15,17 @@ package analyzer
 
 import (
 	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
 
 	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -29,20 +36,88 @@ func (PodAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 
 	kind := "Pod"
 
-	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
-		"analyzer_name": kind,
-	})
+	snapshot := newAnalyzerErrorsSnapshot(kind)
 
 	// search all namespaces for pods that are not running
 	list, err := a.Client.GetClient().CoreV1().Pods(a.Namespace).List(a.Context, metav1.ListOptions{
 		LabelSelector: a.LabelSelector,
+		FieldSelector: util.FieldSelectorFor("Pod", a.FieldSelector),
 	})
 	if err != nil {
 		return nil, err
 	}
 	var preAnalysis = map[string]common.PreAnalysis{}
+	// nodes are only listed once, and only if a pod is unschedulable due to taints
+	// or affinity rules. If listing fails (e.g. no RBAC on nodes) the scheduler
+	// message is reported alone.
+	var nodes []v1.Node
+	var nodesListed bool
+	listNodes := func() []v1.Node {
+		if !nodesListed {
+			nodesListed = true
+			if nodeList, err := a.Client.GetClient().CoreV1().Nodes().List(a.Context, metav1.ListOptions{}); err == nil {
+				nodes = nodeList.Items
+			}
+		}
+		return nodes
+	}
+	// pods and namespaces that affinity rules refer to, also listed once each.
+	podsByNamespace := map[string][]v1.Pod{}
+	var namespaces []v1.Namespace
+	var namespacesListed bool
+	affinity := affinityLookup{
+		nodes: listNodes,
+		pods: func(namespace string) []v1.Pod {
+			if pods, ok := podsByNamespace[namespace]; ok {
+				return pods
+			}
+			var pods []v1.Pod
+			if podList, err := a.Client.GetClient().CoreV1().Pods(namespace).List(a.Context, metav1.ListOptions{}); err == nil {
+				pods = append([]v1.Pod{}, podList.Items...)
+			}
+			podsByNamespace[namespace] = pods
+			return pods
+		},
+		namespaces: func() []v1.Namespace {
+			if !namespacesListed {
+				namespacesListed = true
+				if namespaceList, err := a.Client.GetClient().CoreV1().Namespaces().List(a.Context, metav1.ListOptions{}); err == nil {
+					namespaces = append([]v1.Namespace{}, namespaceList.Items...)
+				}
+			}
+			return namespaces
+		},
+	}
+	// keys of the ConfigMaps and Secrets used through envFrom, fetched once each
+	// when enabled, as reading Secrets takes more permissions.
+	envSources := map[string][]string{}
+	envSourceKeys := func(kind string, namespace string, name string) []string {
+		if !a.PodEnvSourceLookups {
+			return nil
+		}
+		cacheKey := kind + "/" + namespace + "/" + name
+		if keys, ok := envSources[cacheKey]; ok {
+			return keys
+		}
+		var keys []string
+		switch kind {
+		case "ConfigMap":
+			if cm, err := a.Client.GetClient().CoreV1().ConfigMaps(namespace).Get(a.Context, name, metav1.GetOptions{}); err == nil {
+				keys = append(mapKeys(cm.Data), mapKeys(cm.BinaryData)...)
+			}
+		case "Secret":
+			if secret, err := a.Client.GetClient().CoreV1().Secrets(namespace).Get(a.Context, name, metav1.GetOptions{}); err == nil {
+				keys = mapKeys(secret.Data)
+			}
+		}
+		envSources[cacheKey] = keys
+		return keys
+	}
 
 	for _, pod := range list.Items {
+		if util.CreatedWithin(pod.ObjectMeta, a.MinAge) {
+			continue
+		}
 		var failures []common.Failure
 
 		// Check for pending pods
@@ -54,24 +129,36 @@ func (PodAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 						failures = append(failures, common.Failure{
 							Text:      containerStatus.Message,
 							Sensitive: []common.Sensitive{},
+							Severity:  common.SeverityHigh,
 						})
 					}
+					if strings.Contains(containerStatus.Message, "taint") {
+						failures = append(failures, analyzeUntoleratedTaints(pod, listNodes())...)
+					}
+					if strings.Contains(containerStatus.Message, "affinity") {
+						failures = append(failures, analyzeAffinityConflicts(pod, affinity)...)
+					}
 				}
 			}
 		}
 
+		// Check for env vars defined more than once in a container.
+		failures = append(failures, analyzeDuplicateEnv(pod, envSourceKeys)...)
+
 		// Check for errors in the init containers.
-		failures = append(failures, analyzeContainerStatusFailures(a, pod.Status.InitContainerStatuses, pod.Name, pod.Namespace, string(pod.Status.Phase))...)
+		failures = append(failures, analyzeContainerStatusFailures(a, pod.Status.InitContainerStatuses, pod.Spec, pod.Name, pod.Namespace, string(pod.Status.Phase))...)
 
 		// Check for errors in containers.
-		failures = append(failures, analyzeContainerStatusFailures(a, pod.Status.ContainerStatuses, pod.Name, pod.Namespace, string(pod.Status.Phase))...)
+		failures = append(failures, analyzeContainerStatusFailures(a, pod.Status.ContainerStatuses, pod.Spec, pod.Name, pod.Namespace, string(pod.Status.Phase))...)
+
+		addPodSensitive(failures, pod)
 
 		if len(failures) > 0 {
 			preAnalysis[fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)] = common.PreAnalysis{
 				Pod:            pod,
 				FailureDetails: failures,
 			}
-			AnalyzerErrorsMetric.WithLabelValues(kind, pod.Name, pod.Namespace).Set(float64(len(failures)))
+			snapshot.Set(pod.Name, pod.Namespace, float64(len(failures)))
 		}
 	}
 
@@ -82,21 +169,30 @@ func (PodAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 			Error: value.FailureDetails,
 		}
 
-		parent, found := util.GetParent(a.Client, value.Pod.ObjectMeta)
+		// The Deployment of a pod, rather than its ReplicaSet.
+		parent, found := util.GetRootOwner(a.Client, value.Pod.ObjectMeta)
 		if found {
 			currentAnalysis.ParentObject = parent
 		}
 		a.Results = append(a.Results, currentAnalysis)
 	}
 
+	snapshot.Apply()
 	return a.Results, nil
 }
 
-func analyzeContainerStatusFailures(a common.Analyzer, statuses []v1.ContainerStatus, name string, namespace string, statusPhase string) []common.Failure {
+func analyzeContainerStatusFailures(a common.Analyzer, statuses []v1.ContainerStatus, spec v1.PodSpec, name string, namespace string, statusPhase string) []common.Failure {
 	var failures []common.Failure
+	// The metrics of the pod are only fetched for containers killed for lack of memory.
+	var memoryUsage map[string]resource.Quantity
+	var memoryUsageFetched bool
 
 	// Check through container status to check for crashes or unready
 	for _, containerStatus := range statuses {
+		if isContainerExcluded(containerStatus.Name, a.ExcludeContainers) {
+			continue
+		}
+		fieldPath := containerFieldPath(spec, containerStatus.Name)
 		if containerStatus.State.Waiting != nil {
 			if containerStatus.State.Waiting.Reason == "ContainerCreating" && statusPhase == "Pending" {
 				// This represents a container that is still being created or blocked due to conditions such as OOMKilled
@@ -105,22 +201,59 @@ func analyzeContainerStatusFailures(a common.Analyzer, statuses []v1.ContainerSt
 				if err != nil || evt == nil {
 					continue
 				}
-				if isEvtErrorReason(evt.Reason) && evt.Message != "" {
+				if isEvtErrorReason(evt.Reason, a.PodEventErrorReasons) && evt.Message != "" {
 					failures = append(failures, common.Failure{
 						Text:      evt.Message,
 						Sensitive: []common.Sensitive{},
+						Severity:  common.SeverityHigh,
 					})
 				}
 			} else if containerStatus.State.Waiting.Reason == "CrashLoopBackOff" && containerStatus.LastTerminationState.Terminated != nil {
 				// This represents container that is in CrashLoopBackOff state due to conditions such as OOMKilled
+				reason := containerStatus.LastTerminationState.Terminated.Reason
+				text := fmt.Sprintf("the last termination reason is %s container=%s pod=%s", reason, containerStatus.Name, name)
+				if reason == "OOMKilled" {
+					if !memoryUsageFetched {
+						memoryUsage, memoryUsageFetched = containerMemoryUsage(a, namespace, name), true
+					}
+					text += oomKilledDetails(spec, containerStatus.Name, memoryUsage)
+				}
 				failures = append(failures, common.Failure{
-					Text:      fmt.Sprintf("the last termination reason is %s container=%s pod=%s", containerStatus.LastTerminationState.Terminated.Reason, containerStatus.Name, name),
+					Text:      text,
 					Sensitive: []common.Sensitive{},
+					Severity:  common.SeverityHigh,
+					FieldPath: fieldPath,
 				})
-			} else if isErrorReason(containerStatus.State.Waiting.Reason) && containerStatus.State.Waiting.Message != "" {
+			} else if isImagePullReason(containerStatus.State.Waiting.Reason) && isImagePullRateLimited(a, containerStatus.State.Waiting.Message, namespace, name) {
+				// Rate limiting needs registry auth or a mirror, not a fixed image name, so it's reported apart.
+				failures = append(failures, common.Failure{
+					Text: fmt.Sprintf("ImagePullRateLimited: the registry is rate limiting pulls (toomanyrequests) of image %s for container %s. "+
+						"The image reference is not the problem; authenticate pulls with imagePullSecrets or use a pull-through cache or registry mirror", containerStatus.Image, containerStatus.Name),
+					Sensitive: []common.Sensitive{},
+					Severity:  common.SeverityMedium,
+					FieldPath: "spec.imagePullSecrets",
+				})
+			} else if isErrorReason(containerStatus.State.Waiting.Reason, a.PodErrorReasons) && containerStatus.State.Waiting.Message != "" {
+				if isImagePullReason(containerStatus.State.Waiting.Reason) {
+					// Denied pulls are usually fixed in imagePullSecrets rather than in the image reference.
+					if message, denied := imagePullFailure(a, imagePullAuthPattern, containerStatus.State.Waiting.Message, namespace, name); denied {
+						failures = append(failures, common.Failure{
+							Text:      imagePullAuthFailureText(a, spec, containerStatus, namespace, message),
+							Sensitive: []common.Sensitive{},
+							Severity:  common.SeverityHigh,
+							FieldPath: "spec.imagePullSecrets",
+						})
+						continue
+					}
+				}
+				if fieldPath != "" && isImagePullReason(containerStatus.State.Waiting.Reason) {
+					fieldPath += ".image"
+				}
 				failures = append(failures, common.Failure{
 					Text:      containerStatus.State.Waiting.Message,
 					Sensitive: []common.Sensitive{},
+					Severity:  common.SeverityHigh,
+					FieldPath: fieldPath,
 				})
 			}
 		} else {
@@ -132,9 +265,14 @@ func analyzeContainerStatusFailures(a common.Analyzer, statuses []v1.ContainerSt
 					continue
 				}
 				if evt.Reason == "Unhealthy" && evt.Message != "" {
+					if fieldPath != "" && strings.HasPrefix(evt.Message, "Readiness probe") {
+						fieldPath += ".readinessProbe"
+					}
 					failures = append(failures, common.Failure{
 						Text:      evt.Message,
 						Sensitive: []common.Sensitive{},
+						Severity:  common.SeverityMedium,
+						FieldPath: fieldPath,
 					})
 				}
 			}
@@ -144,29 +282,286 @@ func analyzeContainerStatusFailures(a common.Analyzer, statuses []v1.ContainerSt
 	return failures
 }
 
-func isErrorReason(reason string) bool {
-	failureReasons := []string{
-		"CrashLoopBackOff", "ImagePullBackOff", "CreateContainerConfigError", "PreCreateHookError", "CreateContainerError",
-		"PreStartHookError", "RunContainerError", "ImageInspectError", "ErrImagePull", "ErrImageNeverPull", "InvalidImageName",
+// containerFieldPath returns the path of the named container in the pod spec,
+// e.g. spec.containers[1], or nothing when it isn't in the spec.
+func containerFieldPath(spec v1.PodSpec, name string) string {
+	for i, container := range spec.InitContainers {
+		if container.Name == name {
+			return fmt.Sprintf("spec.initContainers[%d]", i)
+		}
+	}
+	for i, container := range spec.Containers {
+		if container.Name == name {
+			return fmt.Sprintf("spec.containers[%d]", i)
+		}
+	}
+	return ""
+}
+
+// addPodSensitive marks the identifiers of the pod found in the failure
+// texts as sensitive, so they are masked before being sent to the AI
+// provider when anonymizing.
+func addPodSensitive(failures []common.Failure, pod v1.Pod) {
+	values := podSensitiveValues(pod)
+	masked := map[string]string{}
+	for i := range failures {
+		for _, value := range values {
+			if !strings.Contains(failures[i].Text, value) {
+				continue
+			}
+			if _, ok := masked[value]; !ok {
+				masked[value] = util.MaskString(value)
+			}
+			failures[i].Sensitive = append(failures[i].Sensitive, common.Sensitive{
+				Unmasked: value,
+				Masked:   masked[value],
+			})
+		}
+	}
+}
+
+// podSensitiveValues returns the name, namespace, node, IP addresses and
+// referenced Secret names of the pod, longest first so that values are
+// masked before the shorter ones they may contain.
+func podSensitiveValues(pod v1.Pod) []string {
+	values := []string{pod.Name, pod.Namespace, pod.Spec.NodeName, pod.Status.HostIP, pod.Status.PodIP}
+	for _, ip := range pod.Status.PodIPs {
+		values = append(values, ip.IP)
+	}
+	for _, secret := range pod.Spec.ImagePullSecrets {
+		values = append(values, secret.Name)
+	}
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Secret != nil {
+			values = append(values, volume.Secret.SecretName)
+		}
+	}
+	for _, container := range append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef != nil {
+				values = append(values, envFrom.SecretRef.Name)
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+				values = append(values, env.ValueFrom.SecretKeyRef.Name)
+			}
+		}
 	}
 
-	for _, r := range failureReasons {
-		if r == reason {
+	seen := map[string]bool{"": true}
+	var unique []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	sort.SliceStable(unique, func(i, j int) bool {
+		return len(unique[i]) > len(unique[j])
+	})
+	return unique
+}
+
+// isContainerExcluded reports whether the container name matches one of the
+// glob patterns (as in path.Match) of the exclude_containers configuration.
+func isContainerExcluded(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, name); err == nil && matched {
 			return true
 		}
 	}
 	return false
 }
 
-func isEvtErrorReason(reason string) bool {
-	failureReasons := []string{
-		"FailedCreatePodSandBox", "FailedMount",
+// analyzeUntoleratedTaints reports each scheduling taint in the cluster that the pod
+// does not tolerate, along with the number of nodes carrying it.
+func analyzeUntoleratedTaints(pod v1.Pod, nodes []v1.Node) []common.Failure {
+	var failures []common.Failure
+
+	var taints []string
+	nodeCount := map[string]int{}
+	for _, node := range nodes {
+		for _, taint := range node.Spec.Taints {
+			if taint.Effect == v1.TaintEffectPreferNoSchedule || isTaintTolerated(taint, pod.Spec.Tolerations) {
+				continue
+			}
+			key := taint.ToString()
+			if _, ok := nodeCount[key]; !ok {
+				taints = append(taints, key)
+			}
+			nodeCount[key]++
+		}
+	}
+
+	for _, taint := range taints {
+		failures = append(failures, common.Failure{
+			Text:      fmt.Sprintf("pod %s does not tolerate taint %s present on %d node(s)", pod.Name, taint, nodeCount[taint]),
+			Sensitive: []common.Sensitive{},
+			Severity:  common.SeverityMedium,
+		})
 	}
+	return failures
+}
 
-	for _, r := range failureReasons {
-		if r == reason {
+func isTaintTolerated(taint v1.Taint, tolerations []v1.Toleration) bool {
+	for _, toleration := range tolerations {
+		if toleration.ToleratesTaint(&taint) {
 			return true
 		}
 	}
 	return false
 }
+
+var (
+	// errorReasons are the waiting reasons of containers reported as failures.
+	errorReasons = []string{
+		"CrashLoopBackOff", "ImagePullBackOff", "CreateContainerConfigError", "PreCreateHookError", "CreateContainerError",
+		"PreStartHookError", "RunContainerError", "ImageInspectError", "ErrImagePull", "ErrImageNeverPull", "InvalidImageName",
+	}
+	// evtErrorReasons are the reasons of the events of pending pods reported
+	// as failures.
+	evtErrorReasons = []string{
+		"FailedCreatePodSandBox", "FailedMount",
+	}
+)
+
+// isErrorReason reports whether a container waiting for this reason is
+// failing. The configured reasons are added to the built-in ones.
+func isErrorReason(reason string, configured []string) bool {
+	return slices.Contains(errorReasons, reason) || slices.Contains(configured, reason)
+}
+
+var (
+	// imagePullRateLimitPattern matches the errors registries return when pulls
+	// are rate limited, such as Docker Hub's "toomanyrequests".
+	imagePullRateLimitPattern = regexp.MustCompile(`(?i)toomanyrequests|429 too many requests|pull rate limit`)
+	// imagePullAuthPattern matches the errors registries return when pulls are
+	// denied, for missing or wrong credentials or, for some registries, an
+	// image that doesn't exist.
+	imagePullAuthPattern = regexp.MustCompile(`(?i)401 unauthorized|pull access denied|authentication required|no basic auth credentials`)
+)
+
+func isImagePullReason(reason string) bool {
+	return reason == "ImagePullBackOff" || reason == "ErrImagePull"
+}
+
+// isImagePullRateLimited reports whether the pull failure of the pod is due to
+// rate limiting.
+func isImagePullRateLimited(a common.Analyzer, message string, namespace string, name string) bool {
+	_, limited := imagePullFailure(a, imagePullRateLimitPattern, message, namespace, name)
+	return limited
+}
+
+// imagePullFailure looks for the pattern in the waiting message, or else in
+// the pull failure events of the pod, as the message of ImagePullBackOff
+// doesn't carry the pull error. It returns the message that matched.
+func imagePullFailure(a common.Analyzer, pattern *regexp.Regexp, message string, namespace string, name string) (string, bool) {
+	if pattern.MatchString(message) {
+		return message, true
+	}
+	if err := a.Client.Wait(a.Context); err != nil {
+		return "", false
+	}
+	events, err := a.Client.GetClient().CoreV1().Events(namespace).List(a.Context, metav1.ListOptions{
+		FieldSelector: "involvedObject.name=" + name,
+	})
+	if err != nil {
+		return "", false
+	}
+	for _, event := range events.Items {
+		if event.InvolvedObject.Name == name && event.Reason == "Failed" && pattern.MatchString(event.Message) {
+			return event.Message, true
+		}
+	}
+	return "", false
+}
+
+// imagePullAuthFailureText explains a denied pull with the state of the
+// imagePullSecrets of the pod: none, some that don't exist, or all present
+// but with credentials the registry didn't accept.
+func imagePullAuthFailureText(a common.Analyzer, spec v1.PodSpec, status v1.ContainerStatus, namespace string, message string) string {
+	text := fmt.Sprintf("ImagePullUnauthorized: the registry denied pulling image %s for container %s: %s. ", status.Image, status.Name, message)
+	if len(spec.ImagePullSecrets) == 0 {
+		return text + "The pod has no imagePullSecrets; add one with credentials for the registry, or check that the image exists"
+	}
+
+	var names, missing []string
+	for _, secret := range spec.ImagePullSecrets {
+		names = append(names, secret.Name)
+		// Secrets that can't be read for other reasons are assumed to exist.
+		if _, err := a.Client.GetClient().CoreV1().Secrets(namespace).Get(a.Context, secret.Name, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+			missing = append(missing, secret.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return text + fmt.Sprintf("The imagePullSecrets %s do not exist in namespace %s", strings.Join(missing, ", "), namespace)
+	}
+	return text + fmt.Sprintf("Check that the imagePullSecrets %s hold valid credentials for the registry of the image", strings.Join(names, ", "))
+}
+
+// isEvtErrorReason reports whether an event of a pending pod with this reason
+// is a failure. The configured reasons are added to the built-in ones.
+func isEvtErrorReason(reason string, configured []string) bool {
+	return slices.Contains(evtErrorReasons, reason) || slices.Contains(configured, reason)
+}
+
+// analyzeDuplicateEnv reports env vars defined more than once in a container,
+// either twice in env or by both env and envFrom, where one value silently
+// wins over the other. sourceKeys returns the keys of a ConfigMap or Secret,
+// and nothing when it can't be read or lookups are disabled.
+func analyzeDuplicateEnv(pod v1.Pod, sourceKeys func(kind string, namespace string, name string) []string) []common.Failure {
+	var failures []common.Failure
+
+	containers := append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for _, container := range containers {
+		var names []string
+		sources := map[string][]string{}
+		define := func(name string, source string) {
+			if _, ok := sources[name]; !ok {
+				names = append(names, name)
+			}
+			sources[name] = append(sources[name], source)
+		}
+
+		for _, envFrom := range container.EnvFrom {
+			var kind, name string
+			switch {
+			case envFrom.ConfigMapRef != nil:
+				kind, name = "ConfigMap", envFrom.ConfigMapRef.Name
+			case envFrom.SecretRef != nil:
+				kind, name = "Secret", envFrom.SecretRef.Name
+			default:
+				continue
+			}
+			for _, key := range sourceKeys(kind, pod.Namespace, name) {
+				define(envFrom.Prefix+key, fmt.Sprintf("envFrom %s %s", kind, name))
+			}
+		}
+		for _, env := range container.Env {
+			define(env.Name, "env")
+		}
+
+		for _, name := range names {
+			if len(sources[name]) < 2 {
+				continue
+			}
+			failures = append(failures, common.Failure{
+				Text:      fmt.Sprintf("container %s of pod %s defines env var %s more than once (%s)", container.Name, pod.Name, name, strings.Join(sources[name], ", ")),
+				Sensitive: []common.Sensitive{},
+				Severity:  common.SeverityMedium,
+				FieldPath: containerFieldPath(pod.Spec, container.Name) + ".env",
+			})
+		}
+	}
+	return failures
+}
+
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
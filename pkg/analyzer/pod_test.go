@@ -15,15 +15,24 @@ package analyzer
 
 import (
 	"context"
+	"errors"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
 	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestPodAnalyzer(t *testing.T) {
@@ -369,3 +378,915 @@ func TestPodAnalyzer(t *testing.T) {
 		})
 	}
 }
+
+func TestPodAnalyzerUntoleratedTaints(t *testing.T) {
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				&v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "Pod1",
+						Namespace: "default",
+					},
+					Spec: v1.PodSpec{
+						Tolerations: []v1.Toleration{
+							{
+								Key:      "dedicated",
+								Operator: v1.TolerationOpEqual,
+								Value:    "gpu",
+								Effect:   v1.TaintEffectNoSchedule,
+							},
+						},
+					},
+					Status: v1.PodStatus{
+						Phase: v1.PodPending,
+						Conditions: []v1.PodCondition{
+							{
+								Type:    v1.PodScheduled,
+								Reason:  "Unschedulable",
+								Message: "0/3 nodes are available: 3 node(s) had untolerated taint {node-role.kubernetes.io/control-plane: }.",
+							},
+						},
+					},
+				},
+				&v1.Node{
+					// Both nodes carry the same untolerated taint.
+					ObjectMeta: metav1.ObjectMeta{Name: "Node1"},
+					Spec: v1.NodeSpec{
+						Taints: []v1.Taint{
+							{Key: "node-role.kubernetes.io/control-plane", Effect: v1.TaintEffectNoSchedule},
+						},
+					},
+				},
+				&v1.Node{
+					ObjectMeta: metav1.ObjectMeta{Name: "Node2"},
+					Spec: v1.NodeSpec{
+						Taints: []v1.Taint{
+							{Key: "node-role.kubernetes.io/control-plane", Effect: v1.TaintEffectNoSchedule},
+							// PreferNoSchedule taints don't block scheduling.
+							{Key: "spot", Effect: v1.TaintEffectPreferNoSchedule},
+						},
+					},
+				},
+				&v1.Node{
+					ObjectMeta: metav1.ObjectMeta{Name: "Node3"},
+					Spec: v1.NodeSpec{
+						Taints: []v1.Taint{
+							// Tolerated by the pod.
+							{Key: "dedicated", Value: "gpu", Effect: v1.TaintEffectNoSchedule},
+						},
+					},
+				},
+			),
+		},
+		Context:   context.Background(),
+		Namespace: "default",
+	}
+
+	results, err := PodAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Len(t, results[0].Error, 2)
+	require.Equal(t, "0/3 nodes are available: 3 node(s) had untolerated taint {node-role.kubernetes.io/control-plane: }.", results[0].Error[0].Text)
+	require.Empty(t, results[0].Error[0].Sensitive)
+	// Only the control-plane taint is reported: the spot taint is
+	// PreferNoSchedule and the dedicated=gpu taint is tolerated.
+	require.Equal(t, "pod Pod1 does not tolerate taint node-role.kubernetes.io/control-plane:NoSchedule present on 2 node(s)", results[0].Error[1].Text)
+	require.Len(t, results[0].Error[1].Sensitive, 1)
+	require.Equal(t, "Pod1", results[0].Error[1].Sensitive[0].Unmasked)
+}
+
+func TestPodAnalyzerUntoleratedTaintsNodeListError(t *testing.T) {
+	unschedulable := func(name string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Status: v1.PodStatus{
+				Phase: v1.PodPending,
+				Conditions: []v1.PodCondition{
+					{
+						Type:    v1.PodScheduled,
+						Reason:  "Unschedulable",
+						Message: "0/1 nodes are available: 1 node(s) had untolerated taint {dedicated: gpu}.",
+					},
+				},
+			},
+		}
+	}
+	clientset := fake.NewSimpleClientset(unschedulable("Pod1"), unschedulable("Pod2"))
+	var nodeLists int
+	clientset.PrependReactor("list", "nodes", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		nodeLists++
+		return true, nil, errors.New("nodes is forbidden")
+	})
+
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: clientset,
+		},
+		Context:   context.Background(),
+		Namespace: "default",
+	}
+
+	results, err := PodAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for _, result := range results {
+		require.Len(t, result.Error, 1)
+		require.Equal(t, "0/1 nodes are available: 1 node(s) had untolerated taint {dedicated: gpu}.", result.Error[0].Text)
+	}
+	require.Equal(t, 1, nodeLists)
+}
+
+func TestPodAnalyzerMinAge(t *testing.T) {
+	pending := func(name string, age time.Duration) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+			},
+			Status: v1.PodStatus{
+				Phase: v1.PodPending,
+				Conditions: []v1.PodCondition{
+					{
+						Type:    v1.PodScheduled,
+						Reason:  "Unschedulable",
+						Message: "0/1 nodes are available: 1 Insufficient memory.",
+					},
+				},
+			},
+		}
+	}
+
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				pending("just-created", 10*time.Second),
+				pending("stuck", time.Hour),
+			),
+		},
+		Context:   context.Background(),
+		Namespace: "default",
+		MinAge:    time.Minute,
+	}
+
+	results, err := PodAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "default/stuck", results[0].Name)
+}
+
+func TestPodAnalyzerExcludeContainers(t *testing.T) {
+	crashLooping := func(name string) v1.ContainerStatus {
+		return v1.ContainerStatus{
+			Name: name,
+			State: v1.ContainerState{
+				Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+			},
+			LastTerminationState: v1.ContainerState{
+				Terminated: &v1.ContainerStateTerminated{Reason: "Error"},
+			},
+		}
+	}
+
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				&v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+					Spec: v1.PodSpec{
+						Containers: []v1.Container{{Name: "istio-proxy"}, {Name: "app"}, {Name: "log-shipper"}},
+					},
+					Status: v1.PodStatus{
+						Phase: v1.PodRunning,
+						ContainerStatuses: []v1.ContainerStatus{
+							crashLooping("app"),
+							crashLooping("istio-proxy"),
+							crashLooping("log-shipper"),
+						},
+					},
+				},
+			),
+		},
+		Context:           context.Background(),
+		Namespace:         "default",
+		ExcludeContainers: []string{"istio-proxy", "log-*"},
+	}
+
+	results, err := PodAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Len(t, results[0].Error, 1)
+	require.Equal(t, "the last termination reason is Error container=app pod=app", results[0].Error[0].Text)
+	require.Equal(t, "spec.containers[1]", results[0].Error[0].FieldPath)
+	require.Equal(t, common.SeverityHigh, results[0].Error[0].Severity)
+}
+
+func TestPodAnalyzerParentObject(t *testing.T) {
+	isController := true
+	ownedBy := func(kind string, name string) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: kind, Name: name, Controller: &isController}}
+	}
+
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}},
+				&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "web-5d8f", Namespace: "default", OwnerReferences: ownedBy("Deployment", "web")}},
+				&v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "web-5d8f-x2k4p", Namespace: "default", OwnerReferences: ownedBy("ReplicaSet", "web-5d8f")},
+					Status: v1.PodStatus{
+						Phase: v1.PodRunning,
+						ContainerStatuses: []v1.ContainerStatus{
+							{
+								Name:                 "web",
+								State:                v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+								LastTerminationState: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Reason: "Error"}},
+							},
+						},
+					},
+				},
+			),
+		},
+		Context:   context.Background(),
+		Namespace: "default",
+	}
+
+	results, err := PodAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "Deployment/web", results[0].ParentObject)
+}
+
+func TestPodAnalyzerCustomErrorReasons(t *testing.T) {
+	tests := []struct {
+		name            string
+		errorReasons    []string
+		evtErrorReasons []string
+		expected        []string
+	}{
+		{
+			name: "built-in reasons",
+		},
+		{
+			name:            "configured reasons",
+			errorReasons:    []string{"SandboxImagePullError"},
+			evtErrorReasons: []string{"FailedAttachVolume"},
+			expected: []string{
+				"AttachVolume.Attach failed for volume \"data\": volume is attached to another node",
+				"failed to pull the sandbox image registry.k8s.io/pause:3.9",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := common.Analyzer{
+				Client: &kubernetes.Client{
+					Client: fake.NewSimpleClientset(
+						&v1.Pod{
+							ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+							Status: v1.PodStatus{
+								Phase: v1.PodPending,
+								ContainerStatuses: []v1.ContainerStatus{
+									{
+										Name: "app",
+										State: v1.ContainerState{
+											Waiting: &v1.ContainerStateWaiting{Reason: "ContainerCreating"},
+										},
+									},
+									{
+										Name: "sidecar",
+										State: v1.ContainerState{
+											Waiting: &v1.ContainerStateWaiting{
+												Reason:  "SandboxImagePullError",
+												Message: "failed to pull the sandbox image registry.k8s.io/pause:3.9",
+											},
+										},
+									},
+								},
+							},
+						},
+						&v1.Event{
+							ObjectMeta:     metav1.ObjectMeta{Name: "app.1", Namespace: "default"},
+							InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: "app", Namespace: "default"},
+							Reason:         "FailedAttachVolume",
+							Message:        "AttachVolume.Attach failed for volume \"data\": volume is attached to another node",
+						},
+					),
+				},
+				Context:              context.Background(),
+				Namespace:            "default",
+				PodErrorReasons:      tt.errorReasons,
+				PodEventErrorReasons: tt.evtErrorReasons,
+			}
+
+			results, err := PodAnalyzer{}.Analyze(config)
+			require.NoError(t, err)
+
+			var got []string
+			for _, result := range results {
+				for _, failure := range result.Error {
+					got = append(got, failure.Text)
+				}
+			}
+			sort.Strings(got)
+			require.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestPodAnalyzerDuplicateEnv(t *testing.T) {
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				&v1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: "default"},
+					Data:       map[string]string{"LOG_LEVEL": "info", "PORT": "8080"},
+				},
+				&v1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "app-secret", Namespace: "default"},
+					Data:       map[string][]byte{"PASSWORD": []byte("secret")},
+				},
+				&v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+					Spec: v1.PodSpec{
+						Containers: []v1.Container{
+							{
+								Name: "app",
+								EnvFrom: []v1.EnvFromSource{
+									{ConfigMapRef: &v1.ConfigMapEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: "app-config"}}},
+									{SecretRef: &v1.SecretEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: "app-secret"}}, Prefix: "DB_"},
+									// Missing sources are reported by other checks, not here.
+									{ConfigMapRef: &v1.ConfigMapEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: "missing"}}},
+								},
+								Env: []v1.EnvVar{
+									{Name: "LOG_LEVEL", Value: "debug"},
+									{Name: "DB_PASSWORD", Value: "override"},
+									{Name: "MODE", Value: "a"},
+									{Name: "MODE", Value: "b"},
+									// The Secret key is prefixed, so this doesn't collide.
+									{Name: "PASSWORD", Value: "other"},
+								},
+							},
+						},
+					},
+					Status: v1.PodStatus{Phase: v1.PodRunning},
+				},
+			),
+		},
+		Context:   context.Background(),
+		Namespace: "default",
+	}
+
+	tests := []struct {
+		name          string
+		sourceLookups bool
+		expected      []string
+	}{
+		{
+			name: "env only",
+			expected: []string{
+				"container app of pod app defines env var MODE more than once (env, env)",
+			},
+		},
+		{
+			name:          "with envFrom sources",
+			sourceLookups: true,
+			expected: []string{
+				"container app of pod app defines env var LOG_LEVEL more than once (envFrom ConfigMap app-config, env)",
+				"container app of pod app defines env var DB_PASSWORD more than once (envFrom Secret app-secret, env)",
+				"container app of pod app defines env var MODE more than once (env, env)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := config
+			config.PodEnvSourceLookups = tt.sourceLookups
+
+			results, err := PodAnalyzer{}.Analyze(config)
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+
+			var texts []string
+			for _, failure := range results[0].Error {
+				require.Equal(t, common.SeverityMedium, failure.Severity)
+				texts = append(texts, failure.Text)
+			}
+			require.Equal(t, tt.expected, texts)
+		})
+	}
+}
+
+func TestPodAnalyzerImagePullRateLimited(t *testing.T) {
+	pulling := func(name string, reason string, message string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{{Name: "app"}},
+			},
+			Status: v1.PodStatus{
+				Phase: v1.PodPending,
+				ContainerStatuses: []v1.ContainerStatus{
+					{
+						Name:  "app",
+						Image: "docker.io/library/nginx:1.27",
+						State: v1.ContainerState{
+							Waiting: &v1.ContainerStateWaiting{Reason: reason, Message: message},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				pulling("limited", "ErrImagePull", `failed to pull and unpack image "docker.io/library/nginx:1.27": `+
+					`failed to copy: httpReadSeeker: failed open: unexpected status code 429 Too Many Requests - `+
+					`Server message: toomanyrequests: You have reached your pull rate limit.`),
+				// The back-off message doesn't carry the error, the pull event does.
+				pulling("backoff", "ImagePullBackOff", `Back-off pulling image "docker.io/library/nginx:1.27"`),
+				&v1.Event{
+					ObjectMeta:     metav1.ObjectMeta{Name: "backoff.1", Namespace: "default"},
+					InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: "backoff", Namespace: "default"},
+					Reason:         "Failed",
+					Message:        `Failed to pull image "docker.io/library/nginx:1.27": toomanyrequests: You have reached your pull rate limit.`,
+				},
+				pulling("typo", "ImagePullBackOff", `Back-off pulling image "docker.io/library/ngnix:1.27"`),
+			),
+		},
+		Context:   context.Background(),
+		Namespace: "default",
+	}
+
+	results, err := PodAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+
+	texts := map[string][]string{}
+	fieldPaths := map[string][]string{}
+	for _, result := range results {
+		for _, failure := range result.Error {
+			texts[result.Name] = append(texts[result.Name], failure.Text)
+			fieldPaths[result.Name] = append(fieldPaths[result.Name], failure.FieldPath)
+		}
+	}
+	rateLimited := "ImagePullRateLimited: the registry is rate limiting pulls (toomanyrequests) of image docker.io/library/nginx:1.27 for container app. " +
+		"The image reference is not the problem; authenticate pulls with imagePullSecrets or use a pull-through cache or registry mirror"
+	require.Equal(t, []string{rateLimited}, texts["default/limited"])
+	require.Equal(t, []string{rateLimited}, texts["default/backoff"])
+	require.Equal(t, []string{`Back-off pulling image "docker.io/library/ngnix:1.27"`}, texts["default/typo"])
+	require.Equal(t, []string{"spec.imagePullSecrets"}, fieldPaths["default/limited"])
+	require.Equal(t, []string{"spec.containers[0].image"}, fieldPaths["default/typo"])
+}
+
+func TestPodAnalyzerImagePullUnauthorized(t *testing.T) {
+	pulling := func(name string, message string, pullSecrets ...string) *v1.Pod {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{{Name: "app"}},
+			},
+			Status: v1.PodStatus{
+				Phase: v1.PodPending,
+				ContainerStatuses: []v1.ContainerStatus{
+					{
+						Name:  "app",
+						Image: "registry.example.com/team/app:1.0",
+						State: v1.ContainerState{
+							Waiting: &v1.ContainerStateWaiting{Reason: "ErrImagePull", Message: message},
+						},
+					},
+				},
+			},
+		}
+		for _, secret := range pullSecrets {
+			pod.Spec.ImagePullSecrets = append(pod.Spec.ImagePullSecrets, v1.LocalObjectReference{Name: secret})
+		}
+		return pod
+	}
+	unauthorized := `failed to pull and unpack image "registry.example.com/team/app:1.0": failed to resolve reference: unexpected status from HEAD request: 401 Unauthorized`
+
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				pulling("no-secrets", unauthorized),
+				pulling("missing-secret", unauthorized, "regcred", "gone"),
+				pulling("wrong-secret", unauthorized, "regcred"),
+				&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "regcred", Namespace: "default"}},
+				pulling("not-found", `failed to pull and unpack image "registry.example.com/team/app:1.0": not found`),
+			),
+		},
+		Context:   context.Background(),
+		Namespace: "default",
+	}
+
+	results, err := PodAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+
+	texts := map[string]string{}
+	fieldPaths := map[string]string{}
+	for _, result := range results {
+		require.Len(t, result.Error, 1)
+		texts[result.Name] = result.Error[0].Text
+		fieldPaths[result.Name] = result.Error[0].FieldPath
+	}
+	denied := "ImagePullUnauthorized: the registry denied pulling image registry.example.com/team/app:1.0 for container app: " + unauthorized + ". "
+	require.Equal(t, denied+"The pod has no imagePullSecrets; add one with credentials for the registry, or check that the image exists", texts["default/no-secrets"])
+	require.Equal(t, denied+"The imagePullSecrets gone do not exist in namespace default", texts["default/missing-secret"])
+	require.Equal(t, denied+"Check that the imagePullSecrets regcred hold valid credentials for the registry of the image", texts["default/wrong-secret"])
+	require.Equal(t, "spec.imagePullSecrets", fieldPaths["default/wrong-secret"])
+	require.NotContains(t, texts["default/not-found"], "ImagePullUnauthorized")
+	require.Equal(t, "spec.containers[0].image", fieldPaths["default/not-found"])
+}
+
+func TestPodAnalyzerFieldSelector(t *testing.T) {
+	tests := []struct {
+		fieldSelector string
+		expected      string
+	}{
+		{fieldSelector: "spec.nodeName=node-1", expected: "spec.nodeName=node-1"},
+		// Fields pods can't be selected by are not sent to the API server.
+		{fieldSelector: "spec.replicas=0", expected: ""},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.fieldSelector, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			var listed string
+			clientset.PrependReactor("list", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				listed = action.(clienttesting.ListAction).GetListRestrictions().Fields.String()
+				return true, &v1.PodList{}, nil
+			})
+
+			config := common.Analyzer{
+				Client:        &kubernetes.Client{Client: clientset},
+				Context:       context.Background(),
+				Namespace:     "default",
+				FieldSelector: tt.fieldSelector,
+			}
+			_, err := PodAnalyzer{}.Analyze(config)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, listed)
+		})
+	}
+}
+
+func TestPodAnalyzerSensitive(t *testing.T) {
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				&v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "payments", Namespace: "prod"},
+					Spec: v1.PodSpec{
+						Containers: []v1.Container{
+							{
+								Name: "api",
+								Env: []v1.EnvVar{
+									{Name: "TOKEN", ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{
+										LocalObjectReference: v1.LocalObjectReference{Name: "payments-token"},
+										Key:                  "token",
+									}}},
+								},
+							},
+						},
+					},
+					Status: v1.PodStatus{
+						Phase: v1.PodPending,
+						ContainerStatuses: []v1.ContainerStatus{
+							{
+								Name: "api",
+								State: v1.ContainerState{
+									Waiting: &v1.ContainerStateWaiting{
+										Reason:  "CreateContainerConfigError",
+										Message: `secret "payments-token" not found`,
+									},
+								},
+							},
+						},
+					},
+				},
+			),
+		},
+		Context:   context.Background(),
+		Namespace: "prod",
+	}
+
+	results, err := PodAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Len(t, results[0].Error, 1)
+
+	// The Secret name is masked as a whole, before the pod name it contains.
+	sensitive := results[0].Error[0].Sensitive
+	require.Len(t, sensitive, 2)
+	require.Equal(t, "payments-token", sensitive[0].Unmasked)
+	require.Equal(t, "payments", sensitive[1].Unmasked)
+	require.NotEqual(t, sensitive[0].Unmasked, sensitive[0].Masked)
+}
+
+func TestPodAnalyzerAffinityConflicts(t *testing.T) {
+	node := func(name string, nodeLabels map[string]string) *v1.Node {
+		return &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: nodeLabels}}
+	}
+	placed := func(name string, namespace string, nodeName string, podLabels map[string]string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: podLabels},
+			Spec:       v1.PodSpec{NodeName: nodeName},
+			Status:     v1.PodStatus{Phase: v1.PodRunning},
+		}
+	}
+	pending := func(spec v1.PodSpec) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "Pending", Namespace: "default", Labels: map[string]string{"app": "web"}},
+			Spec:       spec,
+			Status: v1.PodStatus{
+				Phase: v1.PodPending,
+				Conditions: []v1.PodCondition{
+					{
+						Type:    v1.PodScheduled,
+						Reason:  "Unschedulable",
+						Message: "0/2 nodes are available: 2 node(s) didn't match Pod's node affinity/selector.",
+					},
+				},
+			},
+		}
+	}
+	nodes := []runtime.Object{
+		node("node-a", map[string]string{"kubernetes.io/hostname": "node-a", "topology.kubernetes.io/zone": "us-east-1b", "disk": "ssd"}),
+		node("node-b", map[string]string{"kubernetes.io/hostname": "node-b", "topology.kubernetes.io/zone": "us-east-1c", "gpu": "true"}),
+	}
+	term := func(topologyKey string, matchLabels map[string]string) v1.PodAffinityTerm {
+		return v1.PodAffinityTerm{
+			LabelSelector: &metav1.LabelSelector{MatchLabels: matchLabels},
+			TopologyKey:   topologyKey,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		pod      *v1.Pod
+		objects  []runtime.Object
+		expected []string
+	}{
+		{
+			name: "nodeSelector label on no node",
+			pod: pending(v1.PodSpec{
+				NodeSelector: map[string]string{"topology.kubernetes.io/zone": "us-east-1a", "disk": "ssd"},
+			}),
+			expected: []string{"pod Pending requires label topology.kubernetes.io/zone=us-east-1a in its nodeSelector, but no node has it"},
+		},
+		{
+			name: "nodeSelector labels on different nodes",
+			pod: pending(v1.PodSpec{
+				NodeSelector: map[string]string{"disk": "ssd", "gpu": "true"},
+			}),
+			expected: []string{"pod Pending requires labels disk=ssd, gpu=true in its nodeSelector, but no node has all of them"},
+		},
+		{
+			name: "node affinity matched by no node",
+			pod: pending(v1.PodSpec{
+				Affinity: &v1.Affinity{
+					NodeAffinity: &v1.NodeAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+							NodeSelectorTerms: []v1.NodeSelectorTerm{
+								{
+									MatchExpressions: []v1.NodeSelectorRequirement{
+										{Key: "topology.kubernetes.io/zone", Operator: v1.NodeSelectorOpIn, Values: []string{"us-east-1a"}},
+										{Key: "disk", Operator: v1.NodeSelectorOpExists},
+									},
+								},
+							},
+						},
+					},
+				},
+			}),
+			expected: []string{"pod Pending requires label topology.kubernetes.io/zone=us-east-1a in its node affinity, but no node matches it"},
+		},
+		{
+			name: "node affinity satisfied by another term",
+			pod: pending(v1.PodSpec{
+				Affinity: &v1.Affinity{
+					NodeAffinity: &v1.NodeAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+							NodeSelectorTerms: []v1.NodeSelectorTerm{
+								{
+									MatchExpressions: []v1.NodeSelectorRequirement{
+										{Key: "topology.kubernetes.io/zone", Operator: v1.NodeSelectorOpIn, Values: []string{"us-east-1a"}},
+									},
+								},
+								{
+									MatchFields: []v1.NodeSelectorRequirement{
+										{Key: "metadata.name", Operator: v1.NodeSelectorOpIn, Values: []string{"node-b"}},
+									},
+								},
+							},
+						},
+					},
+				},
+			}),
+		},
+		{
+			name: "podAffinity without a matching pod",
+			pod: pending(v1.PodSpec{
+				Affinity: &v1.Affinity{
+					PodAffinity: &v1.PodAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{
+							term("topology.kubernetes.io/zone", map[string]string{"app": "cache"}),
+						},
+					},
+				},
+			}),
+			objects: []runtime.Object{
+				// Only pods in the pod's own namespace count.
+				placed("cache", "other", "node-a", map[string]string{"app": "cache"}),
+			},
+			expected: []string{"pod Pending requires a pod matching app=cache in namespace default in its podAffinity, but there is none running"},
+		},
+		{
+			name: "podAffinity on nodes without the topology label",
+			pod: pending(v1.PodSpec{
+				Affinity: &v1.Affinity{
+					PodAffinity: &v1.PodAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{
+							term("rack", map[string]string{"app": "cache"}),
+						},
+					},
+				},
+			}),
+			objects: []runtime.Object{
+				placed("cache", "default", "node-a", map[string]string{"app": "cache"}),
+			},
+			expected: []string{"pod Pending requires a pod matching app=cache in its podAffinity, but the 1 matching pod(s) run on nodes without the topology label rack"},
+		},
+		{
+			name: "podAffinity matching the pod itself",
+			pod: pending(v1.PodSpec{
+				Affinity: &v1.Affinity{
+					PodAffinity: &v1.PodAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{
+							term("kubernetes.io/hostname", map[string]string{"app": "web"}),
+						},
+					},
+				},
+			}),
+		},
+		{
+			name: "podAntiAffinity ruling out every node",
+			pod: pending(v1.PodSpec{
+				Affinity: &v1.Affinity{
+					PodAntiAffinity: &v1.PodAntiAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{
+							term("kubernetes.io/hostname", map[string]string{"app": "web"}),
+						},
+					},
+				},
+			}),
+			objects: []runtime.Object{
+				placed("web-1", "default", "node-a", map[string]string{"app": "web"}),
+				placed("web-2", "default", "node-b", map[string]string{"app": "web"}),
+			},
+			expected: []string{"pod Pending must not share a kubernetes.io/hostname with a pod matching app=web in its podAntiAffinity, but all 2 kubernetes.io/hostname domain(s) already run one"},
+		},
+		{
+			name: "podAntiAffinity leaving a node free",
+			pod: pending(v1.PodSpec{
+				Affinity: &v1.Affinity{
+					PodAntiAffinity: &v1.PodAntiAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{
+							term("kubernetes.io/hostname", map[string]string{"app": "web"}),
+						},
+					},
+				},
+			}),
+			objects: []runtime.Object{
+				placed("web-1", "default", "node-a", map[string]string{"app": "web"}),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := append([]runtime.Object{tt.pod}, nodes...)
+			config := common.Analyzer{
+				Client: &kubernetes.Client{
+					Client: fake.NewSimpleClientset(append(objects, tt.objects...)...),
+				},
+				Context:   context.Background(),
+				Namespace: "default",
+			}
+			results, err := PodAnalyzer{}.Analyze(config)
+			require.NoError(t, err)
+			var texts []string
+			for _, result := range results {
+				if result.Name != "default/Pending" {
+					continue
+				}
+				// The first failure is the scheduler message.
+				for _, failure := range result.Error[1:] {
+					texts = append(texts, failure.Text)
+				}
+			}
+			require.Equal(t, tt.expected, texts)
+		})
+	}
+}
+
+func TestPodAnalyzerOOMKilled(t *testing.T) {
+	oomKilled := func(name string) v1.ContainerStatus {
+		return v1.ContainerStatus{
+			Name: name,
+			State: v1.ContainerState{
+				Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+			},
+			LastTerminationState: v1.ContainerState{
+				Terminated: &v1.ContainerStateTerminated{Reason: "OOMKilled"},
+			},
+		}
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name: "app",
+					Resources: v1.ResourceRequirements{
+						Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("256Mi")},
+					},
+				},
+				{Name: "sidecar"},
+			},
+		},
+		Status: v1.PodStatus{
+			Phase:             v1.PodRunning,
+			ContainerStatuses: []v1.ContainerStatus{oomKilled("app"), oomKilled("sidecar")},
+		},
+	}
+
+	metrics := &unstructured.Unstructured{}
+	metrics.SetAPIVersion(metricsGroupVersion)
+	metrics.SetKind("PodMetrics")
+	metrics.SetName("app")
+	metrics.SetNamespace("default")
+	require.NoError(t, unstructured.SetNestedSlice(metrics.Object, []interface{}{
+		map[string]interface{}{"name": "app", "usage": map[string]interface{}{"cpu": "10m", "memory": "250Mi"}},
+		map[string]interface{}{"name": "sidecar", "usage": map[string]interface{}{"cpu": "1m", "memory": "12Mi"}},
+	}, "containers"))
+
+	tests := []struct {
+		name     string
+		metrics  bool
+		expected []string
+	}{
+		{
+			name:    "with metrics-server",
+			metrics: true,
+			expected: []string{
+				"the last termination reason is OOMKilled container=app pod=app; its memory limit is 256Mi, and it currently uses 250Mi",
+				"the last termination reason is OOMKilled container=sidecar pod=app; it has no memory limit, so it was killed as its node ran out of memory, and it currently uses 12Mi",
+			},
+		},
+		{
+			name: "without metrics-server",
+			expected: []string{
+				"the last termination reason is OOMKilled container=app pod=app; its memory limit is 256Mi",
+				"the last termination reason is OOMKilled container=sidecar pod=app; it has no memory limit, so it was killed as its node ran out of memory",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(pod)
+			if tt.metrics {
+				clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+					{
+						GroupVersion: metricsGroupVersion,
+						APIResources: []metav1.APIResource{{Name: "pods", Kind: "PodMetrics", Namespaced: true}},
+					},
+				}
+			}
+			config := common.Analyzer{
+				Client: &kubernetes.Client{
+					Client:     clientset,
+					CtrlClient: ctrlfake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(metrics).Build(),
+				},
+				Context:   context.Background(),
+				Namespace: "default",
+			}
+
+			results, err := PodAnalyzer{}.Analyze(config)
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			var texts []string
+			for _, failure := range results[0].Error {
+				texts = append(texts, failure.Text)
+			}
+			require.Equal(t, tt.expected, texts)
+		})
+	}
+}
@@ -0,0 +1,269 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// preemptorPattern finds the UID of the preemptor in the message of a
+// Preempted event, e.g. "Preempted by pod 6d5d... on node worker-1".
+var preemptorPattern = regexp.MustCompile(`Preempted by (?:pod )?([0-9a-f-]{36})`)
+
+// PriorityClassAnalyzer reports workloads and pods referencing a
+// PriorityClass that doesn't exist, pods preempted by higher priority pods,
+// and Pending pods that preempting lower priority pods doesn't make room for.
+// The priorities involved are included, as capacity problems depend on them.
+type PriorityClassAnalyzer struct{}
+
+func (PriorityClassAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	analyzerName := "PriorityClass"
+
+	snapshot := newAnalyzerErrorsSnapshot(analyzerName)
+
+	classList, err := a.Client.GetClient().SchedulingV1().PriorityClasses().List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	classes := map[string]schedulingv1.PriorityClass{}
+	for _, pc := range classList.Items {
+		classes[pc.Name] = pc
+	}
+
+	pods, err := a.Client.GetClient().CoreV1().Pods(a.Namespace).List(a.Context, metav1.ListOptions{
+		LabelSelector: a.LabelSelector,
+		FieldSelector: util.FieldSelectorFor("Pod", a.FieldSelector),
+	})
+	if err != nil {
+		return nil, err
+	}
+	events, err := a.Client.GetClient().CoreV1().Events(a.Namespace).List(a.Context, metav1.ListOptions{
+		FieldSelector: "involvedObject.kind=Pod",
+	})
+	if err != nil {
+		return nil, err
+	}
+	preempted, failedScheduling := latestPreemptionEvents(events.Items)
+
+	var results []common.Result
+	addResult := func(kind string, namespace string, name string, failure common.Failure) {
+		failure.Sensitive = append(failure.Sensitive,
+			common.Sensitive{Unmasked: namespace, Masked: util.MaskString(namespace)},
+			common.Sensitive{Unmasked: name, Masked: util.MaskString(name)},
+		)
+		snapshot.Set(name, namespace, 1)
+		results = append(results, common.Result{
+			Kind:  kind,
+			Name:  fmt.Sprintf("%s/%s", namespace, name),
+			Error: []common.Failure{failure},
+		})
+	}
+
+	// Missing classes are caught by admission for new pods, so they show up
+	// in the pod templates of workloads, whose pods can't be created.
+	templates, err := workloadPodTemplates(a)
+	if err != nil {
+		return nil, err
+	}
+	for _, template := range templates {
+		className := template.spec.PriorityClassName
+		if className == "" || util.CreatedWithin(template.meta, a.MinAge) {
+			continue
+		}
+		if _, ok := classes[className]; !ok {
+			addResult(template.kind, template.meta.Namespace, template.meta.Name, common.Failure{
+				Text: fmt.Sprintf("%s %s/%s references the PriorityClass %s in its pod template, which does not exist, so its pods can't be created",
+					template.kind, template.meta.Namespace, template.meta.Name, className),
+				Sensitive: []common.Sensitive{{Unmasked: className, Masked: util.MaskString(className)}},
+				FieldPath: "spec.template.spec.priorityClassName",
+				Severity:  common.SeverityHigh,
+			})
+		}
+	}
+
+	podsByUID := map[types.UID]v1.Pod{}
+	for _, pod := range pods.Items {
+		podsByUID[pod.UID] = pod
+	}
+	for _, pod := range pods.Items {
+		if util.CreatedWithin(pod.ObjectMeta, a.MinAge) {
+			continue
+		}
+		if className := pod.Spec.PriorityClassName; className != "" {
+			if _, ok := classes[className]; !ok {
+				addResult("Pod", pod.Namespace, pod.Name, common.Failure{
+					Text:      fmt.Sprintf("Pod %s/%s references the PriorityClass %s, which does not exist", pod.Namespace, pod.Name, className),
+					Sensitive: []common.Sensitive{{Unmasked: className, Masked: util.MaskString(className)}},
+					FieldPath: "spec.priorityClassName",
+					Severity:  common.SeverityHigh,
+				})
+				continue
+			}
+		}
+
+		key := pod.Namespace + "/" + pod.Name
+		if event, ok := failedScheduling[key]; ok && pod.Status.Phase == v1.PodPending {
+			addResult("Pod", pod.Namespace, pod.Name, common.Failure{
+				Text: fmt.Sprintf("Pod %s/%s with %s can't be scheduled, and preempting lower priority pods doesn't make room for it: %s",
+					pod.Namespace, pod.Name, describePodPriority(pod, classes), event.Message),
+				FieldPath: "spec.priorityClassName",
+				Severity:  common.SeverityMedium,
+			})
+		}
+		if event, ok := preempted[key]; ok {
+			addResult("Pod", pod.Namespace, pod.Name, common.Failure{
+				Text: fmt.Sprintf("Pod %s/%s with %s was preempted%s: %s",
+					pod.Namespace, pod.Name, describePodPriority(pod, classes), describePreemptor(event, podsByUID, classes), event.Message),
+				FieldPath: "spec.priorityClassName",
+				Severity:  common.SeverityMedium,
+			})
+		}
+	}
+
+	// Preempted pods are usually deleted, leaving only the event behind.
+	// They can't be matched against selectors, so they're only reported
+	// without one.
+	if a.LabelSelector == "" && a.FieldSelector == "" {
+		seen := map[string]bool{}
+		for _, pod := range pods.Items {
+			seen[pod.Namespace+"/"+pod.Name] = true
+		}
+		keys := make([]string, 0, len(preempted))
+		for key := range preempted {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			event := preempted[key]
+			if seen[key] || util.CreatedWithin(event.ObjectMeta, a.MinAge) {
+				continue
+			}
+			namespace, name := event.InvolvedObject.Namespace, event.InvolvedObject.Name
+			addResult("Pod", namespace, name, common.Failure{
+				Text: fmt.Sprintf("Pod %s/%s was preempted and deleted%s: %s",
+					namespace, name, describePreemptor(event, podsByUID, classes), event.Message),
+				Severity: common.SeverityMedium,
+			})
+		}
+	}
+
+	a.Results = append(a.Results, results...)
+	snapshot.Apply()
+	return a.Results, nil
+}
+
+// latestPreemptionEvents returns the latest Preempted event, and the latest
+// FailedScheduling event mentioning preemption, of each pod by namespace/name.
+func latestPreemptionEvents(events []v1.Event) (map[string]v1.Event, map[string]v1.Event) {
+	preempted := map[string]v1.Event{}
+	failedScheduling := map[string]v1.Event{}
+	keep := func(latest map[string]v1.Event, event v1.Event) {
+		key := event.InvolvedObject.Namespace + "/" + event.InvolvedObject.Name
+		if current, ok := latest[key]; !ok || event.LastTimestamp.After(current.LastTimestamp.Time) {
+			latest[key] = event
+		}
+	}
+	for _, event := range events {
+		if event.InvolvedObject.Kind != "Pod" {
+			continue
+		}
+		switch {
+		case event.Reason == "Preempted":
+			keep(preempted, event)
+		case event.Reason == "FailedScheduling" && strings.Contains(event.Message, "preemption"):
+			keep(failedScheduling, event)
+		}
+	}
+	return preempted, failedScheduling
+}
+
+// describePodPriority describes the priority of a pod and its class, e.g.
+// "priority 1000 (PriorityClass high)". The priority set by admission is
+// preferred, the one of the class otherwise.
+func describePodPriority(pod v1.Pod, classes map[string]schedulingv1.PriorityClass) string {
+	var priority int32
+	if pod.Spec.Priority != nil {
+		priority = *pod.Spec.Priority
+	} else if pc, ok := classes[pod.Spec.PriorityClassName]; ok {
+		priority = pc.Value
+	}
+	if pod.Spec.PriorityClassName == "" {
+		return fmt.Sprintf("priority %d (no PriorityClass)", priority)
+	}
+	return fmt.Sprintf("priority %d (PriorityClass %s)", priority, pod.Spec.PriorityClassName)
+}
+
+// describePreemptor names the pod that preempted another, when its UID in
+// the event message belongs to a listed pod.
+func describePreemptor(event v1.Event, podsByUID map[types.UID]v1.Pod, classes map[string]schedulingv1.PriorityClass) string {
+	match := preemptorPattern.FindStringSubmatch(event.Message)
+	if match == nil {
+		return ""
+	}
+	preemptor, ok := podsByUID[types.UID(match[1])]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf(" by pod %s/%s with %s", preemptor.Namespace, preemptor.Name, describePodPriority(preemptor, classes))
+}
+
+// podTemplate is the pod template of a workload.
+type podTemplate struct {
+	kind string
+	meta metav1.ObjectMeta
+	spec v1.PodSpec
+}
+
+// workloadPodTemplates returns the pod templates of the Deployments,
+// StatefulSets and DaemonSets matching the selectors.
+func workloadPodTemplates(a common.Analyzer) ([]podTemplate, error) {
+	var templates []podTemplate
+	apps := a.Client.GetClient().AppsV1()
+
+	deployments, err := apps.Deployments(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor("Deployment", a.FieldSelector)})
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range deployments.Items {
+		templates = append(templates, podTemplate{kind: "Deployment", meta: d.ObjectMeta, spec: d.Spec.Template.Spec})
+	}
+
+	statefulSets, err := apps.StatefulSets(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor("StatefulSet", a.FieldSelector)})
+	if err != nil {
+		return nil, err
+	}
+	for _, sts := range statefulSets.Items {
+		templates = append(templates, podTemplate{kind: "StatefulSet", meta: sts.ObjectMeta, spec: sts.Spec.Template.Spec})
+	}
+
+	daemonSets, err := apps.DaemonSets(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor("DaemonSet", a.FieldSelector)})
+	if err != nil {
+		return nil, err
+	}
+	for _, ds := range daemonSets.Items {
+		templates = append(templates, podTemplate{kind: "DaemonSet", meta: ds.ObjectMeta, spec: ds.Spec.Template.Spec})
+	}
+	return templates, nil
+}
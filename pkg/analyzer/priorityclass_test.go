@@ -0,0 +1,174 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func priorityPod(name string, className string, priority int32, phase v1.PodPhase) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID("00000000-0000-0000-0000-0000000000" + name[len(name)-2:])},
+		Spec:       v1.PodSpec{PriorityClassName: className, Priority: &priority},
+		Status:     v1.PodStatus{Phase: phase},
+	}
+}
+
+func podEvent(name string, pod string, reason string, message string) *v1.Event {
+	return &v1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default"},
+		InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: pod, Namespace: "default"},
+		Reason:         reason,
+		Message:        message,
+	}
+}
+
+func TestPriorityClassAnalyzer(t *testing.T) {
+	high := &schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "high"}, Value: 1000}
+	low := &schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "low"}, Value: 10}
+
+	tests := []struct {
+		name     string
+		objects  []runtime.Object
+		expected map[string]string
+	}{
+		{
+			name: "pods with existing classes and no preemption",
+			objects: []runtime.Object{
+				high, low,
+				priorityPod("web-01", "high", 1000, v1.PodRunning),
+				priorityPod("batch-02", "low", 10, v1.PodRunning),
+			},
+			expected: map[string]string{},
+		},
+		{
+			name: "pod referencing a missing class",
+			objects: []runtime.Object{
+				high,
+				priorityPod("web-01", "critical", 0, v1.PodPending),
+			},
+			expected: map[string]string{
+				"Pod default/web-01": "Pod default/web-01 references the PriorityClass critical, which does not exist",
+			},
+		},
+		{
+			name: "deployment template referencing a missing class",
+			objects: []runtime.Object{
+				high,
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+					Spec: appsv1.DeploymentSpec{
+						Template: v1.PodTemplateSpec{Spec: v1.PodSpec{PriorityClassName: "critical"}},
+					},
+				},
+			},
+			expected: map[string]string{
+				"Deployment default/api": "Deployment default/api references the PriorityClass critical in its pod template, which does not exist, so its pods can't be created",
+			},
+		},
+		{
+			name: "preempted pod with its preemptor",
+			objects: []runtime.Object{
+				high, low,
+				priorityPod("web-01", "high", 1000, v1.PodRunning),
+				priorityPod("batch-02", "low", 10, v1.PodFailed),
+				podEvent("batch-02.1", "batch-02", "Preempted", "Preempted by pod 00000000-0000-0000-0000-000000000001 on node worker-1"),
+			},
+			expected: map[string]string{
+				"Pod default/batch-02": "Pod default/batch-02 with priority 10 (PriorityClass low) was preempted by pod default/web-01 with priority 1000 (PriorityClass high): Preempted by pod 00000000-0000-0000-0000-000000000001 on node worker-1",
+			},
+		},
+		{
+			name: "deleted preempted pod",
+			objects: []runtime.Object{
+				high,
+				podEvent("batch-02.1", "batch-02", "Preempted", "Preempted by pod 00000000-0000-0000-0000-000000000009 on node worker-1"),
+			},
+			expected: map[string]string{
+				"Pod default/batch-02": "Pod default/batch-02 was preempted and deleted: Preempted by pod 00000000-0000-0000-0000-000000000009 on node worker-1",
+			},
+		},
+		{
+			name: "pending pod preemption doesn't help",
+			objects: []runtime.Object{
+				high,
+				priorityPod("web-01", "high", 1000, v1.PodPending),
+				podEvent("web-01.1", "web-01", "FailedScheduling", "0/3 nodes are available: 3 Insufficient cpu. preemption: 0/3 nodes are available: 3 No preemption victims found for incoming pod."),
+			},
+			expected: map[string]string{
+				"Pod default/web-01": "Pod default/web-01 with priority 1000 (PriorityClass high) can't be scheduled, and preempting lower priority pods doesn't make room for it: 0/3 nodes are available: 3 Insufficient cpu. preemption: 0/3 nodes are available: 3 No preemption victims found for incoming pod.",
+			},
+		},
+		{
+			name: "failed scheduling without preemption",
+			objects: []runtime.Object{
+				high,
+				priorityPod("web-01", "high", 1000, v1.PodPending),
+				podEvent("web-01.1", "web-01", "FailedScheduling", "0/3 nodes are available: 3 node(s) didn't match Pod's node affinity/selector."),
+			},
+			expected: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := common.Analyzer{
+				Client: &kubernetes.Client{
+					Client: fake.NewSimpleClientset(tt.objects...),
+				},
+				Context:   context.Background(),
+				Namespace: "default",
+			}
+
+			results, err := PriorityClassAnalyzer{}.Analyze(config)
+			require.NoError(t, err)
+
+			got := map[string]string{}
+			for _, result := range results {
+				require.Len(t, result.Error, 1)
+				got[result.Kind+" "+result.Name] = result.Error[0].Text
+			}
+			require.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestPriorityClassAnalyzerSelectorSkipsDeletedPods(t *testing.T) {
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				podEvent("batch-02.1", "batch-02", "Preempted", "Preempted by pod 00000000-0000-0000-0000-000000000009 on node worker-1"),
+			),
+		},
+		Context:       context.Background(),
+		Namespace:     "default",
+		LabelSelector: "app=batch",
+	}
+
+	results, err := PriorityClassAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	require.Empty(t, results)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PersistentVolumeAnalyzer reports PersistentVolumes that are Released or
+// Failed, bound to claims that no longer exist, or of a StorageClass that
+// no longer exists. PersistentVolumes are cluster scoped, so the namespace
+// of the analysis doesn't apply.
+type PersistentVolumeAnalyzer struct{}
+
+func (PersistentVolumeAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "PersistentVolume"
+
+	snapshot := newAnalyzerErrorsSnapshot(kind)
+
+	list, err := a.Client.GetClient().CoreV1().PersistentVolumes().List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor(kind, a.FieldSelector)})
+	if err != nil {
+		return nil, err
+	}
+
+	// StorageClasses are looked up once each.
+	storageClassExists := map[string]bool{}
+
+	var preAnalysis = map[string]common.PreAnalysis{}
+
+	for _, pv := range list.Items {
+		if util.CreatedWithin(pv.ObjectMeta, a.MinAge) {
+			continue
+		}
+		var failures []common.Failure
+		sensitive := []common.Sensitive{
+			{
+				Unmasked: pv.Name,
+				Masked:   util.MaskString(pv.Name),
+			},
+		}
+		policy := pv.Spec.PersistentVolumeReclaimPolicy
+
+		switch pv.Status.Phase {
+		case v1.VolumeFailed:
+			failures = append(failures, common.Failure{
+				Text:      fmt.Sprintf("PersistentVolume %s is in phase %s with reclaim policy %s: %s", pv.Name, pv.Status.Phase, policy, pv.Status.Message),
+				Sensitive: sensitive,
+				Severity:  common.SeverityHigh,
+			})
+		case v1.VolumeReleased:
+			text := fmt.Sprintf("PersistentVolume %s is in phase %s with reclaim policy %s: its claim was deleted and it can't be bound again until it is deleted or its claimRef is removed", pv.Name, pv.Status.Phase, policy)
+			if pv.Spec.ClaimRef != nil {
+				text = fmt.Sprintf("PersistentVolume %s is in phase %s with reclaim policy %s: its claim %s/%s was deleted and it can't be bound again until it is deleted or its claimRef is removed",
+					pv.Name, pv.Status.Phase, policy, pv.Spec.ClaimRef.Namespace, pv.Spec.ClaimRef.Name)
+			}
+			failures = append(failures, common.Failure{
+				Text:      text,
+				Sensitive: sensitive,
+				Severity:  common.SeverityLow,
+			})
+		case v1.VolumeBound:
+			if claim := pv.Spec.ClaimRef; claim != nil && policy == v1.PersistentVolumeReclaimRetain {
+				_, err := a.Client.GetClient().CoreV1().PersistentVolumeClaims(claim.Namespace).Get(a.Context, claim.Name, metav1.GetOptions{})
+				if apierrors.IsNotFound(err) {
+					failures = append(failures, common.Failure{
+						Text: fmt.Sprintf("PersistentVolume %s is in phase %s with reclaim policy %s but its claim %s/%s does not exist; the volume is orphaned",
+							pv.Name, pv.Status.Phase, policy, claim.Namespace, claim.Name),
+						Sensitive: sensitive,
+						Severity:  common.SeverityLow,
+					})
+				}
+			}
+		}
+
+		if name := pv.Spec.StorageClassName; name != "" {
+			exists, ok := storageClassExists[name]
+			if !ok {
+				_, err := a.Client.GetClient().StorageV1().StorageClasses().Get(a.Context, name, metav1.GetOptions{})
+				exists = !apierrors.IsNotFound(err)
+				storageClassExists[name] = exists
+			}
+			if !exists {
+				failures = append(failures, common.Failure{
+					Text: fmt.Sprintf("PersistentVolume %s (phase %s, reclaim policy %s) uses the StorageClass %s, which does not exist",
+						pv.Name, pv.Status.Phase, policy, name),
+					Sensitive: sensitive,
+					FieldPath: "spec.storageClassName",
+					Severity:  common.SeverityMedium,
+				})
+			}
+		}
+
+		if len(failures) > 0 {
+			preAnalysis[pv.Name] = common.PreAnalysis{
+				FailureDetails: failures,
+			}
+			snapshot.Set(pv.Name, "", float64(len(failures)))
+		}
+	}
+
+	for key, value := range preAnalysis {
+		a.Results = append(a.Results, common.Result{
+			Kind:  kind,
+			Name:  key,
+			Error: value.FailureDetails,
+		})
+	}
+
+	snapshot.Apply()
+	return a.Results, nil
+}
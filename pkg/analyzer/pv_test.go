@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPersistentVolumeAnalyzer(t *testing.T) {
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				&storagev1.StorageClass{
+					ObjectMeta: metav1.ObjectMeta{Name: "standard"},
+				},
+				&v1.PersistentVolumeClaim{
+					ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "default"},
+				},
+				&v1.PersistentVolume{
+					// Bound to an existing claim.
+					ObjectMeta: metav1.ObjectMeta{Name: "pv-bound"},
+					Spec: v1.PersistentVolumeSpec{
+						StorageClassName:              "standard",
+						PersistentVolumeReclaimPolicy: v1.PersistentVolumeReclaimRetain,
+						ClaimRef:                      &v1.ObjectReference{Namespace: "default", Name: "data"},
+					},
+					Status: v1.PersistentVolumeStatus{Phase: v1.VolumeBound},
+				},
+				&v1.PersistentVolume{
+					ObjectMeta: metav1.ObjectMeta{Name: "pv-released"},
+					Spec: v1.PersistentVolumeSpec{
+						StorageClassName:              "standard",
+						PersistentVolumeReclaimPolicy: v1.PersistentVolumeReclaimRetain,
+						ClaimRef:                      &v1.ObjectReference{Namespace: "default", Name: "old-data"},
+					},
+					Status: v1.PersistentVolumeStatus{Phase: v1.VolumeReleased},
+				},
+				&v1.PersistentVolume{
+					ObjectMeta: metav1.ObjectMeta{Name: "pv-failed"},
+					Spec: v1.PersistentVolumeSpec{
+						PersistentVolumeReclaimPolicy: v1.PersistentVolumeReclaimDelete,
+					},
+					Status: v1.PersistentVolumeStatus{Phase: v1.VolumeFailed, Message: "error deleting volume"},
+				},
+				&v1.PersistentVolume{
+					ObjectMeta: metav1.ObjectMeta{Name: "pv-orphaned"},
+					Spec: v1.PersistentVolumeSpec{
+						StorageClassName:              "legacy",
+						PersistentVolumeReclaimPolicy: v1.PersistentVolumeReclaimRetain,
+						ClaimRef:                      &v1.ObjectReference{Namespace: "default", Name: "gone"},
+					},
+					Status: v1.PersistentVolumeStatus{Phase: v1.VolumeBound},
+				},
+			),
+		},
+		Context: context.Background(),
+	}
+
+	results, err := PersistentVolumeAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	var names []string
+	for _, result := range results {
+		names = append(names, result.Name)
+	}
+	require.Equal(t, []string{"pv-failed", "pv-orphaned", "pv-released"}, names)
+
+	require.Equal(t, "PersistentVolume pv-failed is in phase Failed with reclaim policy Delete: error deleting volume", results[0].Error[0].Text)
+
+	require.Len(t, results[1].Error, 2)
+	require.Equal(t, "PersistentVolume pv-orphaned is in phase Bound with reclaim policy Retain but its claim default/gone does not exist; the volume is orphaned", results[1].Error[0].Text)
+	require.Equal(t, "PersistentVolume pv-orphaned (phase Bound, reclaim policy Retain) uses the StorageClass legacy, which does not exist", results[1].Error[1].Text)
+	require.Equal(t, "spec.storageClassName", results[1].Error[1].FieldPath)
+
+	require.Contains(t, results[2].Error[0].Text, "PersistentVolume pv-released is in phase Released with reclaim policy Retain: its claim default/old-data was deleted")
+}
@@ -28,12 +28,10 @@ func (PvcAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 
 	kind := "PersistentVolumeClaim"
 
-	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
-		"analyzer_name": kind,
-	})
+	snapshot := newAnalyzerErrorsSnapshot(kind)
 
 	// search all namespaces for pods that are not running
-	list, err := a.Client.GetClient().CoreV1().PersistentVolumeClaims(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector})
+	list, err := a.Client.GetClient().CoreV1().PersistentVolumeClaims(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor("PersistentVolumeClaim", a.FieldSelector)})
 	if err != nil {
 		return nil, err
 	}
@@ -41,6 +39,9 @@ func (PvcAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 	var preAnalysis = map[string]common.PreAnalysis{}
 
 	for _, pvc := range list.Items {
+		if util.CreatedWithin(pvc.ObjectMeta, a.MinAge) {
+			continue
+		}
 		var failures []common.Failure
 
 		// Check for empty rs
@@ -63,7 +64,7 @@ func (PvcAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				PersistentVolumeClaim: pvc,
 				FailureDetails:        failures,
 			}
-			AnalyzerErrorsMetric.WithLabelValues(kind, pvc.Name, pvc.Namespace).Set(float64(len(failures)))
+			snapshot.Set(pvc.Name, pvc.Namespace, float64(len(failures)))
 		}
 	}
 
@@ -81,5 +82,6 @@ func (PvcAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		a.Results = append(a.Results, currentAnalysis)
 	}
 
+	snapshot.Apply()
 	return a.Results, nil
 }
@@ -0,0 +1,231 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Quota resources are reported once this share of their hard limit is used,
+// unless common.Analyzer.ResourceQuotaThreshold is set.
+const defaultResourceQuotaThreshold = 0.9
+
+// ResourceQuotaAnalyzer reports ResourceQuotas whose usage of a resource is
+// at or near its hard limit, and those that block the missing pods of a
+// ReplicaSet because one more pod doesn't fit.
+type ResourceQuotaAnalyzer struct{}
+
+func (ResourceQuotaAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "ResourceQuota"
+
+	snapshot := newAnalyzerErrorsSnapshot(kind)
+
+	list, err := a.Client.GetClient().CoreV1().ResourceQuotas(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor(kind, a.FieldSelector)})
+	if err != nil {
+		return nil, err
+	}
+
+	threshold := a.ResourceQuotaThreshold
+	if threshold <= 0 {
+		threshold = defaultResourceQuotaThreshold
+	}
+
+	// ReplicaSets are only listed once per namespace with a quota.
+	replicaSets := map[string][]scaledReplicaSet{}
+
+	for _, quota := range list.Items {
+		if util.CreatedWithin(quota.ObjectMeta, a.MinAge) {
+			continue
+		}
+
+		var failures []common.Failure
+		for _, name := range sortedResourceNames(quota.Status.Hard) {
+			hard := quota.Status.Hard[name]
+			used := quota.Status.Used[name]
+			// A hard limit of zero forbids the resource on purpose.
+			if hard.IsZero() || used.AsApproximateFloat64() < threshold*hard.AsApproximateFloat64() {
+				continue
+			}
+			severity := common.SeverityMedium
+			text := fmt.Sprintf("ResourceQuota %s/%s has used %s of %s %s (%.0f%%)", quota.Namespace, quota.Name, used.String(), hard.String(), name,
+				100*used.AsApproximateFloat64()/hard.AsApproximateFloat64())
+			if used.Cmp(hard) >= 0 {
+				severity = common.SeverityHigh
+				text += "; new objects requesting it are rejected"
+			}
+			failures = append(failures, common.Failure{
+				Text:      text,
+				Sensitive: quotaSensitive(quota),
+				FieldPath: fmt.Sprintf("spec.hard[%s]", name),
+				Severity:  severity,
+			})
+		}
+
+		// Scoped quotas only apply to some pods, which the ReplicaSet analyzer explains.
+		if len(quota.Spec.Scopes) == 0 && quota.Spec.ScopeSelector == nil {
+			if _, ok := replicaSets[quota.Namespace]; !ok {
+				replicaSets[quota.Namespace] = scaledReplicaSets(a, quota.Namespace)
+			}
+			failures = append(failures, analyzeBlockedReplicaSets(quota, replicaSets[quota.Namespace])...)
+		}
+
+		if len(failures) > 0 {
+			snapshot.Set(quota.Name, quota.Namespace, float64(len(failures)))
+			a.Results = append(a.Results, common.Result{
+				Kind:  kind,
+				Name:  fmt.Sprintf("%s/%s", quota.Namespace, quota.Name),
+				Error: failures,
+			})
+		}
+	}
+
+	snapshot.Apply()
+	return a.Results, nil
+}
+
+// scaledReplicaSet is a ReplicaSet missing pods, with the quota usage of one
+// of its pods.
+type scaledReplicaSet struct {
+	name  string
+	usage v1.ResourceList
+}
+
+// scaledReplicaSets returns the ReplicaSets of the namespace with fewer pods
+// than desired. None are returned when they can't be listed.
+func scaledReplicaSets(a common.Analyzer, namespace string) []scaledReplicaSet {
+	list, err := a.Client.GetClient().AppsV1().ReplicaSets(namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil
+	}
+	var replicaSets []scaledReplicaSet
+	for _, rs := range list.Items {
+		if rs.Spec.Replicas == nil || *rs.Spec.Replicas <= rs.Status.Replicas {
+			continue
+		}
+		replicaSets = append(replicaSets, scaledReplicaSet{name: rs.Name, usage: podQuotaUsage(rs.Spec.Template.Spec)})
+	}
+	return replicaSets
+}
+
+// analyzeBlockedReplicaSets reports the ReplicaSets whose next pod would take
+// a resource of the quota over its hard limit.
+func analyzeBlockedReplicaSets(quota v1.ResourceQuota, replicaSets []scaledReplicaSet) []common.Failure {
+	var failures []common.Failure
+	for _, rs := range replicaSets {
+		for _, name := range sortedResourceNames(quota.Status.Hard) {
+			needed, ok := rs.usage[name]
+			if !ok {
+				continue
+			}
+			hard := quota.Status.Hard[name]
+			remaining := hard.DeepCopy()
+			remaining.Sub(quota.Status.Used[name])
+			if needed.Cmp(remaining) <= 0 {
+				continue
+			}
+			if remaining.Sign() < 0 {
+				remaining = resource.Quantity{}
+			}
+			failures = append(failures, common.Failure{
+				Text: fmt.Sprintf("ResourceQuota %s/%s blocks the missing pods of ReplicaSet %s: each pod needs %s %s but only %s of %s remain",
+					quota.Namespace, quota.Name, rs.name, needed.String(), name, remaining.String(), hard.String()),
+				Sensitive: append(quotaSensitive(quota), common.Sensitive{
+					Unmasked: rs.name,
+					Masked:   util.MaskString(rs.name),
+				}),
+				FieldPath: fmt.Sprintf("spec.hard[%s]", name),
+				Severity:  common.SeverityHigh,
+			})
+			// One exhausted resource is enough to explain the blocked pods.
+			break
+		}
+	}
+	return failures
+}
+
+// podQuotaUsage returns what a pod with the spec counts against quotas: the
+// pod itself, and its requests and limits as the larger of the sum of its
+// containers and any of its init containers.
+func podQuotaUsage(spec v1.PodSpec) v1.ResourceList {
+	usage := v1.ResourceList{
+		v1.ResourcePods:               resource.MustParse("1"),
+		v1.ResourceName("count/pods"): resource.MustParse("1"),
+	}
+	add := func(prefix string, resources v1.ResourceList, legacy bool) {
+		for name, quantity := range resources {
+			keys := []v1.ResourceName{v1.ResourceName(prefix + string(name))}
+			if legacy {
+				keys = append(keys, name)
+			}
+			for _, key := range keys {
+				total := usage[key]
+				total.Add(quantity)
+				usage[key] = total
+			}
+		}
+	}
+	for _, container := range spec.Containers {
+		add("requests.", container.Resources.Requests, true)
+		add("limits.", container.Resources.Limits, false)
+	}
+
+	for _, container := range spec.InitContainers {
+		init := v1.ResourceList{}
+		for name, quantity := range container.Resources.Requests {
+			init[v1.ResourceName("requests."+string(name))] = quantity
+			init[name] = quantity
+		}
+		for name, quantity := range container.Resources.Limits {
+			init[v1.ResourceName("limits."+string(name))] = quantity
+		}
+		for name, quantity := range init {
+			if quantity.Cmp(usage[name]) > 0 {
+				usage[name] = quantity
+			}
+		}
+	}
+	return usage
+}
+
+func sortedResourceNames(resources v1.ResourceList) []v1.ResourceName {
+	names := make([]v1.ResourceName, 0, len(resources))
+	for name := range resources {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return names[i] < names[j]
+	})
+	return names
+}
+
+func quotaSensitive(quota v1.ResourceQuota) []common.Sensitive {
+	return []common.Sensitive{
+		{
+			Unmasked: quota.Namespace,
+			Masked:   util.MaskString(quota.Namespace),
+		},
+		{
+			Unmasked: quota.Name,
+			Masked:   util.MaskString(quota.Name),
+		},
+	}
+}
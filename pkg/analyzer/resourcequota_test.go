@@ -0,0 +1,167 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func quotaWithUsage(name string, hard v1.ResourceList, used v1.ResourceList) *v1.ResourceQuota {
+	return &v1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Status:     v1.ResourceQuotaStatus{Hard: hard, Used: used},
+	}
+}
+
+func TestResourceQuotaAnalyzer(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold float64
+		objects   []runtime.Object
+		expected  []string
+	}{
+		{
+			name: "usage below the threshold",
+			objects: []runtime.Object{
+				quotaWithUsage("compute",
+					v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse("10")},
+					v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse("8")}),
+			},
+		},
+		{
+			name: "usage near and at the hard limit",
+			objects: []runtime.Object{
+				quotaWithUsage("compute",
+					v1.ResourceList{
+						v1.ResourceRequestsCPU:    resource.MustParse("10"),
+						v1.ResourceRequestsMemory: resource.MustParse("4Gi"),
+						// Zero forbids the resource and is not reported.
+						v1.ResourceServicesLoadBalancers: resource.MustParse("0"),
+					},
+					v1.ResourceList{
+						v1.ResourceRequestsCPU:    resource.MustParse("9500m"),
+						v1.ResourceRequestsMemory: resource.MustParse("4Gi"),
+					}),
+			},
+			expected: []string{
+				"ResourceQuota default/compute has used 9500m of 10 requests.cpu (95%)",
+				"ResourceQuota default/compute has used 4Gi of 4Gi requests.memory (100%); new objects requesting it are rejected",
+			},
+		},
+		{
+			name:      "configured threshold",
+			threshold: 0.5,
+			objects: []runtime.Object{
+				quotaWithUsage("pods",
+					v1.ResourceList{v1.ResourcePods: resource.MustParse("10")},
+					v1.ResourceList{v1.ResourcePods: resource.MustParse("6")}),
+			},
+			expected: []string{"ResourceQuota default/pods has used 6 of 10 pods (60%)"},
+		},
+		{
+			name: "quota blocking the missing pods of a ReplicaSet",
+			objects: []runtime.Object{
+				quotaWithUsage("compute",
+					v1.ResourceList{v1.ResourceLimitsMemory: resource.MustParse("4Gi")},
+					v1.ResourceList{v1.ResourceLimitsMemory: resource.MustParse("3Gi")}),
+				&appsv1.ReplicaSet{
+					ObjectMeta: metav1.ObjectMeta{Name: "web-5d4f", Namespace: "default"},
+					Spec: appsv1.ReplicaSetSpec{
+						Replicas: ptr.To(int32(3)),
+						Template: v1.PodTemplateSpec{
+							Spec: v1.PodSpec{
+								Containers: []v1.Container{
+									{Name: "app", Resources: v1.ResourceRequirements{Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("1536Mi")}}},
+								},
+							},
+						},
+					},
+					Status: appsv1.ReplicaSetStatus{Replicas: 2},
+				},
+				&appsv1.ReplicaSet{
+					// Scaled: no missing pods.
+					ObjectMeta: metav1.ObjectMeta{Name: "api-7c9b", Namespace: "default"},
+					Spec: appsv1.ReplicaSetSpec{
+						Replicas: ptr.To(int32(1)),
+						Template: v1.PodTemplateSpec{
+							Spec: v1.PodSpec{
+								Containers: []v1.Container{
+									{Name: "app", Resources: v1.ResourceRequirements{Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("2Gi")}}},
+								},
+							},
+						},
+					},
+					Status: appsv1.ReplicaSetStatus{Replicas: 1},
+				},
+			},
+			expected: []string{"ResourceQuota default/compute blocks the missing pods of ReplicaSet web-5d4f: each pod needs 1536Mi limits.memory but only 1Gi of 4Gi remain"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := common.Analyzer{
+				Client: &kubernetes.Client{
+					Client: fake.NewSimpleClientset(tt.objects...),
+				},
+				Context:                context.Background(),
+				Namespace:              "default",
+				ResourceQuotaThreshold: tt.threshold,
+			}
+
+			results, err := ResourceQuotaAnalyzer{}.Analyze(config)
+			require.NoError(t, err)
+
+			var texts []string
+			for _, result := range results {
+				require.Equal(t, "ResourceQuota", result.Kind)
+				for _, failure := range result.Error {
+					texts = append(texts, failure.Text)
+				}
+			}
+			require.Equal(t, tt.expected, texts)
+		})
+	}
+}
+
+func TestPodQuotaUsage(t *testing.T) {
+	usage := podQuotaUsage(v1.PodSpec{
+		InitContainers: []v1.Container{
+			{Name: "migrate", Resources: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}}},
+		},
+		Containers: []v1.Container{
+			{Name: "app", Resources: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")}}},
+			{Name: "sidecar", Resources: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("250m")}}},
+		},
+	})
+
+	cpu := usage[v1.ResourceRequestsCPU]
+	require.Equal(t, "2", cpu.String())
+	legacy := usage[v1.ResourceCPU]
+	require.Equal(t, "2", legacy.String())
+	pods := usage[v1.ResourcePods]
+	require.Equal(t, "1", pods.String())
+}
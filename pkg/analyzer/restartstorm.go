@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// a pod is churning when one of its containers restarted more than this
+	restartStormRestartThreshold = 5
+	// ... and last terminated within this window (or is in CrashLoopBackOff)
+	restartStormWindow = time.Hour
+	// a namespace is reported once at least this many pods are churning
+	restartStormMinPods = 3
+)
+
+// RestartStormAnalyzer reports namespaces where many pods are restarting at
+// once, which the per-pod findings of the PodAnalyzer don't make obvious.
+type RestartStormAnalyzer struct{}
+
+func (RestartStormAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "Namespace"
+	analyzerName := "RestartStorm"
+
+	snapshot := newAnalyzerErrorsSnapshot(analyzerName)
+
+	list, err := a.Client.GetClient().CoreV1().Pods(a.Namespace).List(a.Context, metav1.ListOptions{
+		LabelSelector: a.LabelSelector,
+		FieldSelector: util.FieldSelectorFor("Pod", a.FieldSelector),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	churning := map[string]int{}
+	restarts := map[string]int32{}
+	crashLooping := map[string]int{}
+	for _, pod := range list.Items {
+		podRestarts, crashLoop := podChurn(pod, time.Now())
+		if podRestarts <= restartStormRestartThreshold {
+			continue
+		}
+		churning[pod.Namespace]++
+		restarts[pod.Namespace] += podRestarts
+		if crashLoop {
+			crashLooping[pod.Namespace]++
+		}
+	}
+
+	var namespaces []string
+	for namespace, count := range churning {
+		if count >= restartStormMinPods {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	sort.Strings(namespaces)
+
+	for _, namespace := range namespaces {
+		failures := []common.Failure{
+			{
+				Text: fmt.Sprintf("namespace %s has %d pods restarting more than %d times in the last %s (%d restarts in total, %d pods in CrashLoopBackOff)",
+					namespace, churning[namespace], restartStormRestartThreshold, restartStormWindow, restarts[namespace], crashLooping[namespace]),
+				Sensitive: []common.Sensitive{},
+				Severity:  common.SeverityHigh,
+			},
+		}
+		snapshot.Set(namespace, namespace, float64(churning[namespace]))
+		a.Results = append(a.Results, common.Result{
+			Kind:  kind,
+			Name:  namespace,
+			Error: failures,
+		})
+	}
+
+	snapshot.Apply()
+	return a.Results, nil
+}
+
+// podChurn returns the highest restart count of the pod's containers that
+// restarted recently, and whether any of them is in CrashLoopBackOff.
+func podChurn(pod v1.Pod, now time.Time) (int32, bool) {
+	var restarts int32
+	var crashLoop bool
+	for _, status := range pod.Status.ContainerStatuses {
+		isCrashLooping := status.State.Waiting != nil && status.State.Waiting.Reason == "CrashLoopBackOff"
+		recent := status.LastTerminationState.Terminated != nil &&
+			now.Sub(status.LastTerminationState.Terminated.FinishedAt.Time) < restartStormWindow
+		if !isCrashLooping && !recent {
+			continue
+		}
+		crashLoop = crashLoop || isCrashLooping
+		if status.RestartCount > restarts {
+			restarts = status.RestartCount
+		}
+	}
+	return restarts, crashLoop
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRestartStormAnalyzer(t *testing.T) {
+	crashLooping := func(name string, namespace string, restarts int32) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Status: v1.PodStatus{
+				ContainerStatuses: []v1.ContainerStatus{
+					{
+						Name:         "app",
+						RestartCount: restarts,
+						State: v1.ContainerState{
+							Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+						},
+					},
+				},
+			},
+		}
+	}
+	restartedLongAgo := func(name string, namespace string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Status: v1.PodStatus{
+				ContainerStatuses: []v1.ContainerStatus{
+					{
+						Name:         "app",
+						RestartCount: 40,
+						State: v1.ContainerState{
+							Running: &v1.ContainerStateRunning{},
+						},
+						LastTerminationState: v1.ContainerState{
+							Terminated: &v1.ContainerStateTerminated{
+								FinishedAt: metav1.NewTime(time.Now().Add(-24 * time.Hour)),
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	var objects []runtime.Object
+	// "flapping" has 3 churning pods and is reported.
+	for i := 0; i < 3; i++ {
+		objects = append(objects, crashLooping(fmt.Sprintf("flapping-%d", i), "flapping", 10))
+	}
+	// "quiet" has pods below the restart threshold or that restarted outside the window.
+	objects = append(objects,
+		crashLooping("low-restarts-0", "quiet", 2),
+		crashLooping("low-restarts-1", "quiet", 3),
+		restartedLongAgo("old-restarts-0", "quiet"),
+		restartedLongAgo("old-restarts-1", "quiet"),
+		crashLooping("crashing", "quiet", 9),
+	)
+
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(objects...),
+		},
+		Context: context.Background(),
+	}
+
+	results, err := RestartStormAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "Namespace", results[0].Kind)
+	require.Equal(t, "flapping", results[0].Name)
+	require.Equal(t, "namespace flapping has 3 pods restarting more than 5 times in the last 1h0m0s (30 restarts in total, 3 pods in CrashLoopBackOff)", results[0].Error[0].Text)
+}
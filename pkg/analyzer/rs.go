@@ -15,24 +15,31 @@ package analyzer
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
+	"strings"
 
 	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// exceededQuotaPattern extracts the quota name from the error returned when
+// a ResourceQuota rejects a pod.
+var exceededQuotaPattern = regexp.MustCompile(`exceeded quota: ([^,]+)`)
+
 type ReplicaSetAnalyzer struct{}
 
 func (ReplicaSetAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 
 	kind := "ReplicaSet"
 
-	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
-		"analyzer_name": kind,
-	})
+	snapshot := newAnalyzerErrorsSnapshot(kind)
 
 	// search all namespaces for pods that are not running
-	list, err := a.Client.GetClient().AppsV1().ReplicaSets(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector})
+	list, err := a.Client.GetClient().AppsV1().ReplicaSets(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor("ReplicaSet", a.FieldSelector)})
 	if err != nil {
 		return nil, err
 	}
@@ -40,6 +47,9 @@ func (ReplicaSetAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 	var preAnalysis = map[string]common.PreAnalysis{}
 
 	for _, rs := range list.Items {
+		if util.CreatedWithin(rs.ObjectMeta, a.MinAge) {
+			continue
+		}
 		var failures []common.Failure
 
 		// Check for empty rs
@@ -52,7 +62,9 @@ func (ReplicaSetAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 						Text:      rsStatus.Message,
 						Sensitive: []common.Sensitive{},
 					})
-
+					if failure, ok := analyzeScopedQuota(a, rs, rsStatus.Message); ok {
+						failures = append(failures, failure)
+					}
 				}
 			}
 		}
@@ -61,7 +73,7 @@ func (ReplicaSetAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				ReplicaSet:     rs,
 				FailureDetails: failures,
 			}
-			AnalyzerErrorsMetric.WithLabelValues(kind, rs.Name, rs.Namespace).Set(float64(len(failures)))
+			snapshot.Set(rs.Name, rs.Namespace, float64(len(failures)))
 		}
 	}
 
@@ -78,5 +90,125 @@ func (ReplicaSetAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		}
 		a.Results = append(a.Results, currentAnalysis)
 	}
+	snapshot.Apply()
 	return a.Results, nil
 }
+
+// analyzeScopedQuota explains a pod creation rejected by a ResourceQuota that
+// only applies to some pods, through scopes or a scopeSelector. The rejection
+// alone doesn't say why this quota applies to the ReplicaSet's pods.
+func analyzeScopedQuota(a common.Analyzer, rs appsv1.ReplicaSet, message string) (common.Failure, bool) {
+	match := exceededQuotaPattern.FindStringSubmatch(message)
+	if match == nil {
+		return common.Failure{}, false
+	}
+	quota, err := a.Client.GetClient().CoreV1().ResourceQuotas(rs.Namespace).Get(a.Context, match[1], metav1.GetOptions{})
+	if err != nil {
+		return common.Failure{}, false
+	}
+
+	var scopes []string
+	for _, scope := range quota.Spec.Scopes {
+		if !podMatchesQuotaScope(rs.Spec.Template.Spec, v1.ScopedResourceSelectorRequirement{ScopeName: scope, Operator: v1.ScopeSelectorOpExists}) {
+			return common.Failure{}, false
+		}
+		scopes = append(scopes, string(scope))
+	}
+	if quota.Spec.ScopeSelector != nil {
+		for _, requirement := range quota.Spec.ScopeSelector.MatchExpressions {
+			if !podMatchesQuotaScope(rs.Spec.Template.Spec, requirement) {
+				return common.Failure{}, false
+			}
+			scope := fmt.Sprintf("%s %s", requirement.ScopeName, requirement.Operator)
+			if len(requirement.Values) > 0 {
+				scope += fmt.Sprintf(" [%s]", strings.Join(requirement.Values, ", "))
+			}
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return common.Failure{}, false
+	}
+
+	text := fmt.Sprintf("ReplicaSet %s cannot create pods because they match the scope of ResourceQuota %s (%s), which is exhausted", rs.Name, quota.Name, strings.Join(scopes, "; "))
+	if rs.Spec.Template.Spec.PriorityClassName != "" {
+		text += fmt.Sprintf("; the pods use PriorityClass %s", rs.Spec.Template.Spec.PriorityClassName)
+	}
+	return common.Failure{
+		Text: text,
+		Sensitive: []common.Sensitive{
+			{
+				Unmasked: rs.Name,
+				Masked:   util.MaskString(rs.Name),
+			},
+			{
+				Unmasked: quota.Name,
+				Masked:   util.MaskString(quota.Name),
+			},
+		},
+	}, true
+}
+
+// podMatchesQuotaScope evaluates a quota scope against a pod spec, as the
+// quota admission controller does.
+func podMatchesQuotaScope(spec v1.PodSpec, requirement v1.ScopedResourceSelectorRequirement) bool {
+	var matches bool
+	switch requirement.ScopeName {
+	case v1.ResourceQuotaScopeTerminating:
+		matches = spec.ActiveDeadlineSeconds != nil && *spec.ActiveDeadlineSeconds >= 0
+	case v1.ResourceQuotaScopeNotTerminating:
+		matches = spec.ActiveDeadlineSeconds == nil || *spec.ActiveDeadlineSeconds < 0
+	case v1.ResourceQuotaScopeBestEffort:
+		matches = isBestEffort(spec)
+	case v1.ResourceQuotaScopeNotBestEffort:
+		matches = !isBestEffort(spec)
+	case v1.ResourceQuotaScopePriorityClass:
+		switch requirement.Operator {
+		case v1.ScopeSelectorOpIn:
+			return slices.Contains(requirement.Values, spec.PriorityClassName)
+		case v1.ScopeSelectorOpNotIn:
+			return !slices.Contains(requirement.Values, spec.PriorityClassName)
+		}
+		matches = spec.PriorityClassName != ""
+	case v1.ResourceQuotaScopeCrossNamespacePodAffinity:
+		matches = hasCrossNamespacePodAffinity(spec)
+	}
+	if requirement.Operator == v1.ScopeSelectorOpDoesNotExist {
+		return !matches
+	}
+	return matches
+}
+
+func isBestEffort(spec v1.PodSpec) bool {
+	for _, container := range append(append([]v1.Container{}, spec.InitContainers...), spec.Containers...) {
+		if len(container.Resources.Requests) > 0 || len(container.Resources.Limits) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func hasCrossNamespacePodAffinity(spec v1.PodSpec) bool {
+	if spec.Affinity == nil {
+		return false
+	}
+	var terms []v1.PodAffinityTerm
+	if affinity := spec.Affinity.PodAffinity; affinity != nil {
+		terms = append(terms, affinity.RequiredDuringSchedulingIgnoredDuringExecution...)
+		for _, weighted := range affinity.PreferredDuringSchedulingIgnoredDuringExecution {
+			terms = append(terms, weighted.PodAffinityTerm)
+		}
+	}
+	if antiAffinity := spec.Affinity.PodAntiAffinity; antiAffinity != nil {
+		terms = append(terms, antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution...)
+		for _, weighted := range antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+			terms = append(terms, weighted.PodAffinityTerm)
+		}
+	}
+	for _, term := range terms {
+		if len(term.Namespaces) > 0 || term.NamespaceSelector != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -15,6 +15,7 @@ package analyzer
 
 import (
 	"context"
+	"fmt"
 	"sort"
 	"testing"
 
@@ -22,6 +23,8 @@ import (
 	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
 )
@@ -199,3 +202,111 @@ func TestReplicaSetAnalyzerLabelSelectorFiltering(t *testing.T) {
 	require.Equal(t, 1, len(results))
 	require.Equal(t, "default/ReplicaSet1", results[0].Name)
 }
+
+func TestReplicaSetAnalyzerScopedQuota(t *testing.T) {
+	rejected := func(name string, quota string, priorityClass string) *appsv1.ReplicaSet {
+		return &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: appsv1.ReplicaSetSpec{
+				Template: v1.PodTemplateSpec{
+					Spec: v1.PodSpec{PriorityClassName: priorityClass},
+				},
+			},
+			Status: appsv1.ReplicaSetStatus{
+				Conditions: []appsv1.ReplicaSetCondition{
+					{
+						Type:   appsv1.ReplicaSetReplicaFailure,
+						Reason: "FailedCreate",
+						Message: fmt.Sprintf(`pods "%s-x2k5p" is forbidden: exceeded quota: %s, requested: pods=1, used: pods=2, limited: pods=2`,
+							name, quota),
+					},
+				},
+			},
+		}
+	}
+	quota := func(name string, spec v1.ResourceQuotaSpec) *v1.ResourceQuota {
+		return &v1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       spec,
+		}
+	}
+
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				quota("high-priority", v1.ResourceQuotaSpec{
+					ScopeSelector: &v1.ScopeSelector{
+						MatchExpressions: []v1.ScopedResourceSelectorRequirement{
+							{ScopeName: v1.ResourceQuotaScopePriorityClass, Operator: v1.ScopeSelectorOpIn, Values: []string{"high", "critical"}},
+						},
+					},
+				}),
+				quota("best-effort", v1.ResourceQuotaSpec{
+					Scopes: []v1.ResourceQuotaScope{v1.ResourceQuotaScopeBestEffort},
+				}),
+				quota("namespace", v1.ResourceQuotaSpec{}),
+				rejected("api", "high-priority", "high"),
+				rejected("worker", "best-effort", ""),
+				rejected("web", "namespace", ""),
+			),
+		},
+		Context:   context.Background(),
+		Namespace: "default",
+	}
+
+	results, err := ReplicaSetAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+
+	texts := map[string][]string{}
+	for _, result := range results {
+		for _, failure := range result.Error {
+			texts[result.Name] = append(texts[result.Name], failure.Text)
+		}
+	}
+	require.Equal(t, "ReplicaSet api cannot create pods because they match the scope of ResourceQuota high-priority (PriorityClass In [high, critical]), which is exhausted; the pods use PriorityClass high", texts["default/api"][1])
+	require.Equal(t, "ReplicaSet worker cannot create pods because they match the scope of ResourceQuota best-effort (BestEffort), which is exhausted", texts["default/worker"][1])
+	// A quota without scopes applies to every pod, the rejection says it all.
+	require.Len(t, texts["default/web"], 1)
+}
+
+func TestPodMatchesQuotaScope(t *testing.T) {
+	deadline := int64(60)
+	tests := []struct {
+		name        string
+		spec        v1.PodSpec
+		requirement v1.ScopedResourceSelectorRequirement
+		matches     bool
+	}{
+		{
+			name:        "priority class not in values",
+			spec:        v1.PodSpec{PriorityClassName: "low"},
+			requirement: v1.ScopedResourceSelectorRequirement{ScopeName: v1.ResourceQuotaScopePriorityClass, Operator: v1.ScopeSelectorOpIn, Values: []string{"high"}},
+			matches:     false,
+		},
+		{
+			name:        "priority class exists",
+			spec:        v1.PodSpec{PriorityClassName: "low"},
+			requirement: v1.ScopedResourceSelectorRequirement{ScopeName: v1.ResourceQuotaScopePriorityClass, Operator: v1.ScopeSelectorOpExists},
+			matches:     true,
+		},
+		{
+			name:        "terminating",
+			spec:        v1.PodSpec{ActiveDeadlineSeconds: &deadline},
+			requirement: v1.ScopedResourceSelectorRequirement{ScopeName: v1.ResourceQuotaScopeTerminating, Operator: v1.ScopeSelectorOpExists},
+			matches:     true,
+		},
+		{
+			name: "not best effort",
+			spec: v1.PodSpec{Containers: []v1.Container{{
+				Resources: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")}},
+			}}},
+			requirement: v1.ScopedResourceSelectorRequirement{ScopeName: v1.ResourceQuotaScopeBestEffort, Operator: v1.ScopeSelectorOpDoesNotExist},
+			matches:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.matches, podMatchesQuotaScope(tt.spec, tt.requirement))
+		})
+	}
+}
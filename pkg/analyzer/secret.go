@@ -0,0 +1,224 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The number of objects named when reporting a missing Secret.
+const maxSecretReferrers = 3
+
+// SecretAnalyzer reports Secrets referenced by Pods and Deployments that
+// don't exist, and Secrets whose data doesn't match their type. The label
+// selector picks the Pods, Deployments and Secrets that are analyzed.
+type SecretAnalyzer struct{}
+
+func (SecretAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "Secret"
+
+	snapshot := newAnalyzerErrorsSnapshot(kind)
+
+	secretList, err := a.Client.GetClient().CoreV1().Secrets(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor(kind, a.FieldSelector)})
+	if err != nil {
+		return nil, err
+	}
+
+	var preAnalysis = map[string]common.PreAnalysis{}
+
+	for _, secret := range secretList.Items {
+		if util.CreatedWithin(secret.ObjectMeta, a.MinAge) {
+			continue
+		}
+		var texts []string
+		switch secret.Type {
+		case v1.SecretTypeDockerConfigJson:
+			if err := validateDockerConfigJSON(secret.Data[v1.DockerConfigJsonKey]); err != nil {
+				texts = append(texts, fmt.Sprintf("Secret %s of type %s has an invalid %s: %s", secret.Name, secret.Type, v1.DockerConfigJsonKey, err))
+			}
+		case v1.SecretTypeTLS:
+			for _, key := range []string{v1.TLSCertKey, v1.TLSPrivateKeyKey} {
+				if len(secret.Data[key]) == 0 {
+					texts = append(texts, fmt.Sprintf("Secret %s of type %s is missing %s", secret.Name, secret.Type, key))
+				}
+			}
+		}
+
+		var failures []common.Failure
+		for _, text := range texts {
+			failures = append(failures, common.Failure{
+				Text:      text,
+				Sensitive: secretSensitive(secret.Namespace, secret.Name),
+				Severity:  common.SeverityHigh,
+			})
+		}
+		if len(failures) > 0 {
+			preAnalysis[fmt.Sprintf("%s/%s", secret.Namespace, secret.Name)] = common.PreAnalysis{
+				FailureDetails: failures,
+			}
+			snapshot.Set(secret.Name, secret.Namespace, float64(len(failures)))
+		}
+	}
+
+	referrers, err := secretReferrers(a)
+	if err != nil {
+		return nil, err
+	}
+	for key, objects := range referrers {
+		namespace, name, _ := strings.Cut(key, "/")
+		_, err := a.Client.GetClient().CoreV1().Secrets(namespace).Get(a.Context, name, metav1.GetOptions{})
+		if !apierrors.IsNotFound(err) {
+			continue
+		}
+
+		sort.Strings(objects)
+		referencedBy := strings.Join(objects, ", ")
+		if len(objects) > maxSecretReferrers {
+			referencedBy = fmt.Sprintf("%s and %d more", strings.Join(objects[:maxSecretReferrers], ", "), len(objects)-maxSecretReferrers)
+		}
+		preAnalysis[key] = common.PreAnalysis{
+			FailureDetails: []common.Failure{
+				{
+					Text:      fmt.Sprintf("Secret %s does not exist but is referenced by %s", name, referencedBy),
+					Sensitive: secretSensitive(namespace, name),
+					Severity:  common.SeverityHigh,
+				},
+			},
+		}
+		snapshot.Set(name, namespace, 1)
+	}
+
+	for key, value := range preAnalysis {
+		a.Results = append(a.Results, common.Result{
+			Kind:  kind,
+			Name:  key,
+			Error: value.FailureDetails,
+		})
+	}
+
+	snapshot.Apply()
+	return a.Results, nil
+}
+
+// secretReferrers maps the namespace/name of the Secrets required by Pods
+// and Deployments to the objects referencing them. Optional references are
+// left out, as their Secrets may be missing.
+func secretReferrers(a common.Analyzer) (map[string][]string, error) {
+	referrers := map[string][]string{}
+	add := func(namespace string, spec v1.PodSpec, object string) {
+		seen := map[string]bool{}
+		for _, name := range requiredSecretNames(spec) {
+			if !seen[name] {
+				seen[name] = true
+				key := namespace + "/" + name
+				referrers[key] = append(referrers[key], object)
+			}
+		}
+	}
+
+	pods, err := a.Client.GetClient().CoreV1().Pods(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector})
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods.Items {
+		add(pod.Namespace, pod.Spec, "Pod "+pod.Name)
+	}
+
+	deployments, err := a.Client.GetClient().AppsV1().Deployments(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector})
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range deployments.Items {
+		add(deployment.Namespace, deployment.Spec.Template.Spec, "Deployment "+deployment.Name)
+	}
+	return referrers, nil
+}
+
+// requiredSecretNames returns the Secrets a pod can't start without.
+func requiredSecretNames(spec v1.PodSpec) []string {
+	var names []string
+	for _, secret := range spec.ImagePullSecrets {
+		names = append(names, secret.Name)
+	}
+	for _, volume := range spec.Volumes {
+		if volume.Secret != nil && !isOptional(volume.Secret.Optional) {
+			names = append(names, volume.Secret.SecretName)
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.Secret != nil && !isOptional(source.Secret.Optional) {
+					names = append(names, source.Secret.Name)
+				}
+			}
+		}
+	}
+	for _, container := range append(append([]v1.Container{}, spec.InitContainers...), spec.Containers...) {
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef != nil && !isOptional(envFrom.SecretRef.Optional) {
+				names = append(names, envFrom.SecretRef.Name)
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil && !isOptional(env.ValueFrom.SecretKeyRef.Optional) {
+				names = append(names, env.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+	}
+	return names
+}
+
+func isOptional(optional *bool) bool {
+	return optional != nil && *optional
+}
+
+// validateDockerConfigJSON checks that the payload is a JSON object with an
+// auths map, as expected by the kubelet.
+func validateDockerConfigJSON(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("the key is missing or empty")
+	}
+	var config struct {
+		Auths map[string]json.RawMessage `json:"auths"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("it is not valid JSON: %v", err)
+	}
+	if config.Auths == nil {
+		return fmt.Errorf("it has no auths")
+	}
+	return nil
+}
+
+func secretSensitive(namespace string, name string) []common.Sensitive {
+	return []common.Sensitive{
+		{
+			Unmasked: namespace,
+			Masked:   util.MaskString(namespace),
+		},
+		{
+			Unmasked: name,
+			Masked:   util.MaskString(name),
+		},
+	}
+}
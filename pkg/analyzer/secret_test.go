@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSecretAnalyzer(t *testing.T) {
+	optional := true
+
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				&v1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: "default"},
+					Type:       v1.SecretTypeDockerConfigJson,
+					Data:       map[string][]byte{v1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": {}}}`)},
+				},
+				&v1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "broken-registry", Namespace: "default"},
+					Type:       v1.SecretTypeDockerConfigJson,
+					Data:       map[string][]byte{v1.DockerConfigJsonKey: []byte(`{"auths":`)},
+				},
+				&v1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "cert", Namespace: "default"},
+					Type:       v1.SecretTypeTLS,
+					Data:       map[string][]byte{v1.TLSCertKey: []byte("certificate")},
+				},
+				&v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+					Spec: v1.PodSpec{
+						ImagePullSecrets: []v1.LocalObjectReference{{Name: "registry"}},
+						Volumes: []v1.Volume{
+							{Name: "config", VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: "web-config"}}},
+							// Optional Secrets may be missing.
+							{Name: "extra", VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: "extra", Optional: &optional}}},
+						},
+					},
+				},
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+					Spec: appsv1.DeploymentSpec{
+						Template: v1.PodTemplateSpec{
+							Spec: v1.PodSpec{
+								Containers: []v1.Container{
+									{
+										Name: "web",
+										EnvFrom: []v1.EnvFromSource{
+											{SecretRef: &v1.SecretEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: "web-config"}}},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				&v1.Pod{
+					// Filtered out by the namespace.
+					ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "test"},
+					Spec: v1.PodSpec{
+						ImagePullSecrets: []v1.LocalObjectReference{{Name: "missing"}},
+					},
+				},
+			),
+		},
+		Context:   context.Background(),
+		Namespace: "default",
+	}
+
+	results, err := SecretAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	require.Len(t, results, 3)
+
+	require.Equal(t, "default/broken-registry", results[0].Name)
+	require.Len(t, results[0].Error, 1)
+	require.Contains(t, results[0].Error[0].Text, "has an invalid .dockerconfigjson: it is not valid JSON")
+
+	require.Equal(t, "default/cert", results[1].Name)
+	require.Len(t, results[1].Error, 1)
+	require.Equal(t, "Secret cert of type kubernetes.io/tls is missing tls.key", results[1].Error[0].Text)
+
+	require.Equal(t, "default/web-config", results[2].Name)
+	require.Len(t, results[2].Error, 1)
+	require.Equal(t, "Secret web-config does not exist but is referenced by Deployment web, Pod web", results[2].Error[0].Text)
+	require.Equal(t, "web-config", results[2].Error[0].Sensitive[1].Unmasked)
+}
+
+func TestValidateDockerConfigJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		expectedErr string
+	}{
+		{name: "valid", data: `{"auths": {"ghcr.io": {"auth": "dXNlcjpwYXNz"}}}`},
+		{name: "empty", data: "", expectedErr: "the key is missing or empty"},
+		{name: "not json", data: "auths", expectedErr: "it is not valid JSON"},
+		{name: "no auths", data: `{"ghcr.io": {}}`, expectedErr: "it has no auths"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDockerConfigJSON([]byte(tt.data))
+			if tt.expectedErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
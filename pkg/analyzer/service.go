@@ -15,8 +15,11 @@ package analyzer
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/fatih/color"
+	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 
@@ -40,19 +43,22 @@ func (ServiceAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		OpenapiSchema: a.OpenapiSchema,
 	}
 
-	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
-		"analyzer_name": kind,
-	})
+	snapshot := newAnalyzerErrorsSnapshot(kind)
 
 	// search all namespaces for pods that are not running
-	list, err := a.Client.GetClient().CoreV1().Endpoints(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector})
+	list, err := a.Client.GetClient().CoreV1().Endpoints(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor("Endpoints", a.FieldSelector)})
 	if err != nil {
 		return nil, err
 	}
 
 	var preAnalysis = map[string]common.PreAnalysis{}
+	// Deployments are only listed for namespaces with a Service without endpoints.
+	deploymentsByNamespace := map[string][]appsv1.Deployment{}
 
 	for _, ep := range list.Items {
+		if util.CreatedWithin(ep.ObjectMeta, a.MinAge) {
+			continue
+		}
 		var failures []common.Failure
 
 		// Check for empty service
@@ -67,6 +73,35 @@ func (ServiceAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				continue
 			}
 
+			if len(svc.Spec.Selector) > 0 {
+				deployments, ok := deploymentsByNamespace[ep.Namespace]
+				if !ok {
+					// The hint below is best effort, a failed list only skips it.
+					if list, err := a.Client.GetClient().AppsV1().Deployments(ep.Namespace).List(a.Context, metav1.ListOptions{}); err == nil {
+						deployments = list.Items
+					}
+					deploymentsByNamespace[ep.Namespace] = deployments
+				}
+				if deployment, mismatches := selectorNearMiss(svc.Spec.Selector, deployments); deployment != nil {
+					sensitive := []common.Sensitive{
+						{
+							Unmasked: deployment.Name,
+							Masked:   util.MaskString(deployment.Name),
+						},
+					}
+					var texts []string
+					for _, mismatch := range mismatches {
+						texts = append(texts, mismatch.String())
+						sensitive = append(sensitive, mismatch.sensitive()...)
+					}
+					failures = append(failures, common.Failure{
+						Text:          fmt.Sprintf("Service selector does not match the pods of Deployment %s: %s", deployment.Name, strings.Join(texts, "; ")),
+						KubernetesDoc: apiDoc.GetApiDocV2("spec.selector"),
+						Sensitive:     sensitive,
+					})
+				}
+			}
+
 			for k, v := range svc.Spec.Selector {
 				doc := apiDoc.GetApiDocV2("spec.selector")
 
@@ -112,6 +147,9 @@ func (ServiceAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 			}
 		}
 		// fetch event
+		if err := a.Client.Wait(a.Context); err != nil {
+			return nil, err
+		}
 		events, err := a.Client.GetClient().CoreV1().Events(a.Namespace).List(a.Context,
 			metav1.ListOptions{
 				FieldSelector: "involvedObject.name=" + ep.Name,
@@ -132,7 +170,7 @@ func (ServiceAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				Endpoint:       ep,
 				FailureDetails: failures,
 			}
-			AnalyzerErrorsMetric.WithLabelValues(kind, ep.Name, ep.Namespace).Set(float64(len(failures)))
+			snapshot.Set(ep.Name, ep.Namespace, float64(len(failures)))
 		}
 	}
 
@@ -149,5 +187,90 @@ func (ServiceAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		}
 		a.Results = append(a.Results, currentAnalysis)
 	}
+
+	// The nodePort range is shared by the whole cluster, so it is only
+	// checked when analyzing all Services.
+	if a.Namespace == "" && a.LabelSelector == "" && a.FieldSelector == "" {
+		if result, ok := analyzeNodePortRange(a); ok {
+			a.Results = append(a.Results, result)
+		}
+	}
+	snapshot.Apply()
 	return a.Results, nil
 }
+
+// selectorMismatch is a label of a Service selector that the pods don't carry.
+type selectorMismatch struct {
+	key      string
+	expected string
+	actual   string
+	missing  bool
+}
+
+func (m selectorMismatch) String() string {
+	if m.missing {
+		return fmt.Sprintf("selector has %s=%s but pods have no %s label", m.key, m.expected, m.key)
+	}
+	return fmt.Sprintf("selector has %s=%s but pods have %s=%s", m.key, m.expected, m.key, m.actual)
+}
+
+func (m selectorMismatch) sensitive() []common.Sensitive {
+	values := []string{m.key, m.expected}
+	if !m.missing {
+		values = append(values, m.actual)
+	}
+	var sensitive []common.Sensitive
+	for _, value := range values {
+		sensitive = append(sensitive, common.Sensitive{
+			Unmasked: value,
+			Masked:   util.MaskString(value),
+		})
+	}
+	return sensitive
+}
+
+// selectorNearMiss looks for the Deployment whose pod template labels come
+// closest to the selector: it must carry at least one of the selector keys,
+// and the one with the fewest mismatching labels wins. Nothing is returned
+// when a Deployment matches the selector exactly, as the missing endpoints
+// are then not caused by the selector.
+func selectorNearMiss(selector map[string]string, deployments []appsv1.Deployment) (*appsv1.Deployment, []selectorMismatch) {
+	keys := make([]string, 0, len(selector))
+	for key := range selector {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var closest *appsv1.Deployment
+	var closestMismatches []selectorMismatch
+	for i := range deployments {
+		labels := deployments[i].Spec.Template.Labels
+		var mismatches []selectorMismatch
+		sharedKeys := 0
+		for _, key := range keys {
+			actual, ok := labels[key]
+			if ok {
+				sharedKeys++
+			}
+			if actual != selector[key] || !ok {
+				mismatches = append(mismatches, selectorMismatch{
+					key:      key,
+					expected: selector[key],
+					actual:   actual,
+					missing:  !ok,
+				})
+			}
+		}
+		if len(mismatches) == 0 {
+			return nil, nil
+		}
+		if sharedKeys == 0 {
+			continue
+		}
+		if closest == nil || len(mismatches) < len(closestMismatches) {
+			closest = &deployments[i]
+			closestMismatches = mismatches
+		}
+	}
+	return closest, closestMismatches
+}
@@ -15,14 +15,17 @@ package analyzer
 
 import (
 	"context"
+	"fmt"
 	"sort"
 	"testing"
 
 	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 )
@@ -268,3 +271,167 @@ func TestServiceAnalyzerLabelSelectorFiltering(t *testing.T) {
 	require.Equal(t, 1, len(results))
 	require.Equal(t, "default/Endpoint1", results[0].Name)
 }
+
+func TestServiceAnalyzerSelectorNearMiss(t *testing.T) {
+	service := func(name string, selector map[string]string) []runtime.Object {
+		return []runtime.Object{
+			&v1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			},
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+				Spec:       v1.ServiceSpec{Selector: selector},
+			},
+		}
+	}
+	deployment := func(name string, labels map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: appsv1.DeploymentSpec{
+				Template: v1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				},
+			},
+		}
+	}
+
+	objects := []runtime.Object{
+		deployment("frontend", map[string]string{"app": "bar", "tier": "web"}),
+		deployment("backend", map[string]string{"app": "backend"}),
+		deployment("worker", map[string]string{"app": "worker", "tier": "jobs"}),
+		deployment("unrelated", map[string]string{"component": "db"}),
+	}
+	// Differs from frontend in the value of app only.
+	objects = append(objects, service("frontend", map[string]string{"app": "foo", "tier": "web"})...)
+	// Matches worker exactly, so the selector isn't the problem.
+	objects = append(objects, service("worker", map[string]string{"app": "worker"})...)
+	// Shares no label key with any Deployment.
+	objects = append(objects, service("cache", map[string]string{"role": "cache"})...)
+
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(objects...),
+		},
+		Context:   context.Background(),
+		Namespace: "test",
+	}
+
+	results, err := ServiceAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+
+	texts := map[string][]string{}
+	for _, result := range results {
+		for _, failure := range result.Error {
+			texts[result.Name] = append(texts[result.Name], failure.Text)
+		}
+	}
+	require.Contains(t, texts["test/frontend"], "Service selector does not match the pods of Deployment frontend: selector has app=foo but pods have app=bar")
+	require.Len(t, texts["test/frontend"], 3)
+	require.Equal(t, []string{"Service has no endpoints, expected label app=worker"}, texts["test/worker"])
+	require.Equal(t, []string{"Service has no endpoints, expected label role=cache"}, texts["test/cache"])
+}
+
+func TestSelectorNearMiss(t *testing.T) {
+	deployments := []appsv1.Deployment{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "one-off"},
+			Spec: appsv1.DeploymentSpec{Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "api", "version": "v2"}},
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "two-off"},
+			Spec: appsv1.DeploymentSpec{Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+			}},
+		},
+	}
+
+	deployment, mismatches := selectorNearMiss(map[string]string{"app": "api", "version": "v1"}, deployments)
+	require.NotNil(t, deployment)
+	require.Equal(t, "one-off", deployment.Name)
+	require.Equal(t, []selectorMismatch{{key: "version", expected: "v1", actual: "v2"}}, mismatches)
+
+	deployment, mismatches = selectorNearMiss(map[string]string{"app": "web", "tier": "frontend"}, deployments)
+	require.NotNil(t, deployment)
+	require.Equal(t, "two-off", deployment.Name)
+	require.Equal(t, "selector has tier=frontend but pods have no tier label", mismatches[0].String())
+}
+
+func TestServiceAnalyzerNodePortRange(t *testing.T) {
+	objects := []runtime.Object{
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "kube-apiserver-control-plane",
+				Namespace: "kube-system",
+				Labels:    map[string]string{"component": "kube-apiserver"},
+			},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name:    "kube-apiserver",
+						Command: []string{"kube-apiserver", "--service-node-port-range=30000-30009"},
+					},
+				},
+			},
+		},
+		&v1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "web.1", Namespace: "default"},
+			InvolvedObject: v1.ObjectReference{Kind: "Service", Name: "web", Namespace: "default"},
+			Type:           "Warning",
+			Message:        "failed to allocate a nodePort: range is full",
+		},
+	}
+	for i := int32(0); i < 9; i++ {
+		objects = append(objects, &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("svc-%d", i), Namespace: "default"},
+			Spec: v1.ServiceSpec{
+				Type:  v1.ServiceTypeNodePort,
+				Ports: []v1.ServicePort{{Port: 80, NodePort: 30000 + i}},
+			},
+		})
+	}
+
+	config := common.Analyzer{
+		Client:  &kubernetes.Client{Client: fake.NewSimpleClientset(objects...)},
+		Context: context.Background(),
+	}
+
+	results, err := ServiceAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "nodePort range 30000-30009", results[0].Name)
+	require.Len(t, results[0].Error, 2)
+	require.Equal(t, "9 of the 10 ports of the nodePort range 30000-30009 are allocated; new NodePort and LoadBalancer Services fail to allocate a port once it is full", results[0].Error[0].Text)
+	require.Equal(t, common.SeverityMedium, results[0].Error[0].Severity)
+	require.Equal(t, "nodePort allocation failed for Service default/web: failed to allocate a nodePort: range is full", results[0].Error[1].Text)
+
+	// A single namespace can't tell how full the cluster-wide range is.
+	config.Namespace = "default"
+	results, err = ServiceAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	require.Empty(t, results)
+}
+
+func TestParseNodePortRange(t *testing.T) {
+	tests := []struct {
+		value string
+		start int32
+		end   int32
+		ok    bool
+	}{
+		{value: "30000-32767", start: 30000, end: 32767, ok: true},
+		{value: "20000+100", start: 20000, end: 20100, ok: true},
+		{value: "32767-30000"},
+		{value: "30000"},
+		{value: "a-b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			start, end, ok := parseNodePortRange(tt.value)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.start, start)
+			require.Equal(t, tt.end, end)
+		})
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	// serviceAccountNotFoundPattern matches the pods rejected by admission for
+	// a missing ServiceAccount, e.g. in the FailedCreate events of ReplicaSets.
+	serviceAccountNotFoundPattern = regexp.MustCompile(`error looking up service account ([^/\s]+)/([^:\s]+): serviceaccount "[^"]+" not found`)
+	// rbacDeniedPattern matches the requests of a ServiceAccount denied by
+	// RBAC, capturing its namespace and name and what it can't do.
+	rbacDeniedPattern = regexp.MustCompile(`User "system:serviceaccount:([^:"]+):([^"]+)" cannot (\S+ resource "[^"]*"(?: in API group "[^"]*")?(?: in the namespace "[^"]*"| at the cluster scope)?)`)
+)
+
+// ServiceAccountAnalyzer reports ServiceAccounts used by pods or pod
+// templates that don't exist, and ServiceAccounts whose requests are denied,
+// found in Forbidden and Unauthorized warning events.
+type ServiceAccountAnalyzer struct{}
+
+// serviceAccountFindings gathers what is found about a ServiceAccount.
+type serviceAccountFindings struct {
+	users   map[string]bool
+	denials map[string]bool
+	events  map[string]bool
+}
+
+func (ServiceAccountAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "ServiceAccount"
+
+	snapshot := newAnalyzerErrorsSnapshot(kind)
+
+	serviceAccounts, err := a.Client.GetClient().CoreV1().ServiceAccounts(a.Namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	existing := map[string]bool{}
+	for _, sa := range serviceAccounts.Items {
+		existing[sa.Namespace+"/"+sa.Name] = true
+	}
+
+	pods, err := a.Client.GetClient().CoreV1().Pods(a.Namespace).List(a.Context, metav1.ListOptions{
+		LabelSelector: a.LabelSelector,
+		FieldSelector: util.FieldSelectorFor("Pod", a.FieldSelector),
+	})
+	if err != nil {
+		return nil, err
+	}
+	templates, err := workloadPodTemplates(a)
+	if err != nil {
+		return nil, err
+	}
+	events, err := a.Client.GetClient().CoreV1().Events(a.Namespace).List(a.Context, metav1.ListOptions{
+		FieldSelector: "type=Warning",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	findings := map[string]*serviceAccountFindings{}
+	find := func(key string) *serviceAccountFindings {
+		if findings[key] == nil {
+			findings[key] = &serviceAccountFindings{users: map[string]bool{}, denials: map[string]bool{}, events: map[string]bool{}}
+		}
+		return findings[key]
+	}
+
+	// referenced holds the ServiceAccounts of the analyzed pods and templates,
+	// podServiceAccounts the one of each pod.
+	referenced := map[string]bool{}
+	podServiceAccounts := map[string]string{}
+	for _, pod := range pods.Items {
+		key := pod.Namespace + "/" + serviceAccountName(pod.Spec)
+		referenced[key] = true
+		podServiceAccounts[pod.Namespace+"/"+pod.Name] = key
+		if !existing[key] && !util.CreatedWithin(pod.ObjectMeta, a.MinAge) {
+			find(key).users[fmt.Sprintf("Pod %s/%s", pod.Namespace, pod.Name)] = true
+		}
+	}
+	for _, template := range templates {
+		key := template.meta.Namespace + "/" + serviceAccountName(template.spec)
+		referenced[key] = true
+		if !existing[key] && !util.CreatedWithin(template.meta, a.MinAge) {
+			find(key).users[fmt.Sprintf("%s %s/%s", template.kind, template.meta.Namespace, template.meta.Name)] = true
+		}
+	}
+
+	for _, event := range events.Items {
+		object := fmt.Sprintf("%s %s/%s", event.InvolvedObject.Kind, event.InvolvedObject.Namespace, event.InvolvedObject.Name)
+		if match := serviceAccountNotFoundPattern.FindStringSubmatch(event.Message); match != nil {
+			key := match[1] + "/" + match[2]
+			// Only the ServiceAccounts of the analyzed namespace are known.
+			if !existing[key] && (a.Namespace == "" || match[1] == a.Namespace) {
+				find(key).users[object] = true
+			}
+			continue
+		}
+		if match := rbacDeniedPattern.FindStringSubmatch(event.Message); match != nil {
+			findings := find(match[1] + "/" + match[2])
+			findings.denials["cannot "+match[3]] = true
+			findings.events[fmt.Sprintf("%s (%s)", object, event.Reason)] = true
+			continue
+		}
+		// An Unauthorized request of a pod is made with the token of its ServiceAccount.
+		if event.InvolvedObject.Kind == "Pod" && (event.Reason == "Unauthorized" || strings.Contains(event.Message, "Unauthorized")) {
+			if key, ok := podServiceAccounts[event.InvolvedObject.Namespace+"/"+event.InvolvedObject.Name]; ok {
+				findings := find(key)
+				findings.denials["was not authenticated: "+event.Message] = true
+				findings.events[fmt.Sprintf("%s (%s)", object, event.Reason)] = true
+			}
+		}
+	}
+
+	for _, key := range mapKeys(findings) {
+		// Events can't be matched against selectors, so with one only the
+		// ServiceAccounts of the analyzed pods and templates are reported.
+		if (a.LabelSelector != "" || a.FieldSelector != "") && !referenced[key] {
+			continue
+		}
+		namespace, name, _ := strings.Cut(key, "/")
+		sensitive := []common.Sensitive{
+			{
+				Unmasked: namespace,
+				Masked:   util.MaskString(namespace),
+			},
+			{
+				Unmasked: name,
+				Masked:   util.MaskString(name),
+			},
+		}
+
+		var failures []common.Failure
+		if users := mapKeys(findings[key].users); len(users) > 0 {
+			failures = append(failures, common.Failure{
+				Text:      fmt.Sprintf("ServiceAccount %s does not exist, but is used by %s", key, strings.Join(users, ", ")),
+				Sensitive: sensitive,
+				FieldPath: "spec.serviceAccountName",
+				Severity:  common.SeverityHigh,
+			})
+		}
+		if denials := mapKeys(findings[key].denials); len(denials) > 0 {
+			failures = append(failures, common.Failure{
+				Text: fmt.Sprintf("ServiceAccount %s had requests denied: it %s (reported by %s)",
+					key, strings.Join(denials, "; it "), strings.Join(mapKeys(findings[key].events), ", ")),
+				Sensitive: sensitive,
+				Severity:  common.SeverityMedium,
+			})
+		}
+		if len(failures) == 0 {
+			continue
+		}
+
+		snapshot.Set(name, namespace, float64(len(failures)))
+		a.Results = append(a.Results, common.Result{
+			Kind:  kind,
+			Name:  key,
+			Error: failures,
+		})
+	}
+
+	snapshot.Apply()
+	return a.Results, nil
+}
+
+// serviceAccountName returns the ServiceAccount a pod runs as.
+func serviceAccountName(spec v1.PodSpec) string {
+	if spec.ServiceAccountName != "" {
+		return spec.ServiceAccountName
+	}
+	if spec.DeprecatedServiceAccount != "" {
+		return spec.DeprecatedServiceAccount
+	}
+	return "default"
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func serviceAccount(name string) *v1.ServiceAccount {
+	return &v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+}
+
+func serviceAccountPod(name string, serviceAccount string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": name}},
+		Spec:       v1.PodSpec{ServiceAccountName: serviceAccount},
+	}
+}
+
+func warningEvent(name string, kind string, object string, reason string, message string) *v1.Event {
+	return &v1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default"},
+		InvolvedObject: v1.ObjectReference{Kind: kind, Name: object, Namespace: "default"},
+		Type:           v1.EventTypeWarning,
+		Reason:         reason,
+		Message:        message,
+	}
+}
+
+func TestServiceAccountAnalyzer(t *testing.T) {
+	tests := []struct {
+		name     string
+		objects  []runtime.Object
+		expected map[string][]string
+	}{
+		{
+			name: "existing ServiceAccounts",
+			objects: []runtime.Object{
+				serviceAccount("default"), serviceAccount("app"),
+				serviceAccountPod("web", "app"),
+				serviceAccountPod("batch", ""),
+			},
+			expected: map[string][]string{},
+		},
+		{
+			name: "missing ServiceAccount of a pod and a deployment",
+			objects: []runtime.Object{
+				serviceAccount("default"),
+				serviceAccountPod("web", "app"),
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+					Spec: appsv1.DeploymentSpec{
+						Template: v1.PodTemplateSpec{Spec: v1.PodSpec{ServiceAccountName: "app"}},
+					},
+				},
+			},
+			expected: map[string][]string{
+				"default/app": {"ServiceAccount default/app does not exist, but is used by Deployment default/api, Pod default/web"},
+			},
+		},
+		{
+			name: "pods rejected by admission",
+			objects: []runtime.Object{
+				serviceAccount("default"),
+				warningEvent("api-rs.1", "ReplicaSet", "api-rs", "FailedCreate",
+					`Error creating: pods "api-rs-x2v4k" is forbidden: error looking up service account default/app: serviceaccount "app" not found`),
+			},
+			expected: map[string][]string{
+				"default/app": {"ServiceAccount default/app does not exist, but is used by ReplicaSet default/api-rs"},
+			},
+		},
+		{
+			name: "requests denied by RBAC",
+			objects: []runtime.Object{
+				serviceAccount("default"), serviceAccount("operator"),
+				warningEvent("cluster.1", "Cluster", "db", "ReconcileError",
+					`pods is forbidden: User "system:serviceaccount:default:operator" cannot list resource "pods" in API group "" in the namespace "kube-system"`),
+				warningEvent("cluster.2", "Cluster", "db", "ReconcileError",
+					`leases.coordination.k8s.io "db-leader" is forbidden: User "system:serviceaccount:default:operator" cannot get resource "leases" in API group "coordination.k8s.io" in the namespace "default"`),
+			},
+			expected: map[string][]string{
+				"default/operator": {`ServiceAccount default/operator had requests denied: it cannot get resource "leases" in API group "coordination.k8s.io" in the namespace "default"; it cannot list resource "pods" in API group "" in the namespace "kube-system" (reported by Cluster default/db (ReconcileError))`},
+			},
+		},
+		{
+			name: "unauthorized requests of a pod",
+			objects: []runtime.Object{
+				serviceAccount("default"), serviceAccount("app"),
+				serviceAccountPod("web", "app"),
+				warningEvent("web.1", "Pod", "web", "Unauthorized", "Unauthorized: the service account token has expired"),
+			},
+			expected: map[string][]string{
+				"default/app": {"ServiceAccount default/app had requests denied: it was not authenticated: Unauthorized: the service account token has expired (reported by Pod default/web (Unauthorized))"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := common.Analyzer{
+				Client: &kubernetes.Client{
+					Client: fake.NewSimpleClientset(tt.objects...),
+				},
+				Context:   context.Background(),
+				Namespace: "default",
+			}
+
+			results, err := ServiceAccountAnalyzer{}.Analyze(config)
+			require.NoError(t, err)
+
+			got := map[string][]string{}
+			for _, result := range results {
+				require.Equal(t, "ServiceAccount", result.Kind)
+				for _, failure := range result.Error {
+					got[result.Name] = append(got[result.Name], failure.Text)
+				}
+			}
+			require.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestServiceAccountAnalyzerLabelSelector(t *testing.T) {
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				serviceAccount("default"),
+				serviceAccountPod("web", "web"),
+				serviceAccountPod("api", "api"),
+				warningEvent("cluster.1", "Cluster", "db", "ReconcileError",
+					`pods is forbidden: User "system:serviceaccount:default:operator" cannot list resource "pods" in API group "" at the cluster scope`),
+			),
+		},
+		Context:       context.Background(),
+		Namespace:     "default",
+		LabelSelector: "app=web",
+	}
+
+	results, err := ServiceAccountAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "default/web", results[0].Name)
+	require.Len(t, results[0].Error[0].Sensitive, 2)
+	require.Equal(t, "default", results[0].Error[0].Sensitive[0].Unmasked)
+	require.Equal(t, "web", results[0].Error[0].Sensitive[1].Unmasked)
+}
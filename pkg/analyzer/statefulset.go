@@ -38,17 +38,18 @@ func (StatefulSetAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		OpenapiSchema: a.OpenapiSchema,
 	}
 
-	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
-		"analyzer_name": kind,
-	})
+	snapshot := newAnalyzerErrorsSnapshot(kind)
 
-	list, err := a.Client.GetClient().AppsV1().StatefulSets(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector})
+	list, err := a.Client.GetClient().AppsV1().StatefulSets(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor("StatefulSet", a.FieldSelector)})
 	if err != nil {
 		return nil, err
 	}
 	var preAnalysis = map[string]common.PreAnalysis{}
 
 	for _, sts := range list.Items {
+		if util.CreatedWithin(sts.ObjectMeta, a.MinAge) {
+			continue
+		}
 		var failures []common.Failure
 
 		// get serviceName
@@ -64,6 +65,7 @@ func (StatefulSetAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 					serviceName,
 				),
 				KubernetesDoc: doc,
+				FieldPath:     "spec.serviceName",
 				Sensitive: []common.Sensitive{
 					{
 						Unmasked: sts.Namespace,
@@ -77,12 +79,13 @@ func (StatefulSetAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 			})
 		}
 		if len(sts.Spec.VolumeClaimTemplates) > 0 {
-			for _, volumeClaimTemplate := range sts.Spec.VolumeClaimTemplates {
+			for i, volumeClaimTemplate := range sts.Spec.VolumeClaimTemplates {
 				if volumeClaimTemplate.Spec.StorageClassName != nil {
 					_, err := a.Client.GetClient().StorageV1().StorageClasses().Get(a.Context, *volumeClaimTemplate.Spec.StorageClassName, metav1.GetOptions{})
 					if err != nil {
 						failures = append(failures, common.Failure{
-							Text: fmt.Sprintf("StatefulSet uses the storage class %s which does not exist.", *volumeClaimTemplate.Spec.StorageClassName),
+							Text:      fmt.Sprintf("StatefulSet uses the storage class %s which does not exist.", *volumeClaimTemplate.Spec.StorageClassName),
+							FieldPath: fmt.Sprintf("spec.volumeClaimTemplates[%d].spec.storageClassName", i),
 							Sensitive: []common.Sensitive{
 								{
 									Unmasked: *volumeClaimTemplate.Spec.StorageClassName,
@@ -134,7 +137,7 @@ func (StatefulSetAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				StatefulSet:    sts,
 				FailureDetails: failures,
 			}
-			AnalyzerErrorsMetric.WithLabelValues(kind, sts.Name, sts.Namespace).Set(float64(len(failures)))
+			snapshot.Set(sts.Name, sts.Namespace, float64(len(failures)))
 		}
 	}
 
@@ -152,5 +155,6 @@ func (StatefulSetAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		a.Results = append(a.Results, currentAnalysis)
 	}
 
+	snapshot.Apply()
 	return a.Results, nil
 }
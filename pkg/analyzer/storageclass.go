@@ -0,0 +1,169 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	defaultStorageClassAnnotation     = "storageclass.kubernetes.io/is-default-class"
+	betaDefaultStorageClassAnnotation = "storageclass.beta.kubernetes.io/is-default-class"
+	provisionedByAnnotation           = "pv.kubernetes.io/provisioned-by"
+)
+
+// StorageClassAnalyzer reports a missing or ambiguous default StorageClass,
+// and StorageClasses whose provisioner doesn't seem to be installed while
+// claims wait on it. A provisioner counts as installed when it has a
+// CSIDriver, is built in, or has provisioned a volume before, as external
+// provisioners that aren't CSI drivers can't be detected otherwise.
+type StorageClassAnalyzer struct{}
+
+func (StorageClassAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "StorageClass"
+
+	snapshot := newAnalyzerErrorsSnapshot(kind)
+
+	list, err := a.Client.GetClient().StorageV1().StorageClasses().List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor(kind, a.FieldSelector)})
+	if err != nil {
+		return nil, err
+	}
+
+	// The default class is only meaningful among all StorageClasses.
+	if a.LabelSelector == "" && a.FieldSelector == "" {
+		if result, ok := analyzeDefaultStorageClass(list.Items); ok {
+			snapshot.Set("", "", float64(len(result.Error)))
+			a.Results = append(a.Results, result)
+		}
+	}
+
+	pending, err := pendingClaimsByClass(a)
+	if err != nil {
+		return nil, err
+	}
+	installed := installedProvisioners(a)
+
+	for _, sc := range list.Items {
+		if util.CreatedWithin(sc.ObjectMeta, a.MinAge) || installed[sc.Provisioner] ||
+			strings.HasPrefix(sc.Provisioner, "kubernetes.io/") || len(pending[sc.Name]) == 0 {
+			continue
+		}
+		claims := pending[sc.Name]
+		sort.Strings(claims)
+		failures := []common.Failure{
+			{
+				Text: fmt.Sprintf("StorageClass %s uses the provisioner %s, which has no CSIDriver and has not provisioned any volume; %d PersistentVolumeClaim(s) using it are Pending: %s",
+					sc.Name, sc.Provisioner, len(claims), strings.Join(claims, ", ")),
+				Sensitive: []common.Sensitive{
+					{
+						Unmasked: sc.Name,
+						Masked:   util.MaskString(sc.Name),
+					},
+				},
+				FieldPath: "provisioner",
+				Severity:  common.SeverityHigh,
+			},
+		}
+		snapshot.Set(sc.Name, "", float64(len(failures)))
+		a.Results = append(a.Results, common.Result{
+			Kind:  kind,
+			Name:  sc.Name,
+			Error: failures,
+		})
+	}
+
+	snapshot.Apply()
+	return a.Results, nil
+}
+
+// analyzeDefaultStorageClass reports when no StorageClass, or more than one,
+// is marked as the default. Claims without a storageClassName aren't
+// provisioned without a default, and with several the newest one is used.
+func analyzeDefaultStorageClass(classes []storagev1.StorageClass) (common.Result, bool) {
+	var defaults []string
+	for _, sc := range classes {
+		if sc.Annotations[defaultStorageClassAnnotation] == "true" || sc.Annotations[betaDefaultStorageClassAnnotation] == "true" {
+			defaults = append(defaults, sc.Name)
+		}
+	}
+	sort.Strings(defaults)
+
+	var failure common.Failure
+	switch {
+	case len(defaults) == 0:
+		failure = common.Failure{
+			Text:      fmt.Sprintf("no StorageClass is annotated with %s=true, so PersistentVolumeClaims without a storageClassName are not dynamically provisioned", defaultStorageClassAnnotation),
+			Sensitive: []common.Sensitive{},
+			Severity:  common.SeverityMedium,
+		}
+	case len(defaults) > 1:
+		failure = common.Failure{
+			Text:      fmt.Sprintf("%d StorageClasses are annotated as the default (%s); the most recently created one is used for PersistentVolumeClaims without a storageClassName", len(defaults), strings.Join(defaults, ", ")),
+			Sensitive: []common.Sensitive{},
+			Severity:  common.SeverityMedium,
+		}
+	default:
+		return common.Result{}, false
+	}
+	return common.Result{
+		Kind:  "StorageClass",
+		Name:  "default StorageClass",
+		Error: []common.Failure{failure},
+	}, true
+}
+
+// pendingClaimsByClass maps StorageClass names to the namespace/name of
+// their Pending PersistentVolumeClaims.
+func pendingClaimsByClass(a common.Analyzer) (map[string][]string, error) {
+	claims, err := a.Client.GetClient().CoreV1().PersistentVolumeClaims(a.Namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	pending := map[string][]string{}
+	for _, pvc := range claims.Items {
+		if pvc.Status.Phase == v1.ClaimPending && pvc.Spec.StorageClassName != nil {
+			name := *pvc.Spec.StorageClassName
+			pending[name] = append(pending[name], fmt.Sprintf("%s/%s", pvc.Namespace, pvc.Name))
+		}
+	}
+	return pending, nil
+}
+
+// installedProvisioners returns the provisioners with a CSIDriver or that
+// provisioned an existing volume. Objects that can't be listed are skipped.
+func installedProvisioners(a common.Analyzer) map[string]bool {
+	installed := map[string]bool{}
+	if drivers, err := a.Client.GetClient().StorageV1().CSIDrivers().List(a.Context, metav1.ListOptions{}); err == nil {
+		for _, driver := range drivers.Items {
+			installed[driver.Name] = true
+		}
+	}
+	if volumes, err := a.Client.GetClient().CoreV1().PersistentVolumes().List(a.Context, metav1.ListOptions{}); err == nil {
+		for _, pv := range volumes.Items {
+			if provisioner := pv.Annotations[provisionedByAnnotation]; provisioner != "" {
+				installed[provisioner] = true
+			}
+		}
+	}
+	return installed
+}
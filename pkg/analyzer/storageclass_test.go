@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func pendingClaim(name string, class string) *v1.PersistentVolumeClaim {
+	return &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       v1.PersistentVolumeClaimSpec{StorageClassName: &class},
+		Status:     v1.PersistentVolumeClaimStatus{Phase: v1.ClaimPending},
+	}
+}
+
+func TestStorageClassAnalyzer(t *testing.T) {
+	defaultClass := map[string]string{defaultStorageClassAnnotation: "true"}
+
+	tests := []struct {
+		name          string
+		objects       []runtime.Object
+		labelSelector string
+		expected      []string
+	}{
+		{
+			name: "single default with installed provisioners",
+			objects: []runtime.Object{
+				&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "csi", Annotations: defaultClass}, Provisioner: "ebs.csi.aws.com"},
+				&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "local"}, Provisioner: "rancher.io/local-path"},
+				&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "gce"}, Provisioner: "kubernetes.io/gce-pd"},
+				&storagev1.CSIDriver{ObjectMeta: metav1.ObjectMeta{Name: "ebs.csi.aws.com"}},
+				&v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv", Annotations: map[string]string{provisionedByAnnotation: "rancher.io/local-path"}}},
+				pendingClaim("a", "csi"),
+				pendingClaim("b", "local"),
+				pendingClaim("c", "gce"),
+			},
+		},
+		{
+			name: "no default",
+			objects: []runtime.Object{
+				&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "standard"}, Provisioner: "kubernetes.io/gce-pd"},
+			},
+			expected: []string{"default StorageClass"},
+		},
+		{
+			name: "multiple defaults, beta annotation included",
+			objects: []runtime.Object{
+				&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "a", Annotations: defaultClass}, Provisioner: "kubernetes.io/gce-pd"},
+				&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "b", Annotations: map[string]string{betaDefaultStorageClassAnnotation: "true"}}, Provisioner: "kubernetes.io/gce-pd"},
+			},
+			expected: []string{"default StorageClass"},
+		},
+		{
+			name: "default checks are skipped with a label selector",
+			objects: []runtime.Object{
+				&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "standard", Labels: map[string]string{"app": "x"}}, Provisioner: "kubernetes.io/gce-pd"},
+			},
+			labelSelector: "app=x",
+		},
+		{
+			name: "missing provisioner with pending claims",
+			objects: []runtime.Object{
+				&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "fast", Annotations: defaultClass}, Provisioner: "pd.csi.storage.gke.io"},
+				&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "unused"}, Provisioner: "nfs.example.com"},
+				pendingClaim("data", "fast"),
+			},
+			expected: []string{"fast"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := common.Analyzer{
+				Client: &kubernetes.Client{
+					Client: fake.NewSimpleClientset(tt.objects...),
+				},
+				Context:       context.Background(),
+				LabelSelector: tt.labelSelector,
+			}
+
+			results, err := StorageClassAnalyzer{}.Analyze(config)
+			require.NoError(t, err)
+
+			var names []string
+			for _, result := range results {
+				require.Equal(t, "StorageClass", result.Kind)
+				require.Len(t, result.Error, 1)
+				names = append(names, result.Name)
+			}
+			require.Equal(t, tt.expected, names)
+		})
+	}
+}
@@ -38,11 +38,9 @@ func (ValidatingWebhookAnalyzer) Analyze(a common.Analyzer) ([]common.Result, er
 		OpenapiSchema: a.OpenapiSchema,
 	}
 
-	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
-		"analyzer_name": kind,
-	})
+	snapshot := newAnalyzerErrorsSnapshot(kind)
 
-	validatingWebhooks, err := a.Client.GetClient().AdmissionregistrationV1().ValidatingWebhookConfigurations().List(context.Background(), v1.ListOptions{LabelSelector: a.LabelSelector})
+	validatingWebhooks, err := a.Client.GetClient().AdmissionregistrationV1().ValidatingWebhookConfigurations().List(context.Background(), v1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: util.FieldSelectorFor("ValidatingWebhookConfiguration", a.FieldSelector)})
 	if err != nil {
 		return nil, err
 	}
@@ -57,10 +55,11 @@ func (ValidatingWebhookAnalyzer) Analyze(a common.Analyzer) ([]common.Result, er
 			svc := webhook.ClientConfig.Service
 			// Get the service
 			service, err := a.Client.GetClient().CoreV1().Services(svc.Namespace).Get(context.Background(), svc.Name, v1.GetOptions{})
+			impact, _ := webhookPolicyImpact(webhook.FailurePolicy)
 			if err != nil {
 				// If the service is not found, we can't check the pods
 				failures = append(failures, common.Failure{
-					Text:          fmt.Sprintf("Service %s not found as mapped to by Validating Webhook %s", svc.Name, webhook.Name),
+					Text:          fmt.Sprintf("Service %s not found as mapped to by Validating Webhook %s; %s", svc.Name, webhook.Name, impact),
 					KubernetesDoc: apiDoc.GetApiDocV2("spec.webhook.clientConfig.service"),
 					Sensitive: []common.Sensitive{
 						{
@@ -77,12 +76,23 @@ func (ValidatingWebhookAnalyzer) Analyze(a common.Analyzer) ([]common.Result, er
 					ValidatingWebhook: webhookConfig,
 					FailureDetails:    failures,
 				}
-				AnalyzerErrorsMetric.WithLabelValues(kind, webhook.Name, webhookConfig.Namespace).Set(float64(len(failures)))
+				snapshot.Set(webhook.Name, webhookConfig.Namespace, float64(len(failures)))
 				continue
 			}
 
+			if failure, ok := analyzeWebhookBackend(a, "Validating", webhook.Name, svc, webhook.FailurePolicy); ok {
+				failures = append(failures, failure)
+			}
+
 			// When Service selectors are empty we defer to service analyser
 			if len(service.Spec.Selector) == 0 {
+				if len(failures) > 0 {
+					preAnalysis[fmt.Sprintf("%s/%s", webhookConfig.Namespace, webhook.Name)] = common.PreAnalysis{
+						ValidatingWebhook: webhookConfig,
+						FailureDetails:    failures,
+					}
+					snapshot.Set(webhook.Name, webhookConfig.Namespace, float64(len(failures)))
+				}
 				continue
 			}
 			// Get pods within service
@@ -138,7 +148,7 @@ func (ValidatingWebhookAnalyzer) Analyze(a common.Analyzer) ([]common.Result, er
 					ValidatingWebhook: webhookConfig,
 					FailureDetails:    failures,
 				}
-				AnalyzerErrorsMetric.WithLabelValues(kind, webhook.Name, webhookConfig.Namespace).Set(float64(len(failures)))
+				snapshot.Set(webhook.Name, webhookConfig.Namespace, float64(len(failures)))
 			}
 		}
 	}
@@ -156,5 +166,6 @@ func (ValidatingWebhookAnalyzer) Analyze(a common.Analyzer) ([]common.Result, er
 		a.Results = append(a.Results, currentAnalysis)
 	}
 
+	snapshot.Apply()
 	return a.Results, nil
 }
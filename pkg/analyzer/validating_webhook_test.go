@@ -215,3 +215,89 @@ func TestValidatingWebhookAnalyzerLabelSelectorFiltering(t *testing.T) {
 	}
 	require.Equal(t, 1, len(results))
 }
+
+func TestValidatingWebhookAnalyzerUnreachableBackend(t *testing.T) {
+	fail := admissionregistrationv1.Fail
+	ignore := admissionregistrationv1.Ignore
+	webhook := func(name string, service string, policy *admissionregistrationv1.FailurePolicyType) admissionregistrationv1.ValidatingWebhook {
+		return admissionregistrationv1.ValidatingWebhook{
+			Name: name,
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{
+				Service: &admissionregistrationv1.ServiceReference{
+					Name:      service,
+					Namespace: "test",
+				},
+			},
+			FailurePolicy: policy,
+		}
+	}
+	service := func(name string) *v1.Service {
+		return &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "test",
+			},
+		}
+	}
+
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				service("ready"),
+				service("unready"),
+				&v1.Endpoints{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "ready",
+						Namespace: "test",
+					},
+					Subsets: []v1.EndpointSubset{
+						{
+							Addresses: []v1.EndpointAddress{{IP: "10.0.0.1"}},
+						},
+					},
+				},
+				&v1.Endpoints{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "unready",
+						Namespace: "test",
+					},
+					Subsets: []v1.EndpointSubset{
+						{
+							NotReadyAddresses: []v1.EndpointAddress{{IP: "10.0.0.2"}},
+						},
+					},
+				},
+				&admissionregistrationv1.ValidatingWebhookConfiguration{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "webhook-config",
+					},
+					Webhooks: []admissionregistrationv1.ValidatingWebhook{
+						webhook("reachable", "ready", &fail),
+						webhook("defaulted", "unready", nil),
+						webhook("ignored", "unready", &ignore),
+						webhook("missing", "absent", &ignore),
+					},
+				},
+			),
+		},
+		Context: context.Background(),
+	}
+
+	results, err := ValidatingWebhookAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+
+	failures := map[string]common.Failure{}
+	for _, result := range results {
+		require.Len(t, result.Error, 1)
+		failures[result.Name] = result.Error[0]
+	}
+	require.Len(t, failures, 3)
+
+	require.Equal(t, "Validating Webhook defaulted calls service test/unready, which has no ready endpoints; with failurePolicy Fail, the API server rejects the requests it intercepts, which can block writes across the cluster", failures["/defaulted"].Text)
+	require.Equal(t, common.SeverityHigh, failures["/defaulted"].Severity)
+
+	require.Contains(t, failures["/ignored"].Text, "with failurePolicy Ignore")
+	require.Equal(t, common.SeverityMedium, failures["/ignored"].Severity)
+
+	require.Equal(t, "Service absent not found as mapped to by Validating Webhook missing; with failurePolicy Ignore, the requests it intercepts are admitted without it once the call times out", failures["/missing"].Text)
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// defaultVolumeAttachmentTimeout is how long a VolumeAttachment may take to
+// be attached, unless common.Analyzer.VolumeAttachmentTimeout is set.
+const defaultVolumeAttachmentTimeout = 2 * time.Minute
+
+// multiAttachPattern matches the FailedAttachVolume events of pods whose
+// volume is attached to another node, capturing the PersistentVolume.
+var multiAttachPattern = regexp.MustCompile(`Multi-Attach error for volume "([^"]+)"`)
+
+// VolumeAttachmentAnalyzer reports CSI VolumeAttachments that aren't attached
+// in time or can't be detached, and those keeping a volume on a node while a
+// pod scheduled on another node needs it (Multi-Attach errors), which leave
+// pods stuck in ContainerCreating. VolumeAttachments are cluster scoped: with
+// a namespace, only those of its claims are reported.
+type VolumeAttachmentAnalyzer struct{}
+
+func (VolumeAttachmentAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "VolumeAttachment"
+
+	snapshot := newAnalyzerErrorsSnapshot(kind)
+
+	list, err := a.Client.GetClient().StorageV1().VolumeAttachments().List(a.Context, metav1.ListOptions{
+		LabelSelector: a.LabelSelector,
+		FieldSelector: util.FieldSelectorFor(kind, a.FieldSelector),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := a.VolumeAttachmentTimeout
+	if timeout == 0 {
+		timeout = defaultVolumeAttachmentTimeout
+	}
+
+	// PersistentVolumes are looked up once each.
+	volumes := map[string]*v1.PersistentVolume{}
+	volume := func(name string) *v1.PersistentVolume {
+		if pv, ok := volumes[name]; ok {
+			return pv
+		}
+		pv, err := a.Client.GetClient().CoreV1().PersistentVolumes().Get(a.Context, name, metav1.GetOptions{})
+		if err != nil {
+			pv = nil
+		}
+		volumes[name] = pv
+		return pv
+	}
+
+	failures := map[string][]common.Failure{}
+	// attached holds the attached VolumeAttachments of each PersistentVolume.
+	attached := map[string][]storagev1.VolumeAttachment{}
+	for _, va := range list.Items {
+		volumeName := ""
+		if va.Spec.Source.PersistentVolumeName != nil {
+			volumeName = *va.Spec.Source.PersistentVolumeName
+		}
+		pv := volume(volumeName)
+		if a.Namespace != "" && (pv == nil || pv.Spec.ClaimRef == nil || pv.Spec.ClaimRef.Namespace != a.Namespace) {
+			continue
+		}
+		sensitive := volumeSensitive(pv)
+
+		if va.Status.Attached {
+			attached[volumeName] = append(attached[volumeName], va)
+		} else if !util.CreatedWithin(va.ObjectMeta, timeout) {
+			text := fmt.Sprintf("VolumeAttachment %s of %s to node %s is not attached after %s (attacher %s)",
+				va.Name, describeVolume(volumeName, pv), va.Spec.NodeName, timeout, va.Spec.Attacher)
+			if va.Status.AttachError != nil && va.Status.AttachError.Message != "" {
+				text += ": " + va.Status.AttachError.Message
+			}
+			failures[va.Name] = append(failures[va.Name], common.Failure{
+				Text:      text,
+				Sensitive: sensitive,
+				Severity:  common.SeverityHigh,
+			})
+		}
+
+		if va.Status.DetachError != nil && va.Status.DetachError.Message != "" {
+			failures[va.Name] = append(failures[va.Name], common.Failure{
+				Text: fmt.Sprintf("VolumeAttachment %s of %s can't be detached from node %s: %s",
+					va.Name, describeVolume(volumeName, pv), va.Spec.NodeName, va.Status.DetachError.Message),
+				Sensitive: sensitive,
+				Severity:  common.SeverityHigh,
+			})
+		}
+	}
+
+	events, err := a.Client.GetClient().CoreV1().Events(a.Namespace).List(a.Context, metav1.ListOptions{
+		FieldSelector: "reason=FailedAttachVolume",
+	})
+	if err != nil {
+		return nil, err
+	}
+	reported := map[string]bool{}
+	for _, event := range events.Items {
+		match := multiAttachPattern.FindStringSubmatch(event.Message)
+		if match == nil || event.Reason != "FailedAttachVolume" || event.InvolvedObject.Kind != "Pod" {
+			continue
+		}
+		volumeName := match[1]
+		holders := attached[volumeName]
+		key := fmt.Sprintf("%s/%s/%s", event.InvolvedObject.Namespace, event.InvolvedObject.Name, volumeName)
+		if len(holders) == 0 || reported[key] {
+			continue
+		}
+		reported[key] = true
+
+		node := "another node"
+		pod, err := a.Client.GetClient().CoreV1().Pods(event.InvolvedObject.Namespace).Get(a.Context, event.InvolvedObject.Name, metav1.GetOptions{})
+		if err != nil {
+			// The pod was deleted since.
+			continue
+		}
+		if pod.Spec.NodeName != "" {
+			node = "node " + pod.Spec.NodeName
+		}
+		pv := volume(volumeName)
+		sensitive := append(volumeSensitive(pv), common.Sensitive{
+			Unmasked: pod.Name,
+			Masked:   util.MaskString(pod.Name),
+		})
+		for _, holder := range holders {
+			failures[holder.Name] = append(failures[holder.Name], common.Failure{
+				Text: fmt.Sprintf("VolumeAttachment %s keeps %s attached to node %s, so pod %s/%s scheduled on %s can't attach it (Multi-Attach error)",
+					holder.Name, describeVolume(volumeName, pv), holder.Spec.NodeName, pod.Namespace, pod.Name, node),
+				Sensitive: sensitive,
+				Severity:  common.SeverityHigh,
+			})
+		}
+	}
+
+	for _, name := range mapKeys(failures) {
+		snapshot.Set(name, "", float64(len(failures[name])))
+		a.Results = append(a.Results, common.Result{
+			Kind:  kind,
+			Name:  name,
+			Error: failures[name],
+		})
+	}
+
+	snapshot.Apply()
+	return a.Results, nil
+}
+
+// describeVolume names a PersistentVolume with its claim and access modes,
+// e.g. "PersistentVolume pvc-1 (claim shop/data, ReadWriteOnce)".
+func describeVolume(name string, pv *v1.PersistentVolume) string {
+	if name == "" {
+		return "an inline volume"
+	}
+	var details []string
+	if pv != nil && pv.Spec.ClaimRef != nil {
+		details = append(details, fmt.Sprintf("claim %s/%s", pv.Spec.ClaimRef.Namespace, pv.Spec.ClaimRef.Name))
+	}
+	if pv != nil && len(pv.Spec.AccessModes) > 0 {
+		modes := make([]string, 0, len(pv.Spec.AccessModes))
+		for _, mode := range pv.Spec.AccessModes {
+			modes = append(modes, string(mode))
+		}
+		details = append(details, strings.Join(modes, ", "))
+	}
+	if len(details) == 0 {
+		return "PersistentVolume " + name
+	}
+	return fmt.Sprintf("PersistentVolume %s (%s)", name, strings.Join(details, ", "))
+}
+
+// volumeSensitive returns the claim of a PersistentVolume as sensitive.
+func volumeSensitive(pv *v1.PersistentVolume) []common.Sensitive {
+	if pv == nil || pv.Spec.ClaimRef == nil {
+		return []common.Sensitive{}
+	}
+	return []common.Sensitive{
+		{
+			Unmasked: pv.Spec.ClaimRef.Namespace,
+			Masked:   util.MaskString(pv.Spec.ClaimRef.Namespace),
+		},
+		{
+			Unmasked: pv.Spec.ClaimRef.Name,
+			Masked:   util.MaskString(pv.Spec.ClaimRef.Name),
+		},
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func attachedVolume(name string, namespace string, claim string) *v1.PersistentVolume {
+	return &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1.PersistentVolumeSpec{
+			AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+			ClaimRef:    &v1.ObjectReference{Namespace: namespace, Name: claim},
+		},
+	}
+}
+
+func volumeAttachment(name string, volume string, node string, attached bool, age time.Duration) *storagev1.VolumeAttachment {
+	return &storagev1.VolumeAttachment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(time.Now().Add(-age))},
+		Spec: storagev1.VolumeAttachmentSpec{
+			Attacher: "ebs.csi.aws.com",
+			NodeName: node,
+			Source:   storagev1.VolumeAttachmentSource{PersistentVolumeName: &volume},
+		},
+		Status: storagev1.VolumeAttachmentStatus{Attached: attached},
+	}
+}
+
+func TestVolumeAttachmentAnalyzer(t *testing.T) {
+	failedAttach := volumeAttachment("csi-1", "pvc-1", "node-1", false, 10*time.Minute)
+	failedAttach.Status.AttachError = &storagev1.VolumeError{Message: "rpc error: code = DeadlineExceeded desc = context deadline exceeded"}
+	failedDetach := volumeAttachment("csi-2", "pvc-2", "node-1", true, time.Hour)
+	failedDetach.Status.DetachError = &storagev1.VolumeError{Message: "volume is still mounted"}
+
+	tests := []struct {
+		name      string
+		namespace string
+		objects   []runtime.Object
+		expected  map[string][]string
+	}{
+		{
+			name: "attached volumes",
+			objects: []runtime.Object{
+				attachedVolume("pvc-1", "shop", "data"),
+				volumeAttachment("csi-1", "pvc-1", "node-1", true, time.Hour),
+				volumeAttachment("csi-2", "pvc-1", "node-2", false, time.Minute),
+			},
+			expected: map[string][]string{},
+		},
+		{
+			name: "attach and detach errors",
+			objects: []runtime.Object{
+				attachedVolume("pvc-1", "shop", "data"),
+				attachedVolume("pvc-2", "shop", "logs"),
+				failedAttach, failedDetach,
+			},
+			expected: map[string][]string{
+				"csi-1": {"VolumeAttachment csi-1 of PersistentVolume pvc-1 (claim shop/data, ReadWriteOnce) to node node-1 is not attached after 2m0s (attacher ebs.csi.aws.com): rpc error: code = DeadlineExceeded desc = context deadline exceeded"},
+				"csi-2": {"VolumeAttachment csi-2 of PersistentVolume pvc-2 (claim shop/logs, ReadWriteOnce) can't be detached from node node-1: volume is still mounted"},
+			},
+		},
+		{
+			name: "multi-attach error",
+			objects: []runtime.Object{
+				attachedVolume("pvc-1", "shop", "data"),
+				volumeAttachment("csi-1", "pvc-1", "node-1", true, time.Hour),
+				&v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "shop"},
+					Spec:       v1.PodSpec{NodeName: "node-2"},
+				},
+				&v1.Event{
+					ObjectMeta:     metav1.ObjectMeta{Name: "db-0.1", Namespace: "shop"},
+					InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: "db-0", Namespace: "shop"},
+					Type:           v1.EventTypeWarning,
+					Reason:         "FailedAttachVolume",
+					Message:        `Multi-Attach error for volume "pvc-1" Volume is already exclusively attached to one node and can't be attached to another`,
+				},
+			},
+			expected: map[string][]string{
+				"csi-1": {"VolumeAttachment csi-1 keeps PersistentVolume pvc-1 (claim shop/data, ReadWriteOnce) attached to node node-1, so pod shop/db-0 scheduled on node node-2 can't attach it (Multi-Attach error)"},
+			},
+		},
+		{
+			name:      "other namespaces",
+			namespace: "web",
+			objects: []runtime.Object{
+				attachedVolume("pvc-1", "shop", "data"),
+				failedAttach,
+			},
+			expected: map[string][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := common.Analyzer{
+				Client: &kubernetes.Client{
+					Client: fake.NewSimpleClientset(tt.objects...),
+				},
+				Context:   context.Background(),
+				Namespace: tt.namespace,
+			}
+
+			results, err := VolumeAttachmentAnalyzer{}.Analyze(config)
+			require.NoError(t, err)
+
+			got := map[string][]string{}
+			for _, result := range results {
+				require.Equal(t, "VolumeAttachment", result.Kind)
+				for _, failure := range result.Error {
+					require.Equal(t, common.SeverityHigh, failure.Severity)
+					got[result.Name] = append(got[result.Name], failure.Text)
+				}
+			}
+			require.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestVolumeAttachmentAnalyzerTimeout(t *testing.T) {
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				attachedVolume("pvc-1", "shop", "data"),
+				volumeAttachment("csi-1", "pvc-1", "node-1", false, 10*time.Minute),
+			),
+		},
+		Context:                 context.Background(),
+		VolumeAttachmentTimeout: 15 * time.Minute,
+	}
+
+	results, err := VolumeAttachmentAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	require.Empty(t, results)
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	regv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// webhookPolicyImpact describes what happens to the requests a webhook
+// intercepts while its backend can't be reached. The failurePolicy defaults
+// to Fail in admissionregistration/v1.
+func webhookPolicyImpact(policy *regv1.FailurePolicyType) (string, common.Severity) {
+	if policy != nil && *policy == regv1.Ignore {
+		return "with failurePolicy Ignore, the requests it intercepts are admitted without it once the call times out", common.SeverityMedium
+	}
+	return "with failurePolicy Fail, the API server rejects the requests it intercepts, which can block writes across the cluster", common.SeverityHigh
+}
+
+// analyzeWebhookBackend reports a webhook whose service has no ready
+// endpoint, so calls to it time out or are refused. Services whose
+// Endpoints can't be read are left to the other checks.
+func analyzeWebhookBackend(a common.Analyzer, webhookType string, webhookName string, svc *regv1.ServiceReference, policy *regv1.FailurePolicyType) (common.Failure, bool) {
+	endpoints, err := a.Client.GetClient().CoreV1().Endpoints(svc.Namespace).Get(a.Context, svc.Name, metav1.GetOptions{})
+	if err != nil {
+		return common.Failure{}, false
+	}
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) > 0 {
+			return common.Failure{}, false
+		}
+	}
+
+	impact, severity := webhookPolicyImpact(policy)
+	return common.Failure{
+		Text: fmt.Sprintf("%s Webhook %s calls service %s/%s, which has no ready endpoints; %s",
+			webhookType, webhookName, svc.Namespace, svc.Name, impact),
+		Sensitive: []common.Sensitive{
+			{
+				Unmasked: webhookName,
+				Masked:   util.MaskString(webhookName),
+			},
+			{
+				Unmasked: svc.Name,
+				Masked:   util.MaskString(svc.Name),
+			},
+		},
+		FieldPath: "webhooks.clientConfig.service",
+		Severity:  severity,
+	}, true
+}
@@ -25,6 +25,8 @@ import (
 	regv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	autov2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	certificatesv1 "k8s.io/api/certificates/v1"
 	v1 "k8s.io/api/core/v1"
 	networkv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
@@ -40,30 +42,74 @@ type Analyzer struct {
 	Context       context.Context
 	Namespace     string
 	LabelSelector string
+	// FieldSelector narrows the objects of the kinds supporting its fields,
+	// see util.FieldSelectorFor.
+	FieldSelector string
 	AIClient      ai.IAI
 	PreAnalysis   map[string]PreAnalysis
 	Results       []Result
 	OpenapiSchema *openapi_v2.Document
+	// MinAge skips objects created more recently than this, as they are often
+	// still transiently unready.
+	MinAge time.Duration
+	// ExcludeContainers are glob patterns of container names whose states
+	// are not reported by the PodAnalyzer, e.g. noisy sidecars.
+	ExcludeContainers []string
+	// PodEnvSourceLookups lets the PodAnalyzer read the ConfigMaps and
+	// Secrets used through envFrom, to find env vars they also define.
+	PodEnvSourceLookups bool
+	// PodErrorReasons and PodEventErrorReasons are container waiting reasons
+	// and pending pod event reasons the PodAnalyzer reports as failures, in
+	// addition to the built-in ones.
+	PodErrorReasons      []string
+	PodEventErrorReasons []string
+	// CustomResources are the kinds of custom resources whose conditions the
+	// CustomResourceAnalyzer checks.
+	CustomResources []CustomResource
+	// ResourceQuotaThreshold is the share of a hard limit from which the
+	// ResourceQuotaAnalyzer reports its usage, 0.9 when unset.
+	ResourceQuotaThreshold float64
+	// EventLookback is how far back the EventAnalyzer reports Warning
+	// events, 1h when unset.
+	EventLookback time.Duration
+	// VolumeAttachmentTimeout is how long the VolumeAttachmentAnalyzer lets
+	// a volume take to be attached, 2m when unset.
+	VolumeAttachmentTimeout time.Duration
+}
+
+// CustomResource is a kind of custom resource for the CustomResourceAnalyzer
+// to check, set with the custom_resources configuration key.
+type CustomResource struct {
+	Group   string `mapstructure:"group"`
+	Version string `mapstructure:"version"`
+	Kind    string `mapstructure:"kind"`
+	// Conditions are the condition types reported when False. All of them
+	// are by default, but those that are False when all is well, such as
+	// Degraded.
+	Conditions []string `mapstructure:"conditions"`
 }
 
 type PreAnalysis struct {
-	Pod                      v1.Pod
-	FailureDetails           []Failure
-	Deployment               appsv1.Deployment
-	ReplicaSet               appsv1.ReplicaSet
-	PersistentVolumeClaim    v1.PersistentVolumeClaim
-	Endpoint                 v1.Endpoints
-	Ingress                  networkv1.Ingress
-	HorizontalPodAutoscalers autov2.HorizontalPodAutoscaler
-	PodDisruptionBudget      policyv1.PodDisruptionBudget
-	StatefulSet              appsv1.StatefulSet
-	NetworkPolicy            networkv1.NetworkPolicy
-	Node                     v1.Node
-	ValidatingWebhook        regv1.ValidatingWebhookConfiguration
-	MutatingWebhook          regv1.MutatingWebhookConfiguration
-	GatewayClass             gtwapi.GatewayClass
-	Gateway                  gtwapi.Gateway
-	HTTPRoute                gtwapi.HTTPRoute
+	Pod                       v1.Pod
+	FailureDetails            []Failure
+	Deployment                appsv1.Deployment
+	ReplicaSet                appsv1.ReplicaSet
+	PersistentVolumeClaim     v1.PersistentVolumeClaim
+	Endpoint                  v1.Endpoints
+	Ingress                   networkv1.Ingress
+	HorizontalPodAutoscalers  autov2.HorizontalPodAutoscaler
+	PodDisruptionBudget       policyv1.PodDisruptionBudget
+	StatefulSet               appsv1.StatefulSet
+	NetworkPolicy             networkv1.NetworkPolicy
+	Node                      v1.Node
+	ValidatingWebhook         regv1.ValidatingWebhookConfiguration
+	MutatingWebhook           regv1.MutatingWebhookConfiguration
+	GatewayClass              gtwapi.GatewayClass
+	Gateway                   gtwapi.Gateway
+	HTTPRoute                 gtwapi.HTTPRoute
+	CertificateSigningRequest certificatesv1.CertificateSigningRequest
+	Job                       batchv1.Job
+	DaemonSet                 appsv1.DaemonSet
 	// Integrations
 	ScaledObject               keda.ScaledObject
 	KyvernoPolicyReport        kyverno.PolicyReport
@@ -76,6 +122,14 @@ type Result struct {
 	Error        []Failure `json:"error"`
 	Details      string    `json:"details"`
 	ParentObject string    `json:"parentObject"`
+	// Warnings flag likely hallucinations in Details, found by the
+	// explanation validators.
+	Warnings []string `json:"warnings,omitempty"`
+	// Confidence estimates from 0 to 1 how well Details addresses the
+	// failures, and HasRemediation whether it gives a concrete fix. They
+	// are only set when explanations are scored.
+	Confidence     float64 `json:"confidence,omitempty"`
+	HasRemediation bool    `json:"hasRemediation,omitempty"`
 }
 
 type AnalysisStats struct {
@@ -87,6 +141,33 @@ type Failure struct {
 	Text          string
 	KubernetesDoc string
 	Sensitive     []Sensitive
+	Severity      Severity `json:",omitempty"`
+	// FieldPath locates the field to fix in the object, e.g.
+	// spec.containers[0].image, when the analyzer can determine it.
+	FieldPath string `json:",omitempty"`
+}
+
+// Severity ranks how urgently a failure needs attention. It is left empty by
+// analyzers that do not rank their failures.
+type Severity string
+
+const (
+	SeverityLow    Severity = "low"
+	SeverityMedium Severity = "medium"
+	SeverityHigh   Severity = "high"
+)
+
+// Rank orders severities from 0 for unranked failures to 3 for high ones.
+func (s Severity) Rank() int {
+	switch s {
+	case SeverityLow:
+		return 1
+	case SeverityMedium:
+		return 2
+	case SeverityHigh:
+		return 3
+	}
+	return 0
 }
 
 type Sensitive struct {
@@ -0,0 +1,162 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Check is the outcome of a single pre-flight check. Err is nil when the
+// check passed.
+type Check struct {
+	Name string
+	Err  error
+}
+
+// resource is an API resource an analyzer lists.
+type resource struct {
+	group    string
+	resource string
+}
+
+// analyzerResources lists the resources each built-in analyzer reads.
+// Analyzers added by integrations are not known here and are skipped, as is
+// the CustomResource analyzer, whose resources are configured.
+var analyzerResources = map[string][]resource{
+	"Pod":                            {{"", "pods"}, {"", "events"}, {"", "nodes"}, {"", "namespaces"}},
+	"Deployment":                     {{"apps", "deployments"}, {"", "pods"}},
+	"ReplicaSet":                     {{"apps", "replicasets"}, {"", "events"}},
+	"PersistentVolumeClaim":          {{"", "persistentvolumeclaims"}, {"", "events"}},
+	"Service":                        {{"", "endpoints"}, {"", "services"}, {"apps", "deployments"}},
+	"Ingress":                        {{"networking.k8s.io", "ingresses"}, {"networking.k8s.io", "ingressclasses"}},
+	"StatefulSet":                    {{"apps", "statefulsets"}, {"", "pods"}},
+	"CronJob":                        {{"batch", "cronjobs"}},
+	"Job":                            {{"batch", "jobs"}, {"batch", "cronjobs"}},
+	"Node":                           {{"", "nodes"}},
+	"ValidatingWebhookConfiguration": {{"admissionregistration.k8s.io", "validatingwebhookconfigurations"}, {"", "pods"}},
+	"MutatingWebhookConfiguration":   {{"admissionregistration.k8s.io", "mutatingwebhookconfigurations"}, {"", "pods"}},
+	"HorizontalPodAutoScaler":        {{"autoscaling", "horizontalpodautoscalers"}},
+	"PodDisruptionBudget":            {{"policy", "poddisruptionbudgets"}},
+	"NetworkPolicy":                  {{"networking.k8s.io", "networkpolicies"}, {"", "pods"}},
+	"Log":                            {{"", "pods"}},
+	"GatewayClass":                   {{"gateway.networking.k8s.io", "gatewayclasses"}},
+	"Gateway":                        {{"gateway.networking.k8s.io", "gateways"}},
+	"HTTPRoute":                      {{"gateway.networking.k8s.io", "httproutes"}},
+	"CertificateSigningRequest":      {{"certificates.k8s.io", "certificatesigningrequests"}},
+	"RestartStorm":                   {{"", "pods"}},
+	"HighAvailability":               {{"apps", "deployments"}, {"apps", "statefulsets"}, {"", "pods"}},
+	"Secret":                         {{"", "secrets"}, {"", "pods"}, {"apps", "deployments"}},
+	"ConfigMap":                      {{"", "configmaps"}, {"", "pods"}, {"apps", "deployments"}, {"apps", "replicasets"}},
+	"PersistentVolume":               {{"", "persistentvolumes"}, {"", "persistentvolumeclaims"}, {"storage.k8s.io", "storageclasses"}},
+	"StorageClass":                   {{"storage.k8s.io", "storageclasses"}, {"storage.k8s.io", "csidrivers"}, {"", "persistentvolumes"}, {"", "persistentvolumeclaims"}},
+	"ResourceQuota":                  {{"", "resourcequotas"}, {"apps", "replicasets"}},
+	"LimitRange":                     {{"", "limitranges"}, {"apps", "deployments"}, {"", "pods"}},
+	"Endpoints":                      {{"", "services"}, {"discovery.k8s.io", "endpointslices"}, {"", "pods"}},
+	"DaemonSet":                      {{"apps", "daemonsets"}, {"", "events"}},
+	"IngressClass":                   {{"networking.k8s.io", "ingressclasses"}, {"networking.k8s.io", "ingresses"}},
+	"PriorityClass":                  {{"scheduling.k8s.io", "priorityclasses"}, {"", "pods"}, {"", "events"}, {"apps", "deployments"}, {"apps", "statefulsets"}, {"apps", "daemonsets"}},
+	"ServiceAccount":                 {{"", "serviceaccounts"}, {"", "pods"}, {"", "events"}, {"apps", "deployments"}, {"apps", "statefulsets"}, {"apps", "daemonsets"}},
+	"Event":                          {{"", "events"}},
+	"VolumeAttachment":               {{"storage.k8s.io", "volumeattachments"}, {"", "persistentvolumes"}, {"", "pods"}, {"", "events"}},
+}
+
+// CheckCluster verifies the API server is reachable.
+func CheckCluster(client kubernetes.Interface) Check {
+	check := Check{Name: "cluster connectivity"}
+	version, err := client.Discovery().ServerVersion()
+	if err != nil {
+		check.Err = err
+		return check
+	}
+	check.Name = fmt.Sprintf("cluster connectivity (Kubernetes %s)", version.GitVersion)
+	return check
+}
+
+// CheckPermissions verifies the current user may list the resources read by
+// each of the given analyzers in the namespace (all namespaces when empty).
+func CheckPermissions(ctx context.Context, client kubernetes.Interface, namespace string, analyzers []string) []Check {
+	sort.Strings(analyzers)
+	var checks []Check
+	for _, name := range analyzers {
+		resources, ok := analyzerResources[name]
+		if !ok {
+			continue
+		}
+		check := Check{Name: fmt.Sprintf("RBAC for %s analyzer", name)}
+		var denied []string
+		for _, r := range resources {
+			review := &authorizationv1.SelfSubjectAccessReview{
+				Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+					ResourceAttributes: &authorizationv1.ResourceAttributes{
+						Namespace: namespace,
+						Verb:      "list",
+						Group:     r.group,
+						Resource:  r.resource,
+					},
+				},
+			}
+			result, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+			if err != nil {
+				check.Err = err
+				break
+			}
+			if !result.Status.Allowed {
+				denied = append(denied, r.String())
+			}
+		}
+		if check.Err == nil && len(denied) > 0 {
+			check.Err = fmt.Errorf("cannot list %s", strings.Join(denied, ", "))
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// CheckAIProvider sends a trivial prompt to the configured provider.
+func CheckAIProvider(ctx context.Context, provider ai.AIProvider) Check {
+	check := Check{Name: fmt.Sprintf("AI provider %s", provider.Name)}
+	if provider.Model != "" {
+		check.Name = fmt.Sprintf("AI provider %s (model %s)", provider.Name, provider.Model)
+	}
+	client := ai.NewClient(provider.Name)
+	if err := client.Configure(&provider); err != nil {
+		check.Err = err
+		return check
+	}
+	defer client.Close()
+	response, err := client.GetCompletion(ctx, "Reply with the single word OK.")
+	if err != nil {
+		check.Err = err
+		return check
+	}
+	if strings.TrimSpace(response) == "" {
+		check.Err = fmt.Errorf("empty response")
+	}
+	return check
+}
+
+func (r resource) String() string {
+	if r.group == "" {
+		return r.resource
+	}
+	return r.resource + "." + r.group
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package doctor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCheckPermissions(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = review.Spec.ResourceAttributes.Resource != "events"
+		return true, review, nil
+	})
+
+	checks := CheckPermissions(context.Background(), client, "default", []string{"Pod", "Node", "Unknown"})
+
+	require.Len(t, checks, 2)
+	require.Equal(t, "RBAC for Node analyzer", checks[0].Name)
+	require.NoError(t, checks[0].Err)
+	require.Equal(t, "RBAC for Pod analyzer", checks[1].Name)
+	require.EqualError(t, checks[1].Err, "cannot list events")
+}
+
+func TestCheckCluster(t *testing.T) {
+	check := CheckCluster(fake.NewSimpleClientset())
+	require.NoError(t, check.Err)
+}
+
+func TestCheckAIProvider(t *testing.T) {
+	check := CheckAIProvider(context.Background(), ai.AIProvider{Name: "noopai"})
+	require.Equal(t, "AI provider noopai", check.Name)
+	require.NoError(t, check.Err)
+}
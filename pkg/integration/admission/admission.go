@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/spf13/viper"
+	"k8s.io/client-go/discovery"
+)
+
+// policyEngineGroups are the API groups of the CRDs installed by the policy
+// engines whose denials are reported.
+var policyEngineGroups = []string{
+	"kyverno.io",
+	"constraints.gatekeeper.sh",
+}
+
+// Admission reports the requests denied by policy engines such as Kyverno
+// and Gatekeeper, and the audit violations of Gatekeeper constraints.
+type Admission struct{}
+
+func NewAdmission() *Admission {
+	return &Admission{}
+}
+
+func (a *Admission) GetAnalyzerName() []string {
+	return []string{
+		"AdmissionDenial",
+	}
+}
+
+func (a *Admission) OwnsAnalyzer(analyzer string) bool {
+
+	for _, name := range a.GetAnalyzerName() {
+		if analyzer == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *Admission) isDeployed() bool {
+	kubecontext := viper.GetString("kubecontext")
+	kubeconfig := viper.GetString("kubeconfig")
+	client, err := kubernetes.NewClient(kubecontext, kubeconfig)
+	if err != nil {
+		// TODO: better error handling
+		color.Red("Error initialising kubernetes client: %v", err)
+		os.Exit(1)
+	}
+	deployed, err := hasPolicyEngine(client.Client.Discovery())
+	if err != nil {
+		// TODO: better error handling
+		color.Red("Error initialising discovery client: %v", err)
+		os.Exit(1)
+	}
+	return deployed
+}
+
+// hasPolicyEngine reports whether the CRDs of one of the policy engines are
+// installed on the cluster.
+func hasPolicyEngine(client discovery.DiscoveryInterface) (bool, error) {
+	groups, err := client.ServerGroups()
+	if err != nil {
+		return false, err
+	}
+	for _, group := range groups.Groups {
+		for _, name := range policyEngineGroups {
+			if group.Name == name {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (a *Admission) isFilterActive() bool {
+	activeFilters := viper.GetStringSlice("active_filters")
+
+	for _, filter := range a.GetAnalyzerName() {
+		for _, af := range activeFilters {
+			if af == filter {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (a *Admission) IsActivate() bool {
+	return a.isFilterActive() && a.isDeployed()
+}
+
+func (a *Admission) AddAnalyzer(mergedMap *map[string]common.IAnalyzer) {
+	(*mergedMap)["AdmissionDenial"] = &AdmissionDenialAnalyzer{}
+}
+
+func (a *Admission) Deploy(namespace string) error {
+	return nil
+}
+
+func (a *Admission) UnDeploy(_ string) error {
+	return nil
+}
+
+func (a *Admission) GetNamespace() (string, error) {
+	return "", nil
+}
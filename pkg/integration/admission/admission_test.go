@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHasPolicyEngine(t *testing.T) {
+	tests := []struct {
+		name          string
+		groupVersions []string
+		expected      bool
+	}{
+		{
+			name:          "no policy engine",
+			groupVersions: []string{"v1", "apps/v1"},
+		},
+		{
+			name:          "kyverno",
+			groupVersions: []string{"v1", "kyverno.io/v1"},
+			expected:      true,
+		},
+		{
+			name:          "gatekeeper",
+			groupVersions: []string{"v1", "constraints.gatekeeper.sh/v1beta1"},
+			expected:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			var resources []*metav1.APIResourceList
+			for _, groupVersion := range tt.groupVersions {
+				resources = append(resources, &metav1.APIResourceList{GroupVersion: groupVersion})
+			}
+			clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = resources
+
+			deployed, err := hasPolicyEngine(clientset.Discovery())
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, deployed)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const gatekeeperConstraintsGroupVersion = "constraints.gatekeeper.sh/v1beta1"
+
+// Admission denials are reported on the controller that attempted the
+// create/update (e.g. the ReplicaSet creating a Pod), not on the rejected object.
+// Policy engines such as Kyverno list the blocking policies and rules on the
+// lines following the denial, so the message is matched across newlines.
+var admissionDeniedPattern = regexp.MustCompile(`(?s)admission webhook "([^"]+)" denied the request:\s*(.*)`)
+
+type AdmissionDenialAnalyzer struct{}
+
+func (AdmissionDenialAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "AdmissionDenial"
+
+	var preAnalysis = map[string][]common.Failure{}
+	var parents = map[string]string{}
+	// the same denial is usually retried by the controller; report it once
+	var seen = map[string]bool{}
+
+	addFailure := func(key string, text string) {
+		if seen[key+text] {
+			return
+		}
+		seen[key+text] = true
+		preAnalysis[key] = append(preAnalysis[key], common.Failure{
+			Text:      text,
+			Sensitive: []common.Sensitive{},
+		})
+	}
+
+	events, err := a.Client.GetClient().CoreV1().Events(a.Namespace).List(a.Context, metav1.ListOptions{
+		FieldSelector: "type=" + v1.EventTypeWarning,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range events.Items {
+		if event.Type != v1.EventTypeWarning {
+			continue
+		}
+		match := admissionDeniedPattern.FindStringSubmatch(event.Message)
+		if match == nil {
+			continue
+		}
+
+		key := fmt.Sprintf("%s/%s", event.InvolvedObject.Namespace, event.InvolvedObject.Name)
+		reason := strings.Join(strings.Fields(match[2]), " ")
+		addFailure(key, fmt.Sprintf("%s %s was blocked by admission webhook %s: %s", event.InvolvedObject.Kind, event.InvolvedObject.Name, match[1], reason))
+		parents[key] = fmt.Sprintf("%s/%s", event.InvolvedObject.Kind, event.InvolvedObject.Name)
+	}
+
+	violations, err := listGatekeeperViolations(a)
+	if err != nil {
+		return nil, err
+	}
+	for _, violation := range violations {
+		if a.Namespace != "" && violation.namespace != a.Namespace {
+			continue
+		}
+		key := fmt.Sprintf("%s/%s", violation.namespace, violation.name)
+		addFailure(key, fmt.Sprintf("%s %s violates Gatekeeper constraint %s/%s: %s", violation.kind, violation.name, violation.constraintKind, violation.constraint, violation.message))
+		parents[key] = fmt.Sprintf("%s/%s", violation.kind, violation.name)
+	}
+
+	for key, failures := range preAnalysis {
+		a.Results = append(a.Results, common.Result{
+			Kind:         kind,
+			Name:         key,
+			Error:        failures,
+			ParentObject: parents[key],
+		})
+	}
+
+	return a.Results, nil
+}
+
+type gatekeeperViolation struct {
+	constraintKind string
+	constraint     string
+	kind           string
+	name           string
+	namespace      string
+	message        string
+}
+
+// listGatekeeperViolations reads the audit violations recorded on Gatekeeper
+// constraints. It returns nothing when Gatekeeper is not installed.
+func listGatekeeperViolations(a common.Analyzer) ([]gatekeeperViolation, error) {
+	resources, err := a.Client.GetClient().Discovery().ServerResourcesForGroupVersion(gatekeeperConstraintsGroupVersion)
+	if err != nil || a.Client.CtrlClient == nil {
+		return nil, nil
+	}
+
+	gv, err := schema.ParseGroupVersion(gatekeeperConstraintsGroupVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []gatekeeperViolation
+	for _, resource := range resources.APIResources {
+		// skip subresources such as <constraint>/status
+		if strings.Contains(resource.Name, "/") {
+			continue
+		}
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gv.WithKind(resource.Kind + "List"))
+		if err := a.Client.CtrlClient.List(a.Context, list, &ctrl.ListOptions{}); err != nil {
+			return nil, err
+		}
+		for _, constraint := range list.Items {
+			items, _, _ := unstructured.NestedSlice(constraint.Object, "status", "violations")
+			for _, item := range items {
+				v, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				violation := gatekeeperViolation{
+					constraintKind: resource.Kind,
+					constraint:     constraint.GetName(),
+				}
+				violation.kind, _, _ = unstructured.NestedString(v, "kind")
+				violation.name, _, _ = unstructured.NestedString(v, "name")
+				violation.namespace, _, _ = unstructured.NestedString(v, "namespace")
+				violation.message, _, _ = unstructured.NestedString(v, "message")
+				violations = append(violations, violation)
+			}
+		}
+	}
+	return violations, nil
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const kyvernoDenialMessage = `Error creating: admission webhook "validate.kyverno.svc-fail" denied the request:
+
+resource Pod/default/nginx-7d8b49557c-x2k5p was blocked due to the following policies
+
+require-labels:
+  check-for-labels: 'validation error: label ''app.kubernetes.io/name'' is required.
+    rule check-for-labels failed at path /metadata/labels/app.kubernetes.io/name/'
+`
+
+func TestAdmissionDenialAnalyzer(t *testing.T) {
+	denial := func(name string, involved string, namespace string, message string) *v1.Event {
+		return &v1.Event{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			InvolvedObject: v1.ObjectReference{
+				Kind:      "ReplicaSet",
+				Name:      involved,
+				Namespace: namespace,
+			},
+			Type:    v1.EventTypeWarning,
+			Reason:  "FailedCreate",
+			Message: message,
+		}
+	}
+
+	clientset := fake.NewSimpleClientset(
+		denial("nginx-rs.1", "nginx-rs", "default", kyvernoDenialMessage),
+		// A retry of the same create, reported as a separate event.
+		denial("nginx-rs.2", "nginx-rs", "default", kyvernoDenialMessage),
+		&v1.Event{
+			// Warning event unrelated to admission control.
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "nginx-pod.1",
+				Namespace: "default",
+			},
+			InvolvedObject: v1.ObjectReference{
+				Kind:      "Pod",
+				Name:      "nginx-pod",
+				Namespace: "default",
+			},
+			Type:    v1.EventTypeWarning,
+			Reason:  "BackOff",
+			Message: "Back-off restarting failed container",
+		},
+		// Not in the analyzed namespace.
+		denial("other-rs.1", "other-rs", "other", `Error creating: admission webhook "validation.gatekeeper.sh" denied the request: [require-labels] missing label`),
+	)
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: gatekeeperConstraintsGroupVersion,
+			APIResources: []metav1.APIResource{
+				{Name: "k8srequiredlabels", Kind: "K8sRequiredLabels"},
+				{Name: "k8srequiredlabels/status", Kind: "K8sRequiredLabels"},
+			},
+		},
+	}
+
+	constraint := &unstructured.Unstructured{}
+	constraint.SetAPIVersion(gatekeeperConstraintsGroupVersion)
+	constraint.SetKind("K8sRequiredLabels")
+	constraint.SetName("ns-must-have-owner")
+	require.NoError(t, unstructured.SetNestedSlice(constraint.Object, []interface{}{
+		map[string]interface{}{
+			"kind":      "ConfigMap",
+			"name":      "settings",
+			"namespace": "default",
+			"message":   "you must provide labels: {\"owner\"}",
+		},
+		map[string]interface{}{
+			"kind":      "ConfigMap",
+			"name":      "settings",
+			"namespace": "other",
+			"message":   "you must provide labels: {\"owner\"}",
+		},
+	}, "status", "violations"))
+
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client:     clientset,
+			CtrlClient: ctrlfake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(constraint).Build(),
+		},
+		Context:   context.Background(),
+		Namespace: "default",
+	}
+
+	results, err := AdmissionDenialAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	require.Len(t, results, 2)
+
+	require.Equal(t, "default/nginx-rs", results[0].Name)
+	require.Equal(t, "ReplicaSet/nginx-rs", results[0].ParentObject)
+	require.Len(t, results[0].Error, 1)
+	require.Equal(t, "ReplicaSet nginx-rs was blocked by admission webhook validate.kyverno.svc-fail: "+
+		"resource Pod/default/nginx-7d8b49557c-x2k5p was blocked due to the following policies "+
+		"require-labels: check-for-labels: 'validation error: label ''app.kubernetes.io/name'' is required. "+
+		"rule check-for-labels failed at path /metadata/labels/app.kubernetes.io/name/'", results[0].Error[0].Text)
+
+	require.Equal(t, "default/settings", results[1].Name)
+	require.Equal(t, "ConfigMap/settings", results[1].ParentObject)
+	require.Equal(t, []common.Failure{
+		{
+			Text:      "ConfigMap settings violates Gatekeeper constraint K8sRequiredLabels/ns-must-have-owner: you must provide labels: {\"owner\"}",
+			Sensitive: []common.Sensitive{},
+		},
+	}, results[1].Error)
+}
+
+func TestAdmissionDenialAnalyzerWithoutGatekeeper(t *testing.T) {
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(),
+		},
+		Context: context.Background(),
+	}
+
+	results, err := AdmissionDenialAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	require.Empty(t, results)
+}
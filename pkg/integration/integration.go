@@ -17,6 +17,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/k8sgpt-ai/k8sgpt/pkg/integration/admission"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/integration/aws"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/integration/kyverno"
 
@@ -52,6 +53,7 @@ var integrations = map[string]IIntegration{
 	"aws":        aws.NewAWS(),
 	"keda":       keda.NewKeda(),
 	"kyverno":    kyverno.NewKyverno(),
+	"admission":  admission.NewAdmission(),
 }
 
 func NewIntegration() *Integration {
@@ -14,6 +14,11 @@ limitations under the License.
 package kubernetes
 
 import (
+	"context"
+	"math"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
 	"k8s.io/client-go/rest"
@@ -33,6 +38,32 @@ func (c *Client) GetCtrlClient() ctrl.Client {
 	return c.CtrlClient
 }
 
+func (c *Client) GetDynamicClient() dynamic.Interface {
+	return c.DynamicClient
+}
+
+// Wait blocks until the Limiter allows a request, or the context is done.
+// It returns at once without a Limiter.
+func (c *Client) Wait(ctx context.Context) error {
+	if c.Limiter == nil {
+		return nil
+	}
+	return c.Limiter.Wait(ctx)
+}
+
+// NewLimiter returns a limiter allowing qps requests per second on average,
+// in bursts of up to burst requests, or nil when qps isn't positive. Without
+// a burst, it is qps rounded up.
+func NewLimiter(qps float64, burst int) *rate.Limiter {
+	if qps <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = int(math.Ceil(qps))
+	}
+	return rate.NewLimiter(rate.Limit(qps), burst)
+}
+
 func NewClient(kubecontext string, kubeconfig string) (*Client, error) {
 	var config *rest.Config
 	config, err := rest.InClusterConfig()
@@ -64,6 +95,11 @@ func NewClient(kubecontext string, kubeconfig string) (*Client, error) {
 		return nil, err
 	}
 
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
 	serverVersion, err := clientSet.ServerVersion()
 	if err != nil {
 		return nil, err
@@ -72,6 +108,7 @@ func NewClient(kubecontext string, kubeconfig string) (*Client, error) {
 	return &Client{
 		Client:        clientSet,
 		CtrlClient:    ctrlClient,
+		DynamicClient: dynamicClient,
 		Config:        config,
 		ServerVersion: serverVersion,
 	}, nil
@@ -0,0 +1,52 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestNewLimiter(t *testing.T) {
+	require.Nil(t, NewLimiter(0, 10))
+
+	limiter := NewLimiter(2.5, 0)
+	require.Equal(t, rate.Limit(2.5), limiter.Limit())
+	require.Equal(t, 3, limiter.Burst())
+
+	limiter = NewLimiter(20, 40)
+	require.Equal(t, rate.Limit(20), limiter.Limit())
+	require.Equal(t, 40, limiter.Burst())
+}
+
+func TestClientWait(t *testing.T) {
+	// Without a limiter requests aren't throttled.
+	require.NoError(t, (&Client{}).Wait(context.Background()))
+
+	client := &Client{Limiter: NewLimiter(1, 1)}
+	require.NoError(t, client.Wait(context.Background()))
+
+	// The next request would wait a second, longer than the context lasts.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.Error(t, client.Wait(ctx))
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, client.Wait(ctx), context.Canceled)
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// clusterScopedKinds are the kinds of objects without a namespace. Objects of
+// other kinds are put in the default namespace when their manifest has none,
+// as kubectl apply does.
+var clusterScopedKinds = map[string]bool{
+	"Namespace":                      true,
+	"Node":                           true,
+	"PersistentVolume":               true,
+	"StorageClass":                   true,
+	"CSIDriver":                      true,
+	"VolumeAttachment":               true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"CustomResourceDefinition":       true,
+	"APIService":                     true,
+	"PriorityClass":                  true,
+	"RuntimeClass":                   true,
+	"IngressClass":                   true,
+	"GatewayClass":                   true,
+	"MutatingWebhookConfiguration":   true,
+	"ValidatingWebhookConfiguration": true,
+	"CertificateSigningRequest":      true,
+}
+
+// NewClientFromDir returns a client serving the objects of the YAML or JSON
+// manifests of a directory and its subdirectories, hidden ones aside, instead
+// of a live cluster, so that analyzers can check manifests before they are
+// deployed. Objects have no status, so the analyzers relying on it report
+// less.
+//
+// The namespaces of the objects, and their default ServiceAccounts, are
+// added as a cluster would. Objects of kinds known to client-go are served
+// by Client, all of them by CtrlClient.
+func NewClientFromDir(dir string) (*Client, error) {
+	path, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []*unstructured.Unstructured
+	err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			// Hidden directories, such as .git, hold no manifests.
+			if file != path && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+		manifests, err := readManifests(file)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", file, err)
+		}
+		objects = append(objects, manifests...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := map[string]bool{}
+	for _, object := range objects {
+		if clusterScopedKinds[object.GetKind()] {
+			object.SetNamespace("")
+			continue
+		}
+		if object.GetNamespace() == "" {
+			object.SetNamespace(v1.NamespaceDefault)
+		}
+		namespaces[object.GetNamespace()] = true
+	}
+
+	seen := map[string]bool{}
+	var typed []runtime.Object
+	var untyped []*unstructured.Unstructured
+	add := func(object *unstructured.Unstructured) error {
+		untyped = append(untyped, object)
+		if obj, err := scheme.Scheme.New(object.GroupVersionKind()); err == nil {
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, obj); err != nil {
+				return fmt.Errorf("invalid %s %s: %w", object.GetKind(), objectName(object), err)
+			}
+			typed = append(typed, obj)
+		}
+		return nil
+	}
+	for _, object := range objects {
+		key := objectKey(object)
+		if seen[key] {
+			return nil, fmt.Errorf("%s %s is defined more than once", object.GetKind(), objectName(object))
+		}
+		seen[key] = true
+		if err := add(object); err != nil {
+			return nil, err
+		}
+	}
+	// A cluster has the namespaces of the objects, with a default
+	// ServiceAccount each, even when the manifests don't define them.
+	for namespace := range namespaces {
+		for _, object := range []*unstructured.Unstructured{
+			implicitObject("Namespace", "", namespace),
+			implicitObject("ServiceAccount", namespace, "default"),
+		} {
+			if !seen[objectKey(object)] {
+				if err := add(object); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	builder := ctrlfake.NewClientBuilder().WithScheme(runtime.NewScheme())
+	dynamicObjects := make([]runtime.Object, 0, len(untyped))
+	for _, object := range untyped {
+		builder = builder.WithObjects(object)
+		dynamicObjects = append(dynamicObjects, object)
+	}
+
+	clientset := fake.NewSimpleClientset(typed...)
+	// Discovery serves the kinds of the objects, so that analyzers reading
+	// resources by kind, such as custom resources, find them.
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = apiResources(untyped)
+
+	return &Client{
+		Client:        clientset,
+		CtrlClient:    builder.Build(),
+		DynamicClient: dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), dynamicObjects...),
+		Config:        &rest.Config{Host: "file://" + path},
+		ServerVersion: &version.Info{},
+	}, nil
+}
+
+// apiResources returns the API resources of the kinds of objects, by group
+// version, guessing the resource names from the kinds.
+func apiResources(objects []*unstructured.Unstructured) []*metav1.APIResourceList {
+	var lists []*metav1.APIResourceList
+	byGroupVersion := map[string]*metav1.APIResourceList{}
+	seen := map[schema.GroupVersionKind]bool{}
+	for _, object := range objects {
+		gvk := object.GroupVersionKind()
+		if seen[gvk] {
+			continue
+		}
+		seen[gvk] = true
+		list, ok := byGroupVersion[gvk.GroupVersion().String()]
+		if !ok {
+			list = &metav1.APIResourceList{GroupVersion: gvk.GroupVersion().String()}
+			byGroupVersion[list.GroupVersion] = list
+			lists = append(lists, list)
+		}
+		plural, singular := meta.UnsafeGuessKindToResource(gvk)
+		list.APIResources = append(list.APIResources, metav1.APIResource{
+			Name:         plural.Resource,
+			SingularName: singular.Resource,
+			Namespaced:   !clusterScopedKinds[gvk.Kind],
+			Kind:         gvk.Kind,
+			Verbs:        metav1.Verbs{"get", "list"},
+		})
+	}
+	return lists
+}
+
+// implicitObject returns a core object that is not in the manifests.
+func implicitObject(kind string, namespace string, name string) *unstructured.Unstructured {
+	object := &unstructured.Unstructured{}
+	object.SetAPIVersion("v1")
+	object.SetKind(kind)
+	object.SetNamespace(namespace)
+	object.SetName(name)
+	return object
+}
+
+func objectKey(object *unstructured.Unstructured) string {
+	return strings.Join([]string{object.GetAPIVersion(), object.GetKind(), object.GetNamespace(), object.GetName()}, "/")
+}
+
+// readManifests returns the objects of a file of YAML documents or JSON
+// objects, expanding lists. Documents without an apiVersion and a kind are
+// skipped.
+func readManifests(file string) ([]*unstructured.Unstructured, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var objects []*unstructured.Unstructured
+	decoder := yaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		var content map[string]interface{}
+		if err := decoder.Decode(&content); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+			return nil, err
+		}
+		object := &unstructured.Unstructured{Object: content}
+		// Other YAML files, such as Helm values, are no objects.
+		if object.GetKind() == "" || object.GetAPIVersion() == "" {
+			continue
+		}
+		if object.IsList() {
+			list, err := object.ToList()
+			if err != nil {
+				return nil, err
+			}
+			for i := range list.Items {
+				objects = append(objects, &list.Items[i])
+			}
+			continue
+		}
+		objects = append(objects, object)
+	}
+}
+
+func objectName(object *unstructured.Unstructured) string {
+	if object.GetNamespace() == "" {
+		return object.GetName()
+	}
+	return object.GetNamespace() + "/" + object.GetName()
+}
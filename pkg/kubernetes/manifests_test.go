@@ -0,0 +1,165 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func writeManifests(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	return dir
+}
+
+func TestNewClientFromDir(t *testing.T) {
+	dir := writeManifests(t, map[string]string{
+		"app.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+        - name: web
+          image: nginx
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: shop
+spec:
+  selector:
+    app: web
+`,
+		"config/list.yml": `apiVersion: v1
+kind: List
+items:
+  - apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: settings
+      namespace: shop
+  - apiVersion: scheduling.k8s.io/v1
+    kind: PriorityClass
+    metadata:
+      name: critical
+      namespace: shop
+    value: 1000
+`,
+		"crds/widget.json": `{"apiVersion": "example.com/v1", "kind": "Widget", "metadata": {"name": "gadget", "namespace": "shop"}}`,
+		"README.md":        "# not a manifest",
+		"values.yaml":      "replicaCount: 2\n",
+		".git/config.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: hidden\n",
+	})
+
+	client, err := NewClientFromDir(dir)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	deployments, err := client.GetClient().AppsV1().Deployments("default").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, deployments.Items, 1)
+	require.Equal(t, "web", deployments.Items[0].Name)
+
+	_, err = client.GetClient().CoreV1().Services("shop").Get(ctx, "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	configMaps, err := client.GetClient().CoreV1().ConfigMaps("").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, configMaps.Items, 1)
+	require.Equal(t, "settings", configMaps.Items[0].Name)
+
+	// Cluster-scoped objects have no namespace.
+	priorityClass, err := client.GetClient().SchedulingV1().PriorityClasses().Get(ctx, "critical", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Empty(t, priorityClass.Namespace)
+
+	// The namespaces and their default ServiceAccounts exist as in a cluster.
+	namespaces, err := client.GetClient().CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, namespaces.Items, 2)
+	for _, namespace := range []string{"default", "shop"} {
+		_, err = client.GetClient().CoreV1().ServiceAccounts(namespace).Get(ctx, "default", metav1.GetOptions{})
+		require.NoError(t, err)
+	}
+
+	// Objects of kinds unknown to client-go are served by the controller-runtime client.
+	widget := &unstructured.Unstructured{}
+	widget.SetGroupVersionKind(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"})
+	require.NoError(t, client.GetCtrlClient().Get(ctx, ctrl.ObjectKey{Namespace: "shop", Name: "gadget"}, widget))
+
+	// and by the dynamic client, with their kinds served by discovery.
+	resources, err := client.GetClient().Discovery().ServerResourcesForGroupVersion("example.com/v1")
+	require.NoError(t, err)
+	require.Equal(t, []metav1.APIResource{
+		{Name: "widgets", SingularName: "widget", Namespaced: true, Kind: "Widget", Verbs: metav1.Verbs{"get", "list"}},
+	}, resources.APIResources)
+	widgets, err := client.GetDynamicClient().Resource(schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}).
+		Namespace("shop").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, widgets.Items, 1)
+}
+
+func TestNewClientFromDirErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		err   string
+	}{
+		{
+			name: "duplicate objects",
+			files: map[string]string{
+				"a.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n",
+				"b.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n  namespace: default\n",
+			},
+			err: "ConfigMap default/settings is defined more than once",
+		},
+		{
+			name:  "invalid YAML",
+			files: map[string]string{"a.yaml": "kind: [ConfigMap\n"},
+			err:   "error reading",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewClientFromDir(writeManifests(t, tt.files))
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+
+	_, err := NewClientFromDir(filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+}
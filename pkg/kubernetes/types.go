@@ -2,8 +2,10 @@ package kubernetes
 
 import (
 	openapi_v2 "github.com/google/gnostic/openapiv2"
+	"golang.org/x/time/rate"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
@@ -12,8 +14,12 @@ import (
 type Client struct {
 	Client        kubernetes.Interface
 	CtrlClient    ctrl.Client
+	DynamicClient dynamic.Interface
 	Config        *rest.Config
 	ServerVersion *version.Info
+	// Limiter throttles the requests made once per object, such as event and
+	// log fetches, when set. See Wait.
+	Limiter *rate.Limiter
 }
 
 type K8sApiReference struct {
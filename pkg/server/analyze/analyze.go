@@ -12,12 +12,18 @@ func (h *Handler) Analyze(ctx context.Context, i *schemav1.AnalyzeRequest) (
 	*schemav1.AnalyzeResponse,
 	error,
 ) {
+	release, err := h.acquire(ctx)
+	if err != nil {
+		return &schemav1.AnalyzeResponse{}, err
+	}
+	defer release()
+
 	if i.Output == "" {
 		i.Output = "json"
 	}
 
-	if int(i.MaxConcurrency) == 0 {
-		i.MaxConcurrency = 10
+	if len(i.Filters) == 0 && h.FilterConfig != nil {
+		i.Filters = h.FilterConfig.Filters()
 	}
 
 	config, err := analysis.NewAnalysis(
@@ -45,7 +51,7 @@ func (h *Handler) Analyze(ctx context.Context, i *schemav1.AnalyzeRequest) (
 	}
 	config.RunAnalysis()
 
-	if i.Explain {
+	if config.Explain {
 		err := config.GetAIResults(i.Output, i.Anonymize)
 		if err != nil {
 			return &schemav1.AnalyzeResponse{}, err
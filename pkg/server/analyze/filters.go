@@ -0,0 +1,105 @@
+package analyze
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// FiltersConfigMapKey is the ConfigMap key holding the analyzers to run,
+// separated by commas or newlines.
+const FiltersConfigMapKey = "filters"
+
+// FilterConfigMap keeps the analyzer filters of the server in sync with an
+// in-cluster ConfigMap, so they can be changed with kubectl edit instead of
+// restarting the server.
+type FilterConfigMap struct {
+	Namespace string
+	Name      string
+	Logger    *zap.Logger
+
+	mu      sync.RWMutex
+	filters []string
+}
+
+// Filters returns the filters currently set in the ConfigMap, if any.
+func (f *FilterConfigMap) Filters() []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.filters
+}
+
+// Watch loads the ConfigMap and reloads the filters whenever it changes,
+// until ctx is cancelled.
+func (f *FilterConfigMap) Watch(ctx context.Context, client kubernetes.Interface) error {
+	factory := informers.NewSharedInformerFactoryWithOptions(client, 0,
+		informers.WithNamespace(f.Namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", f.Name).String()
+		}),
+	)
+	informer := factory.Core().V1().ConfigMaps().Informer()
+	registration, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			f.load(obj)
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			f.load(obj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if configMap, ok := obj.(*v1.ConfigMap); ok && configMap.Name == f.Name {
+				f.set(nil)
+			}
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), registration.HasSynced) {
+		return fmt.Errorf("failed to sync ConfigMap %s/%s", f.Namespace, f.Name)
+	}
+	return nil
+}
+
+func (f *FilterConfigMap) load(obj interface{}) {
+	configMap, ok := obj.(*v1.ConfigMap)
+	if !ok || configMap.Name != f.Name {
+		return
+	}
+	f.set(parseFilters(configMap.Data[FiltersConfigMapKey]))
+}
+
+func (f *FilterConfigMap) set(filters []string) {
+	f.mu.Lock()
+	f.filters = filters
+	f.mu.Unlock()
+	if f.Logger != nil {
+		f.Logger.Info(fmt.Sprintf("loaded filters from ConfigMap %s/%s: %v", f.Namespace, f.Name, filters))
+	}
+}
+
+func parseFilters(data string) []string {
+	var filters []string
+	for _, filter := range strings.FieldsFunc(data, func(r rune) bool {
+		return r == ',' || r == '\n'
+	}) {
+		if filter = strings.TrimSpace(filter); filter != "" {
+			filters = append(filters, filter)
+		}
+	}
+	return filters
+}
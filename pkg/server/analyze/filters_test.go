@@ -0,0 +1,46 @@
+package analyze
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestFilterConfigMap(t *testing.T) {
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "k8sgpt-filters", Namespace: "k8sgpt"},
+		Data:       map[string]string{FiltersConfigMapKey: "Pod, Service\nIngress\n"},
+	}
+	client := fake.NewSimpleClientset(
+		configMap,
+		// Another ConfigMap in the namespace is ignored.
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "k8sgpt"},
+			Data:       map[string]string{FiltersConfigMapKey: "Node"},
+		},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	f := &FilterConfigMap{Namespace: "k8sgpt", Name: "k8sgpt-filters"}
+	require.NoError(t, f.Watch(ctx, client))
+	require.Equal(t, []string{"Pod", "Service", "Ingress"}, f.Filters())
+
+	configMap.Data[FiltersConfigMapKey] = "Deployment"
+	_, err := client.CoreV1().ConfigMaps("k8sgpt").Update(ctx, configMap, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return len(f.Filters()) == 1 && f.Filters()[0] == "Deployment"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, client.CoreV1().ConfigMaps("k8sgpt").Delete(ctx, "k8sgpt-filters", metav1.DeleteOptions{}))
+	require.Eventually(t, func() bool {
+		return f.Filters() == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -1,7 +1,39 @@
 package analyze
 
-import rpc "buf.build/gen/go/k8sgpt-ai/k8sgpt/grpc/go/schema/v1/schemav1grpc"
+import (
+	"context"
+	"sync"
+
+	rpc "buf.build/gen/go/k8sgpt-ai/k8sgpt/grpc/go/schema/v1/schemav1grpc"
+)
 
 type Handler struct {
 	rpc.UnimplementedServerAnalyzerServiceServer
+	// FilterConfig, when set, provides the filters for requests that don't specify any.
+	FilterConfig *FilterConfigMap
+	// MaxConcurrentAnalyses bounds the analyses run at once, so overlapping
+	// requests wait instead of all querying the API server. Zero means no bound.
+	MaxConcurrentAnalyses int
+
+	slotsOnce sync.Once
+	slots     chan struct{}
+}
+
+// acquire waits for an analysis slot, or for ctx to be done. The returned
+// function releases the slot.
+func (h *Handler) acquire(ctx context.Context) (func(), error) {
+	h.slotsOnce.Do(func() {
+		if h.MaxConcurrentAnalyses > 0 {
+			h.slots = make(chan struct{}, h.MaxConcurrentAnalyses)
+		}
+	})
+	if h.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case h.slots <- struct{}{}:
+		return func() { <-h.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
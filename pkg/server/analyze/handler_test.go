@@ -0,0 +1,35 @@
+package analyze
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerAcquire(t *testing.T) {
+	h := &Handler{MaxConcurrentAnalyses: 1}
+
+	release, err := h.acquire(context.Background())
+	require.NoError(t, err)
+
+	// A second analysis waits for the first one.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = h.acquire(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	release()
+	release, err = h.acquire(context.Background())
+	require.NoError(t, err)
+	release()
+}
+
+func TestHandlerAcquireUnbounded(t *testing.T) {
+	h := &Handler{}
+	for i := 0; i < 3; i++ {
+		_, err := h.acquire(context.Background())
+		require.NoError(t, err)
+	}
+}
@@ -57,6 +57,9 @@ type Config struct {
 	metricsServer  *http.Server
 	listener       net.Listener
 	EnableHttp     bool
+	FilterConfig   *analyze.FilterConfigMap
+	// MaxConcurrentAnalyses bounds the analyze requests served at once.
+	MaxConcurrentAnalyses int
 }
 
 type Health struct {
@@ -100,7 +103,7 @@ func (s *Config) Serve() error {
 	}
 
 	s.ConfigHandler = &config.Handler{}
-	s.AnalyzeHandler = &analyze.Handler{}
+	s.AnalyzeHandler = &analyze.Handler{FilterConfig: s.FilterConfig, MaxConcurrentAnalyses: s.MaxConcurrentAnalyses}
 	s.QueryHandler = &query.Handler{}
 	s.listener = lis
 	s.Logger.Info(fmt.Sprintf("binding api to %s", s.Port))
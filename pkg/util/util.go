@@ -24,14 +24,20 @@ import (
 	"net/http"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	k "k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var anonymizePattern = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789!@#$%^&*()-_=+[]{}|;':\",./<>?")
@@ -90,6 +96,16 @@ func GetParent(client *kubernetes.Client, meta metav1.ObjectMeta) (string, bool)
 				}
 				return "Ingress/" + ds.Name, true
 
+			case "CronJob":
+				cj, err := client.GetClient().BatchV1().CronJobs(meta.Namespace).Get(context.Background(), owner.Name, metav1.GetOptions{})
+				if err != nil {
+					return "", false
+				}
+				if cj.OwnerReferences != nil {
+					return GetParent(client, cj.ObjectMeta)
+				}
+				return "CronJob/" + cj.Name, true
+
 			case "MutatingWebhookConfiguration":
 				mw, err := client.GetClient().AdmissionregistrationV1().MutatingWebhookConfigurations().Get(context.Background(), owner.Name, metav1.GetOptions{})
 				if err != nil {
@@ -115,6 +131,81 @@ func GetParent(client *kubernetes.Client, meta metav1.ObjectMeta) (string, bool)
 	return "", false
 }
 
+// maxOwnerDepth bounds the owner references GetRootOwner follows.
+const maxOwnerDepth = 10
+
+// GetRootOwner returns the top-level owner of an object as Kind/name, such as
+// the Deployment of a pod owned by a ReplicaSet, following the controller
+// owner references, or the first ones, of any kind. The walk ends at owners
+// that can't be read, as they were deleted or their kind is unknown without
+// a controller-runtime client, and at cycles. GetParent returns the owner of
+// the known kinds only.
+func GetRootOwner(client *kubernetes.Client, meta metav1.ObjectMeta) (string, bool) {
+	root := ""
+	visited := map[string]bool{}
+	current := meta
+	for depth := 0; depth < maxOwnerDepth; depth++ {
+		ref := metav1.GetControllerOfNoCopy(&current)
+		if ref == nil && len(current.OwnerReferences) > 0 {
+			ref = &current.OwnerReferences[0]
+		}
+		if ref == nil {
+			break
+		}
+		key := ref.Kind + "/" + ref.Name
+		if visited[key] {
+			break
+		}
+		visited[key] = true
+		root = key
+
+		owner, err := getOwner(client, current.Namespace, *ref)
+		if err != nil {
+			break
+		}
+		current = owner
+	}
+	return root, root != ""
+}
+
+// getOwner returns the metadata of the owner of an object of namespace.
+// Owners are in the namespace of the objects they own, or cluster scoped.
+func getOwner(client *kubernetes.Client, namespace string, ref metav1.OwnerReference) (metav1.ObjectMeta, error) {
+	ctx := context.Background()
+	var owner metav1.Object
+	var err error
+	switch ref.Kind {
+	case "ReplicaSet":
+		owner, err = client.GetClient().AppsV1().ReplicaSets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	case "Deployment":
+		owner, err = client.GetClient().AppsV1().Deployments(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	case "StatefulSet":
+		owner, err = client.GetClient().AppsV1().StatefulSets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	case "DaemonSet":
+		owner, err = client.GetClient().AppsV1().DaemonSets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	case "Job":
+		owner, err = client.GetClient().BatchV1().Jobs(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	case "CronJob":
+		owner, err = client.GetClient().BatchV1().CronJobs(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	default:
+		if client.CtrlClient == nil {
+			return metav1.ObjectMeta{}, fmt.Errorf("%s %s can't be read without a controller-runtime client", ref.Kind, ref.Name)
+		}
+		object := &unstructured.Unstructured{}
+		object.SetGroupVersionKind(schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind))
+		err = client.CtrlClient.Get(ctx, ctrl.ObjectKey{Namespace: namespace, Name: ref.Name}, object)
+		owner = object
+	}
+	if err != nil {
+		return metav1.ObjectMeta{}, err
+	}
+	return metav1.ObjectMeta{
+		Name:            owner.GetName(),
+		Namespace:       owner.GetNamespace(),
+		OwnerReferences: owner.GetOwnerReferences(),
+	}, nil
+}
+
 func RemoveDuplicates(slice []string) ([]string, []string) {
 	set := make(map[string]bool)
 	duplicates := []string{}
@@ -239,6 +330,11 @@ func LabelsIncludeAny(predefinedSelector, Labels map[string]string) bool {
 
 func FetchLatestEvent(ctx context.Context, kubernetesClient *kubernetes.Client, namespace string, name string) (*v1.Event, error) {
 
+	// events are fetched once per object, so they are throttled
+	if err := kubernetesClient.Wait(ctx); err != nil {
+		return nil, err
+	}
+
 	// get the list of events
 	events, err := kubernetesClient.GetClient().CoreV1().Events(namespace).List(ctx,
 		metav1.ListOptions{
@@ -311,3 +407,83 @@ func LabelStrToSelector(labelStr string) labels.Selector {
 	}
 	return labels.SelectorFromSet(labels.Set(labelSelectorMap))
 }
+
+// selectableFields lists the fields the API server can select objects of a
+// kind by, besides metadata.name and metadata.namespace.
+var selectableFields = map[string][]string{
+	"Pod": {"spec.nodeName", "spec.restartPolicy", "spec.schedulerName", "spec.serviceAccountName",
+		"spec.hostNetwork", "status.phase", "status.podIP", "status.podIPs", "status.nominatedNodeName"},
+	"Node":                      {"spec.unschedulable"},
+	"Namespace":                 {"status.phase"},
+	"Secret":                    {"type"},
+	"ReplicaSet":                {"status.replicas"},
+	"ReplicationController":     {"status.replicas"},
+	"Job":                       {"status.successful"},
+	"CertificateSigningRequest": {"spec.signerName"},
+	"Event": {"involvedObject.kind", "involvedObject.namespace", "involvedObject.name", "involvedObject.uid",
+		"involvedObject.apiVersion", "involvedObject.resourceVersion", "involvedObject.fieldPath",
+		"reason", "reportingComponent", "source", "type"},
+}
+
+// FieldSelectorFor returns the field selector if objects of the kind can be
+// selected by all of its fields, and an empty selector otherwise, so the
+// analyzers of other kinds list their objects unfiltered instead of failing.
+func FieldSelectorFor(kind string, fieldSelector string) string {
+	if fieldSelector == "" {
+		return ""
+	}
+	selector, err := fields.ParseSelector(fieldSelector)
+	if err != nil {
+		return ""
+	}
+	for _, requirement := range selector.Requirements() {
+		if !IsSelectableField(kind, requirement.Field) {
+			return ""
+		}
+	}
+	return fieldSelector
+}
+
+// IsSelectableField reports whether objects of the kind can be selected by
+// the field.
+func IsSelectableField(kind string, field string) bool {
+	if field == "metadata.name" || field == "metadata.namespace" {
+		return true
+	}
+	for _, f := range selectableFields[kind] {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldSelectorKinds returns the kinds the field selector can be applied to,
+// sorted, or nil when it only uses metadata fields, which all kinds support.
+func FieldSelectorKinds(fieldSelector string) []string {
+	if FieldSelectorFor("", fieldSelector) != "" {
+		return nil
+	}
+	var kinds []string
+	for kind := range selectableFields {
+		if FieldSelectorFor(kind, fieldSelector) != "" {
+			kinds = append(kinds, kind)
+		}
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// FieldStrToSelector is FieldSelectorFor for controller-runtime clients.
+func FieldStrToSelector(kind string, fieldSelector string) fields.Selector {
+	if selector := FieldSelectorFor(kind, fieldSelector); selector != "" {
+		return fields.ParseSelectorOrDie(selector)
+	}
+	return nil
+}
+
+// CreatedWithin reports whether the object was created less than age ago.
+// A zero age never matches.
+func CreatedWithin(meta metav1.ObjectMeta, age time.Duration) bool {
+	return age > 0 && time.Since(meta.CreationTimestamp.Time) < age
+}
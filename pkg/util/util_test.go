@@ -14,16 +14,22 @@ limitations under the License.
 package util
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
 	"github.com/stretchr/testify/require"
 	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestGetParent(t *testing.T) {
@@ -60,6 +66,12 @@ func TestGetParent(t *testing.T) {
 				Namespace: namespace,
 			},
 		},
+		&batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      ownerName,
+				Namespace: namespace,
+			},
+		},
 		&admissionregistrationv1.MutatingWebhookConfiguration{
 			ObjectMeta: metav1.ObjectMeta{
 				Name: ownerName,
@@ -124,6 +136,14 @@ func TestGetParent(t *testing.T) {
 			name:           ownerName,
 			expectedOutput: "Ingress/test-name",
 		},
+		{
+			kind: "CronJob",
+		},
+		{
+			kind:           "CronJob",
+			name:           ownerName,
+			expectedOutput: "CronJob/test-name",
+		},
 		{
 			kind: "MutatingWebhookConfiguration",
 		},
@@ -165,6 +185,107 @@ func TestGetParent(t *testing.T) {
 	}
 }
 
+func TestGetRootOwner(t *testing.T) {
+	owner := func(apiVersion string, kind string, name string) metav1.OwnerReference {
+		return metav1.OwnerReference{APIVersion: apiVersion, Kind: kind, Name: name}
+	}
+	controller := func(ref metav1.OwnerReference) metav1.OwnerReference {
+		isController := true
+		ref.Controller = &isController
+		return ref
+	}
+	objectMeta := func(name string, owners ...metav1.OwnerReference) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "shop", OwnerReferences: owners}
+	}
+
+	rollout := &unstructured.Unstructured{}
+	rollout.SetAPIVersion("argoproj.io/v1alpha1")
+	rollout.SetKind("Rollout")
+	rollout.SetNamespace("shop")
+	rollout.SetName("canary")
+	rollout.SetOwnerReferences([]metav1.OwnerReference{owner("argoproj.io/v1alpha1", "ApplicationSet", "apps")})
+
+	clientset := fake.NewSimpleClientset(
+		&appsv1.ReplicaSet{ObjectMeta: objectMeta("web-5d8f", controller(owner("apps/v1", "Deployment", "web")))},
+		&appsv1.Deployment{ObjectMeta: objectMeta("web")},
+		&batchv1.Job{ObjectMeta: objectMeta("backup-2891", controller(owner("batch/v1", "CronJob", "backup")))},
+		&batchv1.CronJob{ObjectMeta: objectMeta("backup")},
+		&appsv1.ReplicaSet{ObjectMeta: objectMeta("loop", owner("apps/v1", "Deployment", "loop"))},
+		&appsv1.Deployment{ObjectMeta: objectMeta("loop", owner("apps/v1", "ReplicaSet", "loop"))},
+		&appsv1.ReplicaSet{ObjectMeta: objectMeta("canary-7c9b", controller(owner("argoproj.io/v1alpha1", "Rollout", "canary")))},
+	)
+	ctrlClient := ctrlfake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(rollout).Build()
+
+	tests := []struct {
+		name       string
+		owners     []metav1.OwnerReference
+		noCtrl     bool
+		expected   string
+		expectedOk bool
+	}{
+		{
+			name: "no owner",
+		},
+		{
+			name:       "deployment",
+			owners:     []metav1.OwnerReference{controller(owner("apps/v1", "ReplicaSet", "web-5d8f"))},
+			expected:   "Deployment/web",
+			expectedOk: true,
+		},
+		{
+			name:       "cronjob",
+			owners:     []metav1.OwnerReference{controller(owner("batch/v1", "Job", "backup-2891"))},
+			expected:   "CronJob/backup",
+			expectedOk: true,
+		},
+		{
+			name: "controller first",
+			owners: []metav1.OwnerReference{
+				owner("v1", "ConfigMap", "settings"),
+				controller(owner("apps/v1", "ReplicaSet", "web-5d8f")),
+			},
+			expected:   "Deployment/web",
+			expectedOk: true,
+		},
+		{
+			name:       "deleted owner",
+			owners:     []metav1.OwnerReference{controller(owner("apps/v1", "ReplicaSet", "web-0000"))},
+			expected:   "ReplicaSet/web-0000",
+			expectedOk: true,
+		},
+		{
+			name:       "cycle",
+			owners:     []metav1.OwnerReference{controller(owner("apps/v1", "ReplicaSet", "loop"))},
+			expected:   "Deployment/loop",
+			expectedOk: true,
+		},
+		{
+			name:       "custom resource",
+			owners:     []metav1.OwnerReference{controller(owner("apps/v1", "ReplicaSet", "canary-7c9b"))},
+			expected:   "ApplicationSet/apps",
+			expectedOk: true,
+		},
+		{
+			name:       "custom resource without a controller-runtime client",
+			owners:     []metav1.OwnerReference{controller(owner("apps/v1", "ReplicaSet", "canary-7c9b"))},
+			noCtrl:     true,
+			expected:   "Rollout/canary",
+			expectedOk: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &kubernetes.Client{Client: clientset, CtrlClient: ctrlClient}
+			if tt.noCtrl {
+				client.CtrlClient = nil
+			}
+			root, ok := GetRootOwner(client, objectMeta("pod", tt.owners...))
+			require.Equal(t, tt.expectedOk, ok)
+			require.Equal(t, tt.expected, root)
+		})
+	}
+}
+
 func TestRemoveDuplicates(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -503,3 +624,102 @@ func TestLabelsIncludeAny(t *testing.T) {
 		})
 	}
 }
+
+func TestCreatedWithin(t *testing.T) {
+	tests := []struct {
+		name    string
+		created time.Time
+		age     time.Duration
+		ok      bool
+	}{
+		{
+			name:    "no minimum age",
+			created: time.Now(),
+			ok:      false,
+		},
+		{
+			name:    "recently created",
+			created: time.Now().Add(-10 * time.Second),
+			age:     time.Minute,
+			ok:      true,
+		},
+		{
+			name:    "older than the minimum age",
+			created: time.Now().Add(-2 * time.Minute),
+			age:     time.Minute,
+			ok:      false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			meta := metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(tt.created)}
+			require.Equal(t, tt.ok, CreatedWithin(meta, tt.age))
+		})
+	}
+}
+
+func TestFieldSelectorFor(t *testing.T) {
+	tests := []struct {
+		name          string
+		kind          string
+		fieldSelector string
+		expected      string
+	}{
+		{
+			name:          "metadata fields apply to every kind",
+			kind:          "Deployment",
+			fieldSelector: "metadata.name=web",
+			expected:      "metadata.name=web",
+		},
+		{
+			name:          "pod fields",
+			kind:          "Pod",
+			fieldSelector: "spec.nodeName=node-1,status.phase!=Running",
+			expected:      "spec.nodeName=node-1,status.phase!=Running",
+		},
+		{
+			name:          "unsupported field is dropped",
+			kind:          "Deployment",
+			fieldSelector: "spec.nodeName=node-1",
+		},
+		{
+			name:          "invalid selector is dropped",
+			kind:          "Pod",
+			fieldSelector: "spec.nodeName",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, FieldSelectorFor(tt.kind, tt.fieldSelector))
+		})
+	}
+}
+
+func TestFieldSelectorKinds(t *testing.T) {
+	require.Nil(t, FieldSelectorKinds("metadata.namespace=default"))
+	require.Equal(t, []string{"Pod"}, FieldSelectorKinds("spec.nodeName=node-1"))
+	require.Equal(t, []string{"ReplicaSet", "ReplicationController"}, FieldSelectorKinds("status.replicas=0"))
+	require.Empty(t, FieldSelectorKinds("spec.foo=bar"))
+}
+
+func TestFetchLatestEventThrottled(t *testing.T) {
+	client := &kubernetes.Client{
+		Client:  fake.NewSimpleClientset(),
+		Limiter: kubernetes.NewLimiter(20, 1),
+	}
+
+	// The first fetch uses the burst, the next ones are spaced 50ms apart.
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		_, err := FetchLatestEvent(context.Background(), client, "default", "web")
+		require.NoError(t, err)
+	}
+	require.GreaterOrEqual(t, time.Since(start), 190*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := FetchLatestEvent(ctx, client, "default", "web")
+	require.ErrorIs(t, err, context.Canceled)
+}